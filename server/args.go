@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	flagDue      = "due"
+	flagPriority = "priority"
+	flagDesc     = "desc"
+	flagList     = "list"
+	flagSort     = "sort"
+	flagFix      = "fix"
+	flagRepeat   = "repeat"
+	flagTag      = "tag"
+)
+
+// commandFlags are the flags known by the command parser, and whether they take a value
+var commandFlags = map[string]bool{
+	flagDue:      true,
+	flagPriority: true,
+	flagDesc:     true,
+	flagList:     true,
+	flagSort:     true,
+	flagFix:      false,
+	flagRepeat:   true,
+	flagTag:      true,
+}
+
+// maxFlagPhraseWords is the maximum number of unquoted words taken as the value of a phrase flag
+const maxFlagPhraseWords = 5
+
+// flagPhraseValidators hold the flags whose value may span several unquoted words, like
+// --due next friday 3pm. The longest run of words accepted by the validator is taken as the value.
+var flagPhraseValidators = map[string]func(string) bool{
+	flagDue: func(value string) bool {
+		_, err := parseDueDate(value, time.UTC)
+		return err == nil
+	},
+	flagRepeat: func(value string) bool {
+		_, err := normalizeRecurrence(value, 0, time.UTC)
+		return err == nil
+	},
+}
+
+// commandArgs holds the arguments of a slash command, as split by parseCommandArgs
+type commandArgs struct {
+	values []string
+	// separators hold the whitespace found before each value, as a space or the line breaks it had
+	separators []string
+	flags      map[string]string
+}
+
+// parseCommandArgs splits text into arguments and flags. Arguments are separated by whitespace
+// unless double quoted, and flags are written as --name value or --name=value. Arguments after
+// a bare -- are never taken as flags.
+func parseCommandArgs(text string) (*commandArgs, error) {
+	args := &commandArgs{
+		values:     []string{},
+		separators: []string{},
+		flags:      map[string]string{},
+	}
+
+	tokens, separators, quoted, err := tokenize(text)
+	if err != nil {
+		return nil, err
+	}
+
+	onlyValues := false
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if onlyValues || quoted[i] || !strings.HasPrefix(token, "--") {
+			args.values = append(args.values, token)
+			args.separators = append(args.separators, separators[i])
+			continue
+		}
+
+		if token == "--" {
+			onlyValues = true
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(token, "--"), "=")
+		takesValue, ok := commandFlags[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown flag `--%s`, quote it or write `--` before it to use it as text", name)
+		}
+
+		if takesValue && !hasValue {
+			if i+1 >= len(tokens) {
+				return nil, fmt.Errorf("missing value for `--%s`", name)
+			}
+			n := phraseLength(name, tokens[i+1:], separators[i+1:], quoted[i+1:])
+			value = strings.Join(tokens[i+1:i+1+n], " ")
+			i += n
+		} else if !takesValue && hasValue {
+			return nil, fmt.Errorf("`--%s` does not take a value", name)
+		}
+
+		args.flags[name] = value
+	}
+
+	return args, nil
+}
+
+// phraseLength returns how many of the tokens following the flag name make its value. It is one
+// unless the flag accepts phrases, which end before quoted tokens, flags and line breaks.
+func phraseLength(name string, tokens, separators []string, quoted []bool) int {
+	isValid, ok := flagPhraseValidators[name]
+	if !ok {
+		return 1
+	}
+
+	length := 1
+	for n := 1; n <= len(tokens) && n <= maxFlagPhraseWords; n++ {
+		last := n - 1
+		if last > 0 && (quoted[last] || separators[last] != " " || strings.HasPrefix(tokens[last], "--")) {
+			break
+		}
+		if isValid(strings.Join(tokens[:n], " ")) {
+			length = n
+		}
+	}
+	return length
+}
+
+// tokenize splits text by whitespace, respecting double quoted strings, where a backslash escapes
+// the next character. It returns the tokens, the whitespace before each one and whether they were quoted.
+func tokenize(text string) ([]string, []string, []bool, error) {
+	tokens := []string{}
+	separators := []string{}
+	quoted := []bool{}
+
+	var token strings.Builder
+	inToken, inQuotes, escaped, wasQuoted := false, false, false, false
+	lineBreaks := 0
+
+	endToken := func() {
+		separator := " "
+		if lineBreaks > 0 {
+			separator = strings.Repeat("\n", lineBreaks)
+		}
+		tokens = append(tokens, token.String())
+		separators = append(separators, separator)
+		quoted = append(quoted, wasQuoted)
+		token.Reset()
+		inToken, wasQuoted = false, false
+		lineBreaks = 0
+	}
+
+	for _, r := range text {
+		switch {
+		case escaped:
+			token.WriteRune(r)
+			escaped = false
+		case inQuotes && r == '\\':
+			escaped = true
+		case inQuotes && isCloseQuote(r):
+			inQuotes = false
+		case inQuotes:
+			token.WriteRune(r)
+		case !inToken && isOpenQuote(r):
+			inToken, inQuotes, wasQuoted = true, true, true
+		case unicode.IsSpace(r):
+			if inToken {
+				endToken()
+			}
+			if r == '\n' {
+				lineBreaks++
+			}
+		default:
+			inToken = true
+			token.WriteRune(r)
+		}
+	}
+
+	if inQuotes {
+		return nil, nil, nil, fmt.Errorf("missing closing quote")
+	}
+	if inToken {
+		endToken()
+	}
+
+	return tokens, separators, quoted, nil
+}
+
+// isOpenQuote returns whether r opens a quoted string, including the typographic quotes some
+// keyboards replace double quotes with
+func isOpenQuote(r rune) bool {
+	return r == '"' || r == '“'
+}
+
+func isCloseQuote(r rune) bool {
+	return r == '"' || r == '”'
+}
+
+// len returns the number of values
+func (a *commandArgs) len() int {
+	return len(a.values)
+}
+
+// rest returns the arguments without the first n values, keeping the flags
+func (a *commandArgs) rest(n int) *commandArgs {
+	if n > len(a.values) {
+		n = len(a.values)
+	}
+	return &commandArgs{
+		values:     a.values[n:],
+		separators: a.separators[n:],
+		flags:      a.flags,
+	}
+}
+
+// text joins the values, keeping the line breaks between them
+func (a *commandArgs) text() string {
+	var sb strings.Builder
+	for i, value := range a.values {
+		if i > 0 {
+			sb.WriteString(a.separators[i])
+		}
+		sb.WriteString(value)
+	}
+	return sb.String()
+}
+
+// flag returns the value of the flag name, if given
+func (a *commandArgs) flag(name string) string {
+	return a.flags[name]
+}
+
+// hasFlag returns whether the flag name was given
+func (a *commandArgs) hasFlag(name string) bool {
+	_, ok := a.flags[name]
+	return ok
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustParseCommandArgs(t *testing.T, text string) *commandArgs {
+	t.Helper()
+	args, err := parseCommandArgs(text)
+	require.NoError(t, err)
+	return args
+}
+
+func TestParseCommandArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		wantValues []string
+		wantText   string
+		wantFlags  map[string]string
+		wantErr    bool
+	}{
+		{
+			name:       "Whitespace is collapsed",
+			text:       "  /todo   add\tbe  awesome ",
+			wantValues: []string{"/todo", "add", "be", "awesome"},
+			wantText:   "/todo add be awesome",
+			wantFlags:  map[string]string{},
+		},
+		{
+			name:       "Quotes group words",
+			text:       `/todo send @bob "fix the build" now`,
+			wantValues: []string{"/todo", "send", "@bob", "fix the build", "now"},
+			wantText:   "/todo send @bob fix the build now",
+			wantFlags:  map[string]string{},
+		},
+		{
+			name:       "Typographic quotes and escapes",
+			text:       `“say \"hi\"” don't`,
+			wantValues: []string{`say "hi"`, "don't"},
+			wantText:   `say "hi" don't`,
+			wantFlags:  map[string]string{},
+		},
+		{
+			name:       "Line breaks are kept",
+			text:       "first line\nsecond\n\n\"third\nline\"",
+			wantValues: []string{"first", "line", "second", "third\nline"},
+			wantText:   "first line\nsecond\n\nthird\nline",
+			wantFlags:  map[string]string{},
+		},
+		{
+			name:       "Flags with values",
+			text:       `add --due 2025-01-31 be --priority=high awesome --desc "long text"`,
+			wantValues: []string{"add", "be", "awesome"},
+			wantText:   "add be awesome",
+			wantFlags:  map[string]string{flagDue: "2025-01-31", flagPriority: "high", flagDesc: "long text"},
+		},
+		{
+			name:       "Due date phrases",
+			text:       "add --due next friday 3pm call mom --priority high",
+			wantValues: []string{"add", "call", "mom"},
+			wantText:   "add call mom",
+			wantFlags:  map[string]string{flagDue: "next friday 3pm", flagPriority: "high"},
+		},
+		{
+			name:       "Due date phrases leave out trailing connectors",
+			text:       "add --due friday on call rotation",
+			wantValues: []string{"add", "on", "call", "rotation"},
+			wantText:   "add on call rotation",
+			wantFlags:  map[string]string{flagDue: "friday"},
+		},
+		{
+			name:       "Due date phrases leave out a trailing at",
+			text:       "add --due tomorrow at lunch call Bob",
+			wantValues: []string{"add", "at", "lunch", "call", "Bob"},
+			wantText:   "add at lunch call Bob",
+			wantFlags:  map[string]string{flagDue: "tomorrow"},
+		},
+		{
+			name:       "Due date phrases keep connectors followed by a time",
+			text:       "add --due tomorrow at 3pm call Bob",
+			wantValues: []string{"add", "call", "Bob"},
+			wantText:   "add call Bob",
+			wantFlags:  map[string]string{flagDue: "tomorrow at 3pm"},
+		},
+		{
+			name:       "Repetition phrases",
+			text:       "add --repeat weekly on mon Standup notes",
+			wantValues: []string{"add", "Standup", "notes"},
+			wantText:   "add Standup notes",
+			wantFlags:  map[string]string{flagRepeat: "weekly on mon"},
+		},
+		{
+			name:       "Due date phrases end at quotes and line breaks",
+			text:       "add --due in 2 \"days\" and --due=tomorrow\nnoon",
+			wantValues: []string{"add", "2", "days", "and", "noon"},
+			wantText:   "add 2 days and\nnoon",
+			wantFlags:  map[string]string{flagDue: "tomorrow"},
+		},
+		{
+			name:       "Invalid due date phrases take a word",
+			text:       "add --due someday soon",
+			wantValues: []string{"add", "soon"},
+			wantText:   "add soon",
+			wantFlags:  map[string]string{flagDue: "someday"},
+		},
+		{
+			name:       "Boolean flag",
+			text:       "admin fsck --fix",
+			wantValues: []string{"admin", "fsck"},
+			wantText:   "admin fsck",
+			wantFlags:  map[string]string{flagFix: ""},
+		},
+		{
+			name:       "Quoted and escaped flags are values",
+			text:       `add "--force" -- push --due`,
+			wantValues: []string{"add", "--force", "push", "--due"},
+			wantText:   "add --force push --due",
+			wantFlags:  map[string]string{},
+		},
+		{
+			name:    "Unknown flag",
+			text:    "add --force push",
+			wantErr: true,
+		},
+		{
+			name:    "Missing flag value",
+			text:    "add be awesome --due",
+			wantErr: true,
+		},
+		{
+			name:    "Value for boolean flag",
+			text:    "admin fsck --fix=yes",
+			wantErr: true,
+		},
+		{
+			name:    "Missing closing quote",
+			text:    `add "be awesome`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := parseCommandArgs(tt.text)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantValues, args.values)
+			assert.Equal(t, tt.wantText, args.text())
+			assert.Equal(t, tt.wantFlags, args.flags)
+		})
+	}
+}
+
+func TestCommandArgsRest(t *testing.T) {
+	args := mustParseCommandArgs(t, "send @bob first\nsecond --due 2025-01-31")
+
+	rest := args.rest(2)
+	assert.Equal(t, "first\nsecond", rest.text())
+	assert.Equal(t, "2025-01-31", rest.flag(flagDue))
+	assert.Equal(t, 0, args.rest(10).len())
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+const (
+	// autocompleteOpenList is the list filter of the issues on any of the open lists of the user
+	autocompleteOpenList = "open"
+	// autocompleteSnoozedList is the list filter of the snoozed issues of the user
+	autocompleteSnoozedList = "snoozed"
+	// autocompleteChannelList is the list filter of the open issues of the channel the command is typed on
+	autocompleteChannelList = "channel"
+	// autocompleteMaxItems is the number of issues suggested at once
+	autocompleteMaxItems = 25
+)
+
+// autocompleteIssuesURL returns the plugin relative URL suggesting the issues of list, which is a
+// list flag or autocompleteOpenList
+func autocompleteIssuesURL(list string) string {
+	return path.Join("autocomplete", "issues", list)
+}
+
+// handleAutocompleteIssues returns the open issues of the user, on the list given on the URL,
+// that match the argument being typed
+func (p *Plugin) handleAutocompleteIssues(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	// ownerID is the channel for the channel list, and the user otherwise
+	ownerID := userID
+
+	// listNames hold the names of the custom lists, shown along with their issues
+	listNames := map[string]string{}
+	listIDs := []string{MyListKey, InListKey, OutListKey}
+	snoozedOnly := false
+	switch list := mux.Vars(r)["list"]; list {
+	case autocompleteOpenList, autocompleteSnoozedList:
+		if list == autocompleteSnoozedList {
+			listIDs = []string{MyListKey, InListKey}
+			snoozedOnly = true
+		}
+
+		customLists, err := p.listManager.GetCustomLists(userID)
+		if err != nil {
+			msg := "Unable to get lists for autocomplete"
+			p.API.LogError(msg, "err", err.Error())
+			p.handleErrorWithCode(w, http.StatusInternalServerError, msg, err)
+			return
+		}
+		for _, customList := range customLists {
+			listIDs = append(listIDs, customList.ID)
+			listNames[customList.ID] = customList.Name
+		}
+	case autocompleteChannelList:
+		ownerID = r.URL.Query().Get("channel_id")
+		if err := p.checkChannelMember(ownerID, userID); err != nil {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		listIDs = []string{ChannelListKey}
+	default:
+		listID, ok := listIDFromFlag(list)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		listIDs = []string{listID}
+	}
+
+	userInput := r.URL.Query().Get("user_input")
+
+	items := []model.AutocompleteListItem{}
+	for _, listID := range listIDs {
+		issues, err := p.listManager.GetIssueList(ownerID, listID)
+		if err != nil {
+			msg := "Unable to get issues for autocomplete"
+			p.API.LogError(msg, "err", err.Error())
+			p.handleErrorWithCode(w, http.StatusInternalServerError, msg, err)
+			return
+		}
+
+		if snoozedOnly {
+			issues = snoozedIssues(issues, model.GetMillis())
+		}
+
+		listName, ok := listNames[listID]
+		if !ok {
+			listName = listDisplayName(listID)
+		}
+		items = append(items, issuesToAutocompleteItems(issues, listName, userInput)...)
+	}
+
+	if len(items) > autocompleteMaxItems {
+		items = items[:autocompleteMaxItems]
+	}
+
+	itemsJSON, err := json.Marshal(items)
+	if err != nil {
+		p.API.LogError("Unable to marshal autocomplete items to json err=" + err.Error())
+		return
+	}
+
+	if _, err = w.Write(itemsJSON); err != nil {
+		p.API.LogError("Unable to write json response while autocompleting issues err=" + err.Error())
+	}
+}
+
+// issuesToAutocompleteItems returns the suggestions for the issues of the list called listName whose
+// short ID starts with userInput, or whose message contains it
+func issuesToAutocompleteItems(issues []*ExtendedIssue, listName, userInput string) []model.AutocompleteListItem {
+	userInput = strings.ToLower(strings.TrimSpace(userInput))
+
+	items := []model.AutocompleteListItem{}
+	for _, issue := range issues {
+		if issue.ShortID == 0 {
+			continue
+		}
+
+		shortID := fmt.Sprintf("#%d", issue.ShortID)
+		if userInput != "" &&
+			!strings.HasPrefix(shortID, "#"+strings.TrimPrefix(userInput, "#")) &&
+			!strings.Contains(strings.ToLower(issue.Message), userInput) {
+			continue
+		}
+
+		items = append(items, model.AutocompleteListItem{
+			Item:     shortID,
+			Hint:     fmt.Sprintf("(%s)", listName),
+			HelpText: truncateText(issue.Message, maxActionOptionLength),
+		})
+	}
+
+	return items
+}
+
+// snoozedIssues returns the issues that are snoozed at now
+func snoozedIssues(issues []*ExtendedIssue, now int64) []*ExtendedIssue {
+	snoozed := []*ExtendedIssue{}
+	for _, issue := range issues {
+		if issue.IsSnoozed(now) {
+			snoozed = append(snoozed, issue)
+		}
+	}
+	return snoozed
+}
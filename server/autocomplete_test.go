@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssuesToAutocompleteItems(t *testing.T) {
+	issues := []*ExtendedIssue{
+		{Issue: Issue{ID: "issue1", ShortID: 4, Message: "Fix the build"}},
+		{Issue: Issue{ID: "issue2", ShortID: 42, Message: "Write the release notes"}},
+		{Issue: Issue{ID: "issue3", Message: "Legacy"}},
+	}
+
+	itemNames := func(userInput string) []string {
+		names := []string{}
+		for _, item := range issuesToAutocompleteItems(issues, InFlag, userInput) {
+			names = append(names, item.Item)
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"#4", "#42"}, itemNames(""))
+	assert.Equal(t, []string{"#4", "#42"}, itemNames("#4"))
+	assert.Equal(t, []string{"#42"}, itemNames("42"))
+	assert.Equal(t, []string{"#4"}, itemNames("build"))
+	assert.Equal(t, []string{"#42"}, itemNames("RELEASE"))
+	assert.Empty(t, itemNames("#5"))
+
+	items := issuesToAutocompleteItems(issues, InFlag, "#42")
+	assert.Equal(t, "(in)", items[0].Hint)
+	assert.Equal(t, "Write the release notes", items[0].HelpText)
+}
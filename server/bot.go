@@ -16,20 +16,48 @@ func (p *Plugin) PostBotDM(userID string, message string) {
 	}, userID)
 }
 
-// PostBotCustomDM posts a DM as the cloud bot user using custom post with action buttons.
+// PostBotCustomDM posts a DM as the cloud bot user showing a received todo, with buttons to accept,
+// decline, complete and snooze it.
 func (p *Plugin) PostBotCustomDM(userID, message, todo, postPermalink, issueID string) {
-	p.createBotPostDM(&model.Post{
+	p.PostBotIssueDM(userID, message, todo, postPermalink, issueID, issueActions(issueID))
+}
+
+// PostBotIssueDM posts a DM as the cloud bot user showing a todo, with the given actions on it.
+func (p *Plugin) PostBotIssueDM(userID, message, todo, postPermalink, issueID string, actions []*model.PostAction) {
+	text := ""
+	if postPermalink != "" {
+		text = fmt.Sprintf("[Permalink](%s)", postPermalink)
+	}
+
+	post := &model.Post{
 		UserId:  p.BotUserID,
 		Message: message + ": " + todo,
-		Type:    "custom_todo",
 		Props: map[string]interface{}{
-			"type":          "custom_todo",
-			"message":       message,
 			"todo":          todo,
 			"postPermalink": postPermalink,
 			"issueId":       issueID,
 		},
-	}, userID)
+	}
+	model.ParseSlackAttachment(post, []*model.SlackAttachment{{
+		Fallback: message + ": " + todo,
+		Text:     text,
+		Actions:  actions,
+	}})
+
+	p.createBotPostDM(post, userID)
+}
+
+// PostBotReminderDM posts a DM as the cloud bot user with the summary of issues, and a menu to complete them.
+func (p *Plugin) PostBotReminderDM(userID, title string, issues []*ExtendedIssue) {
+	post := &model.Post{
+		UserId:  p.BotUserID,
+		Message: title + ":\n\n" + issuesListToString(issues, p.getUserLocation(userID)),
+	}
+	model.ParseSlackAttachment(post, []*model.SlackAttachment{{
+		Actions: []*model.PostAction{completeSelectAction(awakeIssues(issues, model.GetMillis()))},
+	}})
+
+	p.createBotPostDM(post, userID)
 }
 
 func (p *Plugin) createBotPostDM(post *model.Post, userID string) {
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+const (
+	// CaptureActionAdd adds a line captured from a post to the list of its author
+	CaptureActionAdd = "add"
+	// CaptureActionSend sends a line captured from a post to the user mentioned on it
+	CaptureActionSend = "send"
+
+	captureMessageKey  = "message"
+	capturePostIDKey   = "post_id"
+	captureReceiverKey = "receiver_id"
+
+	// autoCaptureAllChannels enables the auto capture on every channel
+	autoCaptureAllChannels = "*"
+	// maxCapturedLines is the number of lines of a post offered to be added as Todos
+	maxCapturedLines = 5
+)
+
+// capturedLine is a line of a post marked as a Todo, like "TODO: message" or "@username todo: message"
+type capturedLine struct {
+	Username string
+	Message  string
+}
+
+// newAutoCapturePattern returns the pattern matching the lines marked with any of keywords, which are
+// separated by commas, or nil if there are none
+func newAutoCapturePattern(keywords string) *regexp.Regexp {
+	quoted := []string{}
+	for _, keyword := range strings.Split(keywords, ",") {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			quoted = append(quoted, regexp.QuoteMeta(keyword))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+
+	// Lines may be list items or quotes, and the mention is optional
+	return regexp.MustCompile(`(?im)^[ \t>*-]*(?:@([a-z0-9._-]+)[ \t,]+)?(?:` + strings.Join(quoted, "|") + `)[ \t]*:[ \t]*(\S.*?)[ \t]*$`)
+}
+
+// findCapturedLines returns the lines of message matching pattern, up to maxCapturedLines
+func findCapturedLines(pattern *regexp.Regexp, message string) []*capturedLine {
+	lines := []*capturedLine{}
+	for _, match := range pattern.FindAllStringSubmatch(message, maxCapturedLines) {
+		lines = append(lines, &capturedLine{Username: strings.ToLower(match[1]), Message: match[2]})
+	}
+	return lines
+}
+
+// MessageHasBeenPosted offers the author of a post, on the channels where the auto capture is
+// enabled, to add the lines marked as Todos to their list or to send them to the user they mention
+func (p *Plugin) MessageHasBeenPosted(_ *plugin.Context, post *model.Post) {
+	config := p.getConfiguration()
+	if config.autoCapturePattern == nil || config.AutoCaptureChannels == "" || post.UserId == p.BotUserID || post.Type != "" {
+		return
+	}
+
+	lines := findCapturedLines(config.autoCapturePattern, post.Message)
+	if len(lines) == 0 || !p.isAutoCaptureChannel(config.AutoCaptureChannels, post.ChannelId) {
+		return
+	}
+
+	author, appErr := p.API.GetUser(post.UserId)
+	if appErr != nil || author.IsBot {
+		return
+	}
+
+	// Each line gets its own message, so the others are kept once one is added
+	for _, line := range lines {
+		var receiver *model.User
+		if line.Username != "" && line.Username != author.Username {
+			if user, appErr := p.API.GetUserByUsername(line.Username); appErr == nil && !user.IsBot && user.DeleteAt == 0 {
+				receiver = user
+			}
+		}
+
+		offer := &model.Post{
+			UserId:    p.BotUserID,
+			ChannelId: post.ChannelId,
+			RootId:    post.RootId,
+			Message:   "Do you want to track this as a Todo?",
+		}
+		model.ParseSlackAttachment(offer, []*model.SlackAttachment{{
+			Text:    line.Message,
+			Actions: captureActions(post.Id, line.Message, receiver),
+		}})
+		_ = p.API.SendEphemeralPost(post.UserId, offer)
+	}
+}
+
+// captureActions returns the buttons to add message, captured from postID, to the list of the author,
+// and to send it to receiver if given
+func captureActions(postID, message string, receiver *model.User) []*model.PostAction {
+	addContext := map[string]interface{}{
+		captureMessageKey: message,
+		capturePostIDKey:  postID,
+	}
+
+	actions := []*model.PostAction{{
+		Id:          CaptureActionAdd,
+		Type:        model.PostActionTypeButton,
+		Name:        "Add to my list",
+		Style:       "primary",
+		Integration: &model.PostActionIntegration{URL: captureActionURL(CaptureActionAdd), Context: addContext},
+	}}
+	if receiver == nil {
+		return actions
+	}
+
+	sendContext := map[string]interface{}{captureReceiverKey: receiver.Id}
+	for key, value := range addContext {
+		sendContext[key] = value
+	}
+	return append(actions, &model.PostAction{
+		Id:          CaptureActionSend,
+		Type:        model.PostActionTypeButton,
+		Name:        fmt.Sprintf("Send to @%s", receiver.Username),
+		Integration: &model.PostActionIntegration{URL: captureActionURL(CaptureActionSend), Context: sendContext},
+	})
+}
+
+// mentionsUser returns whether message contains an @mention of username
+func mentionsUser(message, username string) bool {
+	pattern := `(?i)(?:^|[^a-z0-9._-])@` + regexp.QuoteMeta(username) + `(?:$|[^a-z0-9._-]|\.(?:$|[^a-z0-9._-]))`
+	return regexp.MustCompile(pattern).MatchString(message)
+}
+
+func captureActionURL(action string) string {
+	return fmt.Sprintf("/plugins/%s/capture/%s", manifest.Id, action)
+}
+
+// isAutoCaptureChannel returns whether the posts of channelID are checked for lines marked as Todos.
+// Entries are separated by commas, and are team names, for all their channels, team/channel names
+// or autoCaptureAllChannels.
+func (p *Plugin) isAutoCaptureChannel(entries, channelID string) bool {
+	var channel *model.Channel
+	teamName := ""
+	for _, entry := range strings.Split(entries, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == autoCaptureAllChannels {
+			return true
+		}
+		if entry == "" {
+			continue
+		}
+
+		if channel == nil {
+			var appErr *model.AppError
+			if channel, appErr = p.API.GetChannel(channelID); appErr != nil {
+				p.API.LogWarn("Unable to get channel to capture Todos", "err", appErr.Error())
+				return false
+			}
+			// Direct and group messages have no team
+			if channel.TeamId != "" {
+				team, appErr := p.API.GetTeam(channel.TeamId)
+				if appErr != nil {
+					p.API.LogWarn("Unable to get team to capture Todos", "err", appErr.Error())
+					return false
+				}
+				teamName = team.Name
+			}
+		}
+
+		if teamName != "" && (entry == teamName || entry == teamName+"/"+channel.Name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// handleCaptureAction adds or sends the line offered by MessageHasBeenPosted, and removes the offer
+func (p *Plugin) handleCaptureAction(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	action := mux.Vars(r)["action"]
+
+	request := &model.PostActionIntegrationRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		msg := "Unable to get post action request payload from JSON"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusBadRequest, msg, err)
+		return
+	}
+
+	message, _ := request.Context[captureMessageKey].(string)
+	postID, _ := request.Context[capturePostIDKey].(string)
+	if message == "" || postID == "" {
+		p.writePostActionResponse(w, &model.PostActionIntegrationResponse{EphemeralText: "Missing Todo to add."})
+		return
+	}
+
+	// The context comes from the request, so the post must be one the user can read
+	post, appErr := p.API.GetPost(postID)
+	if appErr != nil {
+		p.writePostActionResponse(w, &model.PostActionIntegrationResponse{EphemeralText: "Unable to find the post of the Todo."})
+		return
+	}
+	// Only the author is offered to capture the Todos of a post
+	if post.UserId != userID {
+		p.writePostActionResponse(w, &model.PostActionIntegrationResponse{EphemeralText: "Only the author of the post can capture its Todos."})
+		return
+	}
+	if !p.API.HasPermissionToChannel(userID, post.ChannelId, model.PermissionReadChannel) {
+		p.writePostActionResponse(w, &model.PostActionIntegrationResponse{EphemeralText: "You do not have access to the post of the Todo."})
+		return
+	}
+
+	issue := newIssue(message, p.getPostPermalink(postID), "", postID)
+	issue.Labels = messageLabels(message)
+	userName := p.listManager.GetUserName(userID)
+
+	var outcome string
+	switch action {
+	case CaptureActionAdd:
+		if err := p.listManager.AddIssue(userID, issue); err != nil {
+			p.API.LogError(ErrorMsgAddIssue, "err", err.Error())
+			p.writePostActionResponse(w, &model.PostActionIntegrationResponse{EphemeralText: "Unable to add the Todo."})
+			return
+		}
+
+		p.trackAddIssue(userID, sourceCapture, true)
+		p.sendRefreshEvent(userID, []string{MyListKey})
+		p.postReplyIfNeeded(postID, fmt.Sprintf("@%s attached a todo to this thread", userName), message, issue.PostPermalink)
+		outcome = fmt.Sprintf("Added Todo: %s", message)
+	case CaptureActionSend:
+		receiverID, _ := request.Context[captureReceiverKey].(string)
+		receiver, appErr := p.API.GetUser(receiverID)
+		if appErr != nil || !mentionsUser(post.Message, receiver.Username) {
+			p.writePostActionResponse(w, &model.PostActionIntegrationResponse{EphemeralText: "Unable to find the user to send the Todo to."})
+			return
+		}
+
+		receiverMessage := fmt.Sprintf("You have received a new Todo from @%s", userName)
+		sent, blocked, err := p.sendIssueToReceivers(userID, issue, []*model.User{receiver}, receiverMessage)
+		if err != nil {
+			p.API.LogError("Unable to send issue", "err", err.Error())
+			p.writePostActionResponse(w, &model.PostActionIntegrationResponse{EphemeralText: "Unable to send the Todo."})
+			return
+		}
+		if len(sent) == 0 {
+			p.writePostActionResponse(w, &model.PostActionIntegrationResponse{EphemeralText: blockedText(blocked)})
+			return
+		}
+
+		p.trackSendIssue(userID, sourceCapture, true)
+		p.postReplyIfNeeded(postID, fmt.Sprintf("@%s sent %s a todo attached to this thread", userName, usernamesText(sent)), message, issue.PostPermalink)
+		outcome = fmt.Sprintf("Sent %s the Todo: %s", usernamesText(sent), message)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	p.API.DeleteEphemeralPost(userID, request.PostId)
+
+	p.writePostActionResponse(w, &model.PostActionIntegrationResponse{EphemeralText: outcome})
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi/experimental/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindCapturedLines(t *testing.T) {
+	pattern := newAutoCapturePattern("TODO, action item")
+
+	lines := findCapturedLines(pattern, "Notes of the meeting\nTODO: update the docs\n- @Alice todo:  book the room \n> Action item: send the summary\nNothing to do: here\ntodo:")
+	assert.Equal(t, []*capturedLine{
+		{Message: "update the docs"},
+		{Username: "alice", Message: "book the room"},
+		{Message: "send the summary"},
+	}, lines)
+
+	assert.Empty(t, findCapturedLines(pattern, "We still have a TODO: in the code"))
+	assert.Nil(t, newAutoCapturePattern(" , "))
+}
+
+func TestMessageHasBeenPosted(t *testing.T) {
+	author := &model.User{Id: model.NewId(), Username: "lead"}
+	alice := &model.User{Id: model.NewId(), Username: "alice"}
+	team := &model.Team{Id: model.NewId(), Name: "eng"}
+	captured := &model.Channel{Id: model.NewId(), TeamId: team.Id, Name: "standup"}
+	other := &model.Channel{Id: model.NewId(), TeamId: team.Id, Name: "random"}
+
+	api := &plugintest.API{}
+	api.On("GetUser", author.Id).Return(author, nil)
+	api.On("GetUser", alice.Id).Return(alice, nil)
+	api.On("GetUserByUsername", "alice").Return(alice, nil)
+	api.On("GetChannel", captured.Id).Return(captured, nil)
+	api.On("GetChannel", other.Id).Return(other, nil)
+	api.On("GetTeam", team.Id).Return(team, nil)
+	api.On("SendEphemeralPost", author.Id, mock.AnythingOfType("*model.Post")).Return(&model.Post{})
+
+	p := &Plugin{}
+	p.SetAPI(api)
+	config := &configuration{AutoCaptureKeywords: "TODO", AutoCaptureChannels: "other-team, eng/standup"}
+	config.autoCapturePattern = newAutoCapturePattern(config.AutoCaptureKeywords)
+	p.setConfiguration(config)
+
+	message := "TODO: update the docs\n@alice todo: book the room"
+	p.MessageHasBeenPosted(nil, &model.Post{Id: model.NewId(), UserId: author.Id, ChannelId: other.Id, Message: message})
+	api.AssertNotCalled(t, "SendEphemeralPost", mock.Anything, mock.Anything)
+
+	p.MessageHasBeenPosted(nil, &model.Post{Id: model.NewId(), UserId: author.Id, ChannelId: captured.Id, Message: message})
+	api.AssertNumberOfCalls(t, "SendEphemeralPost", 2)
+
+	offer := api.Calls[len(api.Calls)-1].Arguments.Get(1).(*model.Post)
+	actions := offer.Attachments()[0].Actions
+	require.Len(t, actions, 2)
+	assert.Equal(t, "Send to @alice", actions[1].Name)
+	assert.Equal(t, alice.Id, actions[1].Integration.Context[captureReceiverKey])
+	assert.Equal(t, "book the room", actions[1].Integration.Context[captureMessageKey])
+}
+
+func TestHandleCaptureAction(t *testing.T) {
+	userID := model.NewId()
+	postID := model.NewId()
+	siteURL := "https://chat.example.com"
+
+	api := &plugintest.API{}
+	api.On("GetUser", userID).Return(&model.User{Id: userID, Username: "lead"}, nil)
+	api.On("GetConfig").Return(&model.Config{ServiceSettings: model.ServiceSettings{SiteURL: &siteURL}})
+	api.On("GetPost", postID).Return(&model.Post{Id: postID, UserId: userID, ChannelId: model.NewId()}, nil)
+	api.On("HasPermissionToChannel", userID, mock.Anything, model.PermissionReadChannel).Return(true)
+	api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(&model.Post{}, nil)
+	api.On("PublishWebSocketEvent", WSEventRefresh, mock.Anything, mock.Anything).Return()
+	api.On("DeleteEphemeralPost", userID, "offer").Return()
+
+	p := &Plugin{
+		listManager: &listManager{store: newMemStore(), api: api},
+		tracker:     telemetry.NewTracker(nil, "", "", "", "", "", telemetry.TrackerConfig{}, nil),
+	}
+	p.SetAPI(api)
+
+	body, err := json.Marshal(&model.PostActionIntegrationRequest{
+		PostId:  "offer",
+		Context: map[string]interface{}{captureMessageKey: "update the #docs", capturePostIDKey: postID},
+	})
+	require.NoError(t, err)
+	r := httptest.NewRequest(http.MethodPost, "/capture/add", bytes.NewReader(body))
+	r.Header.Set("Mattermost-User-ID", userID)
+	r = mux.SetURLVars(r, map[string]string{"action": CaptureActionAdd})
+	w := httptest.NewRecorder()
+	p.handleCaptureAction(w, r)
+
+	response := &model.PostActionIntegrationResponse{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(response))
+	assert.Equal(t, "Added Todo: update the #docs", response.EphemeralText)
+	api.AssertCalled(t, "DeleteEphemeralPost", userID, "offer")
+
+	issues, err := p.listManager.GetIssueList(userID, MyListKey)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, postID, issues[0].PostID)
+	assert.Equal(t, []string{"docs"}, issues[0].Labels)
+}
+
+func TestHandleCaptureActionChecks(t *testing.T) {
+	userID := model.NewId()
+	channelID := model.NewId()
+	bob := &model.User{Id: model.NewId(), Username: "bob"}
+	post := &model.Post{Id: model.NewId(), UserId: userID, ChannelId: channelID, Message: "todo: book the room"}
+
+	handle := func(t *testing.T, api *plugintest.API, action string, context map[string]interface{}) string {
+		p := &Plugin{
+			listManager: &listManager{store: newMemStore(), api: api},
+			tracker:     telemetry.NewTracker(nil, "", "", "", "", "", telemetry.TrackerConfig{}, nil),
+		}
+		p.SetAPI(api)
+
+		body, err := json.Marshal(&model.PostActionIntegrationRequest{PostId: "offer", Context: context})
+		require.NoError(t, err)
+		r := httptest.NewRequest(http.MethodPost, "/capture/"+action, bytes.NewReader(body))
+		r.Header.Set("Mattermost-User-ID", userID)
+		r = mux.SetURLVars(r, map[string]string{"action": action})
+		w := httptest.NewRecorder()
+		p.handleCaptureAction(w, r)
+
+		response := &model.PostActionIntegrationResponse{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(response))
+
+		// Nothing is added nor replied
+		issues, err := p.listManager.GetIssueList(userID, MyListKey)
+		require.NoError(t, err)
+		assert.Empty(t, issues)
+		api.AssertNotCalled(t, "CreatePost", mock.Anything)
+		api.AssertNotCalled(t, "DeleteEphemeralPost", mock.Anything, mock.Anything)
+		return response.EphemeralText
+	}
+
+	t.Run("post of another user", func(t *testing.T) {
+		otherPost := &model.Post{Id: model.NewId(), UserId: bob.Id, ChannelId: channelID, Message: post.Message}
+		api := &plugintest.API{}
+		api.On("GetPost", otherPost.Id).Return(otherPost, nil)
+		api.On("HasPermissionToChannel", userID, channelID, model.PermissionReadChannel).Return(true)
+
+		text := handle(t, api, CaptureActionAdd, map[string]interface{}{captureMessageKey: "book the room", capturePostIDKey: otherPost.Id})
+		assert.Equal(t, "Only the author of the post can capture its Todos.", text)
+	})
+
+	t.Run("post of an unreadable channel", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("GetPost", post.Id).Return(post, nil)
+		api.On("HasPermissionToChannel", userID, channelID, model.PermissionReadChannel).Return(false)
+
+		text := handle(t, api, CaptureActionAdd, map[string]interface{}{captureMessageKey: "book the room", capturePostIDKey: post.Id})
+		assert.Equal(t, "You do not have access to the post of the Todo.", text)
+	})
+
+	t.Run("receiver not mentioned on the post", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("GetPost", post.Id).Return(post, nil)
+		api.On("HasPermissionToChannel", userID, channelID, model.PermissionReadChannel).Return(true)
+		api.On("GetUser", userID).Return(&model.User{Id: userID, Username: "lead"}, nil)
+		api.On("GetUser", bob.Id).Return(bob, nil)
+		api.On("GetConfig").Return(&model.Config{})
+
+		text := handle(t, api, CaptureActionSend, map[string]interface{}{
+			captureMessageKey:  "book the room",
+			capturePostIDKey:   post.Id,
+			captureReceiverKey: bob.Id,
+		})
+		assert.Equal(t, "Unable to find the user to send the Todo to.", text)
+	})
+}
+
+func TestMentionsUser(t *testing.T) {
+	tests := []struct {
+		message string
+		want    bool
+	}{
+		{message: "@bob todo: book the room", want: true},
+		{message: "ask @Bob.", want: true},
+		{message: "> @bob, todo: book the room", want: true},
+		{message: "@bobby todo: book the room", want: false},
+		{message: "@bob.smith todo: book the room", want: false},
+		{message: "mail bob@example.com", want: false},
+		{message: "todo: book the room", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			assert.Equal(t, tt.want, mentionsUser(tt.message, "bob"))
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+const (
+	// ChannelListKey is the key used to store the list of open todos of a channel, with the channel ID
+	// in place of the user ID
+	ChannelListKey = "_channel"
+	// ChannelDoneListKey is the key used to store the list of completed todos of a channel
+	ChannelDoneListKey = "_channel_done"
+
+	channelActionClaim    = "claim"
+	channelActionComplete = "complete"
+	channelActionRemove   = "remove"
+)
+
+// ErrNotChannelMember is returned when using the list of a channel the user is not a member of
+var ErrNotChannelMember = errors.New("only the members of a channel can use its Todo list")
+
+// isChannelListKey returns whether listID is one of the lists owned by channels
+func isChannelListKey(listID string) bool {
+	return listID == ChannelListKey || listID == ChannelDoneListKey
+}
+
+func (l *listManager) AddChannelIssue(channelID string, issue *Issue) error {
+	if err := l.assignShortID(channelID, issue); err != nil {
+		return err
+	}
+
+	if err := l.store.SaveIssue(issue); err != nil {
+		return err
+	}
+
+	if err := l.store.AddReference(channelID, issue.ID, ChannelListKey, "", ""); err != nil {
+		if rollbackError := l.store.RemoveIssue(channelID, issue.ID); rollbackError != nil {
+			l.api.LogError("cannot rollback issue after add error, Err=", err.Error())
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (l *listManager) ClaimChannelIssue(channelID, userID, issueID string) (*Issue, error) {
+	if ir, _, _ := l.store.GetIssueReference(channelID, issueID, ChannelListKey); ir == nil {
+		return nil, ErrIssueNotFound
+	}
+
+	issue, err := l.store.GetIssue(issueID)
+	if err != nil {
+		return nil, err
+	}
+
+	issue.ClaimedBy = userID
+	if err = l.store.SaveIssue(issue); err != nil {
+		return nil, err
+	}
+
+	return issue, nil
+}
+
+func (l *listManager) CompleteChannelIssue(channelID, userID, issueID string) (*Issue, error) {
+	ir, _, _ := l.store.GetIssueReference(channelID, issueID, ChannelListKey)
+	if ir == nil {
+		return nil, ErrIssueNotFound
+	}
+
+	issue, err := l.store.GetIssue(issueID)
+	if err != nil {
+		return nil, err
+	}
+
+	issue.CompletedAt = model.GetMillis()
+	issue.CompletedBy = userID
+
+	entry := newJournalEntry(JournalComplete, channelID)
+	entry.removeReference(channelID, issueID, ChannelListKey)
+	entry.insertReference(channelID, ChannelDoneListKey, &IssueRef{
+		IssueID:      ir.IssueID,
+		PreviousList: ChannelListKey,
+	}, 0)
+	entry.saveIssue(issue)
+
+	if err = l.runJournaled(entry); err != nil {
+		return nil, err
+	}
+
+	return issue, nil
+}
+
+func (l *listManager) RemoveChannelIssue(channelID, issueID string) (*Issue, error) {
+	listID := ChannelListKey
+	ir, _, _ := l.store.GetIssueReference(channelID, issueID, listID)
+	if ir == nil {
+		listID = ChannelDoneListKey
+		ir, _, _ = l.store.GetIssueReference(channelID, issueID, listID)
+	}
+	if ir == nil {
+		return nil, ErrIssueNotFound
+	}
+
+	issue, err := l.store.GetIssue(issueID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := newJournalEntry(JournalRemove, channelID)
+	entry.removeReference(channelID, issueID, listID)
+	entry.removeIssue(channelID, issueID)
+
+	if err = l.runJournaled(entry); err != nil {
+		return nil, err
+	}
+
+	return issue, nil
+}
+
+// checkChannelMember returns ErrNotChannelMember unless userID is a member of channelID
+func (p *Plugin) checkChannelMember(channelID, userID string) error {
+	if _, appErr := p.API.GetChannelMember(channelID, userID); appErr != nil {
+		if appErr.StatusCode == http.StatusNotFound {
+			return ErrNotChannelMember
+		}
+		return appErr
+	}
+	return nil
+}
+
+// sendChannelRefreshEvent lets every member of channelID know that its lists changed
+func (p *Plugin) sendChannelRefreshEvent(channelID string) {
+	p.API.PublishWebSocketEvent(
+		WSEventRefresh,
+		map[string]interface{}{
+			"lists":      []string{ChannelListKey, ChannelDoneListKey},
+			"channel_id": channelID,
+		},
+		&model.WebsocketBroadcast{ChannelId: channelID},
+	)
+}
+
+// checkChannelAccess only lets the members of the channel of the request use its lists
+func (p *Plugin) checkChannelAccess(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Header.Get("Mattermost-User-ID")
+		channelID := mux.Vars(r)["channel_id"]
+		if err := p.checkChannelMember(channelID, userID); err != nil {
+			if errors.Is(err, ErrNotChannelMember) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+			msg := "Unable to check channel membership"
+			p.API.LogError(msg, "err", err.Error())
+			p.handleErrorWithCode(w, http.StatusInternalServerError, msg, err)
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+func (p *Plugin) handleChannelList(w http.ResponseWriter, r *http.Request) {
+	channelID := mux.Vars(r)["channel_id"]
+
+	listID := ChannelListKey
+	if r.URL.Query().Get("list") == DoneFlag {
+		listID = ChannelDoneListKey
+	}
+
+	issues, err := p.listManager.GetIssueList(channelID, listID)
+	if err != nil {
+		msg := "Unable to get issues for channel"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusInternalServerError, msg, err)
+		return
+	}
+
+	issuesJSON, err := json.Marshal(issues)
+	if err != nil {
+		msg := "Unable marhsal channel issues to json"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusInternalServerError, msg, err)
+		return
+	}
+
+	if _, err = w.Write(issuesJSON); err != nil {
+		p.API.LogError("Unable to write json response while listing channel issues err=" + err.Error())
+	}
+}
+
+func (p *Plugin) handleChannelAdd(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	channelID := mux.Vars(r)["channel_id"]
+
+	addRequest, err := GetChannelAddPayloadFromJSON(r.Body)
+	if err != nil {
+		msg := "Unable to get add channel issue payload from JSON"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusBadRequest, msg, err)
+		return
+	}
+
+	if err = addRequest.IsValid(); err != nil {
+		p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to validate add channel issue payload.", err)
+		return
+	}
+
+	labels, err := normalizeLabels(addRequest.Labels)
+	if err != nil {
+		p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to parse the tags.", err)
+		return
+	}
+
+	issue := newIssue(addRequest.Message, addRequest.PostPermalink, addRequest.Description, addRequest.PostID)
+	issue.DueAt = addRequest.DueAt
+	issue.Priority = addRequest.Priority
+	issue.Labels = mergeLabels(messageLabels(issue.Message), labels)
+
+	if err = p.listManager.AddChannelIssue(channelID, issue); err != nil {
+		p.API.LogError(ErrorMsgAddIssue, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusInternalServerError, ErrorMsgAddIssue, err)
+		return
+	}
+
+	p.trackChannelList(userID, sourceWebapp, "add")
+
+	p.sendChannelRefreshEvent(channelID)
+}
+
+func (p *Plugin) handleChannelIssueAction(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	channelID := mux.Vars(r)["channel_id"]
+	action := mux.Vars(r)["action"]
+
+	actionRequest, err := GetChannelIssuePayloadFromJSON(r.Body)
+	if err != nil {
+		msg := "Unable to get channel issue request payload from JSON"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusBadRequest, msg, err)
+		return
+	}
+
+	if err = actionRequest.IsValid(); err != nil {
+		p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to validate channel issue request payload.", err)
+		return
+	}
+
+	if actionRequest.ID, err = p.listManager.ResolveIssueID(channelID, actionRequest.ID); err != nil {
+		p.handleIssueNotResolved(w, err)
+		return
+	}
+
+	switch action {
+	case channelActionClaim:
+		_, err = p.listManager.ClaimChannelIssue(channelID, userID, actionRequest.ID)
+	case channelActionComplete:
+		_, err = p.listManager.CompleteChannelIssue(channelID, userID, actionRequest.ID)
+	case channelActionRemove:
+		_, err = p.listManager.RemoveChannelIssue(channelID, actionRequest.ID)
+	default:
+		p.handleErrorWithCode(w, http.StatusNotFound, "Unknown channel action", fmt.Errorf("invalid action `%s`", action))
+		return
+	}
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, ErrIssueNotFound) {
+			code = http.StatusNotFound
+		}
+		msg := fmt.Sprintf("Unable to %s channel issue", action)
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, code, msg, err)
+		return
+	}
+
+	p.trackChannelList(userID, sourceWebapp, action)
+
+	p.sendChannelRefreshEvent(channelID)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelList(t *testing.T) {
+	channelID := model.NewId()
+	alice, bob := model.NewId(), model.NewId()
+	store := newMemStore()
+	api := &plugintest.API{}
+	api.On("GetUser", alice).Return(&model.User{Id: alice, Username: "alice"}, nil)
+	api.On("GetUser", bob).Return(&model.User{Id: bob, Username: "bob"}, nil)
+	l := &listManager{store: store, api: api}
+
+	first := newIssue("Book the room", "", "", "")
+	second := newIssue("Order the pizza", "", "", "")
+	require.NoError(t, l.AddChannelIssue(channelID, first))
+	require.NoError(t, l.AddChannelIssue(channelID, second))
+	require.NoError(t, l.AddIssue(alice, newIssue("Own todo", "", "", "")))
+
+	// Channels are kept apart from the users getting reminders
+	userIDs, err := store.GetUserIDs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{alice}, userIDs)
+	channelIDs, err := store.GetChannelIDs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{channelID}, channelIDs)
+
+	_, err = l.ClaimChannelIssue(channelID, alice, first.ID)
+	require.NoError(t, err)
+	issues, err := l.GetIssueList(channelID, ChannelListKey)
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.Equal(t, "alice", issues[0].ClaimedByUser)
+
+	issueID, err := l.ResolveIssueID(channelID, "#2")
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, issueID)
+
+	_, err = l.CompleteChannelIssue(channelID, bob, first.ID)
+	require.NoError(t, err)
+	done, err := l.GetIssueList(channelID, ChannelDoneListKey)
+	require.NoError(t, err)
+	require.Len(t, done, 1)
+	assert.Equal(t, "bob", done[0].CompletedByUser)
+	_, err = l.ClaimChannelIssue(channelID, alice, first.ID)
+	assert.ErrorIs(t, err, ErrIssueNotFound)
+
+	// Todos of other channels cannot be used
+	_, err = l.CompleteChannelIssue(model.NewId(), bob, second.ID)
+	assert.ErrorIs(t, err, ErrIssueNotFound)
+
+	report, err := l.Fsck("", false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Users)
+	assert.Equal(t, 1, report.Channels)
+	assert.Empty(t, report.Problems)
+
+	_, err = l.RemoveChannelIssue(channelID, first.ID)
+	require.NoError(t, err)
+	_, err = l.RemoveChannelIssue(channelID, second.ID)
+	require.NoError(t, err)
+	assert.NotContains(t, store.issues, first.ID)
+	assert.NotContains(t, store.issues, second.ID)
+}
+
+func TestChannelCommandMembership(t *testing.T) {
+	channelID, userID := model.NewId(), model.NewId()
+	api := &plugintest.API{}
+	api.On("GetChannelMember", channelID, userID).Return(nil, model.NewAppError("", "", nil, "", 404))
+
+	p := &Plugin{}
+	p.SetAPI(api)
+
+	isUserError, err := p.runChannelCommand(&commandArgs{}, &model.CommandArgs{ChannelId: channelID, UserId: userID})
+	assert.True(t, isUserError)
+	assert.ErrorIs(t, err, ErrNotChannelMember)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+const (
+	// ChecklistAdd appends an item to the checklist
+	ChecklistAdd = "add"
+	// ChecklistToggle marks an item as done, or as not done if it was
+	ChecklistToggle = "toggle"
+	// ChecklistRemove removes an item from the checklist
+	ChecklistRemove = "remove"
+	// ChecklistMove moves an item to another position of the checklist
+	ChecklistMove = "move"
+
+	maxChecklistItems = 100
+)
+
+var (
+	// ErrChecklistItemNotFound is returned when a checklist update refers to an item the issue does not have
+	ErrChecklistItemNotFound = errors.New("cannot find checklist item")
+	// ErrChecklistFull is returned when adding an item to a checklist that has the maximum number of items
+	ErrChecklistFull = fmt.Errorf("checklists can have at most %d items", maxChecklistItems)
+)
+
+// ChecklistItem is a subtask of a todo
+type ChecklistItem struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+	Done bool   `json:"done,omitempty"`
+}
+
+// ChecklistUpdate is an operation on the checklist of an issue. Items are identified by ID, which
+// is the same on the issues of the sender and the receiver of a todo, so the update can be applied
+// to both.
+type ChecklistUpdate struct {
+	Action string
+	ItemID string
+	Text   string
+	// Position is the zero based position an item is moved to
+	Position int
+}
+
+// newChecklistAdd returns the update adding a new item with text
+func newChecklistAdd(text string) *ChecklistUpdate {
+	return &ChecklistUpdate{Action: ChecklistAdd, ItemID: model.NewId(), Text: text}
+}
+
+func (u *ChecklistUpdate) apply(issue *Issue) error {
+	if u.Action == ChecklistAdd {
+		if len(issue.Checklist) >= maxChecklistItems {
+			return ErrChecklistFull
+		}
+		issue.Checklist = append(issue.Checklist, ChecklistItem{ID: u.ItemID, Text: u.Text})
+		return nil
+	}
+
+	n := checklistItemIndex(issue.Checklist, u.ItemID)
+	if n < 0 {
+		return ErrChecklistItemNotFound
+	}
+
+	switch u.Action {
+	case ChecklistToggle:
+		issue.Checklist[n].Done = !issue.Checklist[n].Done
+	case ChecklistRemove:
+		issue.Checklist = append(issue.Checklist[:n:n], issue.Checklist[n+1:]...)
+	case ChecklistMove:
+		item := issue.Checklist[n]
+		checklist := append(issue.Checklist[:n:n], issue.Checklist[n+1:]...)
+		position := u.Position
+		if position > len(checklist) {
+			position = len(checklist)
+		}
+		issue.Checklist = append(checklist[:position:position], append([]ChecklistItem{item}, checklist[position:]...)...)
+	}
+
+	return nil
+}
+
+func checklistItemIndex(checklist []ChecklistItem, itemID string) int {
+	for i, item := range checklist {
+		if item.ID == itemID {
+			return i
+		}
+	}
+	return -1
+}
+
+// copyChecklist returns a copy of checklist that can be modified independently
+func copyChecklist(checklist []ChecklistItem) []ChecklistItem {
+	if checklist == nil {
+		return nil
+	}
+	return append([]ChecklistItem{}, checklist...)
+}
+
+// checklistProgress returns how many items of the checklist of issue are done, as in 3/5, or empty
+// if it has no checklist
+func checklistProgress(issue *Issue) string {
+	if len(issue.Checklist) == 0 {
+		return ""
+	}
+
+	done := 0
+	for _, item := range issue.Checklist {
+		if item.Done {
+			done++
+		}
+	}
+	return fmt.Sprintf("%d/%d", done, len(issue.Checklist))
+}
+
+// issueSummary returns the message of issue followed by the progress of its checklist, if any
+func issueSummary(issue *Issue) string {
+	if progress := checklistProgress(issue); progress != "" {
+		return fmt.Sprintf("%s (%s)", issue.Message, progress)
+	}
+	return issue.Message
+}
+
+// checklistToString formats the checklist of issue with the position of each item
+func checklistToString(issue *Issue) string {
+	if len(issue.Checklist) == 0 {
+		return "The Todo has no checklist."
+	}
+
+	str := ""
+	for i, item := range issue.Checklist {
+		check := " "
+		if item.Done {
+			check = "x"
+		}
+		str += fmt.Sprintf("%d. [%s] %s\n", i+1, check, item.Text)
+	}
+	return str
+}
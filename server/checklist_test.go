@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checklistTexts(issue *Issue) []string {
+	texts := []string{}
+	for _, item := range issue.Checklist {
+		texts = append(texts, item.Text)
+	}
+	return texts
+}
+
+func TestChecklistUpdate(t *testing.T) {
+	issue := newIssue("trip", "", "", "")
+	for _, text := range []string{"flights", "hotel", "car"} {
+		require.NoError(t, newChecklistAdd(text).apply(issue))
+	}
+	assert.Equal(t, []string{"flights", "hotel", "car"}, checklistTexts(issue))
+	assert.Equal(t, "trip (0/3)", issueSummary(issue))
+
+	require.NoError(t, (&ChecklistUpdate{Action: ChecklistToggle, ItemID: issue.Checklist[1].ID}).apply(issue))
+	assert.True(t, issue.Checklist[1].Done)
+	assert.Equal(t, "trip (1/3)", issueSummary(issue))
+	assert.Equal(t, "1. [ ] flights\n2. [x] hotel\n3. [ ] car\n", checklistToString(issue))
+
+	require.NoError(t, (&ChecklistUpdate{Action: ChecklistMove, ItemID: issue.Checklist[2].ID, Position: 0}).apply(issue))
+	assert.Equal(t, []string{"car", "flights", "hotel"}, checklistTexts(issue))
+
+	require.NoError(t, (&ChecklistUpdate{Action: ChecklistMove, ItemID: issue.Checklist[0].ID, Position: 10}).apply(issue))
+	assert.Equal(t, []string{"flights", "hotel", "car"}, checklistTexts(issue))
+
+	require.NoError(t, (&ChecklistUpdate{Action: ChecklistRemove, ItemID: issue.Checklist[0].ID}).apply(issue))
+	assert.Equal(t, []string{"hotel", "car"}, checklistTexts(issue))
+	assert.Equal(t, "trip (1/2)", issueSummary(issue))
+
+	err := (&ChecklistUpdate{Action: ChecklistToggle, ItemID: "missing"}).apply(issue)
+	assert.ErrorIs(t, err, ErrChecklistItemNotFound)
+
+	for len(issue.Checklist) < maxChecklistItems {
+		require.NoError(t, newChecklistAdd("more").apply(issue))
+	}
+	assert.ErrorIs(t, newChecklistAdd("too many").apply(issue), ErrChecklistFull)
+}
@@ -3,11 +3,14 @@ package main
 import (
 	"errors"
 	"fmt"
-	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/mattermost/mattermost/server/public/plugin"
+
+	"github.com/mattermost/mattermost-plugin-todo/server/dateparse"
 )
 
 const (
@@ -15,6 +18,10 @@ const (
 	MyFlag            = "my"
 	InFlag            = "in"
 	OutFlag           = "out"
+	DoneFlag          = "done"
+
+	sortByPriority = "priority"
+	dueDateNone    = "none"
 )
 
 func getHelp() string {
@@ -25,6 +32,33 @@ add [message]
 
 	example: /todo add Don't forget to be awesome
 
+add --due [date] [message]
+	Adds a Todo with a due date in your timezone, written like tomorrow, next friday 3pm, in 2 days, eod or 2025-01-31. Quote the date if the message starts with a word that could be part of it.
+
+	example: /todo add --due next friday 3pm Submit the report
+
+add --priority [low, normal, high, urgent] [message]
+	Adds a Todo with a priority.
+
+	example: /todo add --priority high Fix the build
+
+add --desc [description] [message]
+	Adds a Todo with a description. Quote texts with spaces, messages can span several lines.
+
+	example: /todo add --desc "It fails on the CI" Fix the build
+	example: /todo add "Fix the build" --desc "It fails on the CI"
+
+add --tag [tags] [message]
+	Adds a Todo with tags, given as a comma separated list. Words of the message like #release are tags too.
+
+	example: /todo add --tag oncall Rotate the certificates
+	example: /todo add Update the changelog #release
+
+add --repeat [rule] [message]
+	Adds a repeating Todo. Once completed or popped, the next one is added with its due date, or sent again if it was sent. Rules are like daily, weekdays, weekly on mon,fri, every 2 weeks, monthly on the 15th or an RRULE, and --repeat none stops repeating a Todo on edit.
+
+	example: /todo add --due friday 5pm --repeat weekly Submit timesheet
+
 list
 	Lists your Todo issues.
 
@@ -33,35 +67,216 @@ list [listName]
 
 	example: /todo list in
 	example: /todo list out
+	example: /todo list done
 	example (same as /todo list): /todo list my
+	example: /todo list Someday
+
+list [listName] --sort priority
+	List your issues sorted by priority
+
+	example: /todo list my --sort priority
+
+list [listName] --tag [tag]
+	List your issues with a tag
+
+	example: /todo list my --tag release
 
 pop
 	Removes the Todo issue at the top of the list.
 
-send [user] [message]
-	Sends some user a Todo
+pop priority
+	Removes the first Todo issue with the highest priority in the list.
+
+send [users] [message]
+	Sends some users a Todo. Users are given by their username, a user group or a ~channel, separated by commas or each starting with @ or ~.
+	Several users get a Todo each, and you keep a single one on your sent list telling how many of them are done.
 
 	example: /todo send @awesomePerson Don't forget to be awesome
+	example: /todo send @awesomePerson --due tomorrow 5pm Submit the report
+	example: /todo send @alice @bob ~backend-team Update the sprint board
+
+lists
+	Shows the lists you created to organize your Todos. Their names can be used wherever a list name is accepted, quoted if they have spaces.
+
+lists create [name]
+	Creates a new list.
+
+	example: /todo lists create Release 9.0
+
+lists rename [name] [new name]
+	Renames a list.
+
+	example: /todo lists rename "Release 9.0" "Release 9.1"
+
+lists delete [name]
+	Deletes a list, moving its Todos to your own list.
+
+	example: /todo lists delete Someday
+
+lists move [name] [position]
+	Moves a list to another position among your lists.
+
+	example: /todo lists move Someday 1
+
+channel
+	Lists the Todos shared by the members of the current channel. Any member can add, claim, complete or remove them.
+
+channel list done
+	Lists the completed Todos of the current channel.
+
+channel add [message]
+	Adds a Todo to the list of the current channel. It takes the same flags as add, except --repeat.
+
+	example: /todo channel add --due friday Prepare the release notes
+
+channel claim [position or #id]
+	Tells the channel you are taking care of a Todo of its list.
+
+	example: /todo channel claim 2
+
+channel done [position or #id]
+	Completes a Todo of the list of the current channel.
+
+	example: /todo channel done #7
+
+channel remove [position or #id]
+	Removes a Todo from the list of the current channel.
+
+move [listName] [position or #id] [list or new position]
+	Moves a Todo of your own list or of one of your lists to another of them. Received Todos keep telling their sender when they are completed.
+	Given a number instead of a list, moves the Todo to that position of its list.
+
+	example: /todo move 3 Someday
+	example: /todo move Someday 1 my
+	example: /todo move #42 1
+
+done [listName] [position or #id]
+	Completes the Todo at the given position of a list, your own list if none is given, or the Todo with the given #id. The list can also be given with --list.
+
+	example: /todo done 2
+	example: /todo done in 1
+	example: /todo done #42
+
+remove [listName] [position or #id]
+	Removes the Todo at the given position of a list, your own list if none is given.
+
+	example: /todo remove out 3
+
+edit [listName] [position or #id] [message]
+	Changes the message of the Todo at the given position of a list, your own list if none is given. The --due, --priority, --desc, --repeat and --tag flags change the rest of its fields, use --due none to remove the due date and --tag none to remove the tags not written on the message.
+
+	example: /todo edit 2 Don't forget to be extra awesome
+	example: /todo edit #42 --priority urgent --due 2025-01-31
+
+accept [position or #id]
+	Accepts the received Todo at the given position, moving it to your own list.
+
+	example: /todo accept 1
+
+decline [position or #id]
+	Declines the received Todo at the given position.
+
+	example: /todo decline 1
+
+bump [position or #id]
+	Moves the sent Todo at the given position to the top of its receiver's list.
+
+	example: /todo bump 1
+
+assign [listName] [position or #id] [user]
+	Sends the Todo at the given position of a list, your own list if none is given, to some user.
+
+	example: /todo assign 2 @awesomePerson
+
+checklist add [listName] [position or #id] [text]
+	Adds an item to the checklist of a Todo. Sent Todos share their checklist with the receiver.
+
+	example: /todo checklist add 2 Book the flights
+
+checklist check [listName] [position or #id] [item]
+	Checks the item at the given position of the checklist of a Todo, or unchecks it if it was checked.
+
+	example: /todo checklist check #42 1
+
+checklist remove [listName] [position or #id] [item]
+	Removes the item at the given position of the checklist of a Todo.
+
+	example: /todo checklist remove 2 3
+
+checklist move [listName] [position or #id] [item] [new position]
+	Moves an item of the checklist of a Todo to a new position.
+
+	example: /todo checklist move 2 3 1
+
+checklist show [listName] [position or #id]
+	Shows the checklist of a Todo.
+
+	example: /todo checklist show out 1
+
+snooze [listName] [position or #id] [time]
+	Hides a Todo of your own or received lists until the given time, like tomorrow, monday 9am or in 2 hours. It then moves back to the top of its list and you get a message. Dates without time wake up at 9:00.
+
+	example: /todo snooze 2 tomorrow
+	example: /todo snooze in 1 next week
+
+unsnooze [listName] [position or #id]
+	Moves a snoozed Todo back to the top of its list right away.
+
+	example: /todo unsnooze #42
+
+undo
+	Restores the last Todo you completed, removed or popped in the last 5 minutes.
 
 settings summary [on, off]
 	Sets user preference on daily reminders
 
 	example: /todo settings summary on
 
+settings summary hour [0-23]
+	Sets the hour of the day after which the reminder is sent, as a number or a time like 9am
+
+	example: /todo settings summary hour 8
+
+settings summary days [days]
+	Sets the days of the week the reminder is sent on, as a comma separated list of sun, mon, tue, wed, thu, fri and sat, or weekdays or all
+
+	example: /todo settings summary days sun,mon,tue,wed,thu
+
+settings summary frequency [daily, weekly]
+	Sets whether the reminder is sent every day or once a week
+
+	example: /todo settings summary frequency weekly
+
 settings allow_incoming_task_requests [on, off]
 	Allow other Mattermost users to send a task for you to accept/decline?
 
 	example: /todo settings allow_incoming_task_requests on
 
+settings timezone [timezone, auto]
+	Sets the IANA timezone of your daily reminders and due dates. Use auto to follow your profile timezone.
+
+	example: /todo settings timezone Europe/Madrid
+
 
 help
 	Display usage.
 `
 }
 
-func getSummarySetting(flag bool) string {
+func getAdminHelp() string {
+	return `Available Admin Commands:
+
+admin fsck [user] [--fix]
+	Checks the Todo lists of a user, or of all users, for broken references, and fixes them if --fix is given.
+
+	example: /todo admin fsck @awesomePerson
+	example: /todo admin fsck --fix
+`
+}
+
+func getSummarySetting(flag bool, schedule *ReminderSchedule) string {
 	if flag {
-		return "Reminder setting is set to `on`. **You will receive daily reminders.**"
+		return fmt.Sprintf("Reminder setting is set to `on`. **You will receive reminders %s.**", schedule.String())
 	}
 	return "Reminder setting is set to `off`. **You will not receive daily reminders.**"
 }
@@ -72,12 +287,20 @@ func getAllowIncomingTaskRequestsSetting(flag bool) string {
 	return "Allow incoming task requests setting is set to `off`. **Other users cannot send you task request. They will see a message saying you don't accept Todo requests.**"
 }
 
-func getAllSettings(summaryFlag, blockIncomingFlag bool) string {
+func getTimezoneSetting(timezone string, loc *time.Location) string {
+	if timezone == "" {
+		return fmt.Sprintf("Timezone setting is set to `auto`. **Your profile timezone, %s, is used.**", loc.String())
+	}
+	return fmt.Sprintf("Timezone setting is set to `%s`.", timezone)
+}
+
+func getAllSettings(summaryFlag bool, schedule *ReminderSchedule, blockIncomingFlag bool, timezoneSetting string) string {
 	return fmt.Sprintf(`Current Settings:
 
 %s
 %s
-	`, getSummarySetting(summaryFlag), getAllowIncomingTaskRequestsSetting(blockIncomingFlag))
+%s
+	`, getSummarySetting(summaryFlag, schedule), getAllowIncomingTaskRequestsSetting(blockIncomingFlag), timezoneSetting)
 }
 
 func getCommand() *model.Command {
@@ -86,7 +309,7 @@ func getCommand() *model.Command {
 		DisplayName:      "Todo Bot",
 		Description:      "Interact with your Todo list.",
 		AutoComplete:     true,
-		AutoCompleteDesc: "Available commands: add, list, pop, send, help",
+		AutoCompleteDesc: "Available commands: add, list, pop, send, done, remove, edit, accept, decline, bump, assign, undo, help",
 		AutoCompleteHint: "[command]",
 		AutocompleteData: getAutocompleteData(),
 	}
@@ -101,23 +324,40 @@ func (p *Plugin) postCommandResponse(args *model.CommandArgs, text string) {
 	_ = p.API.SendEphemeralPost(args.UserId, post)
 }
 
+// commandAllowedFlags are the flags each command supports
+var commandAllowedFlags = map[string][]string{
+	"add":       {flagDue, flagPriority, flagDesc, flagRepeat, flagTag},
+	"send":      {flagDue, flagPriority, flagDesc, flagRepeat, flagTag},
+	"list":      {flagList, flagSort, flagTag},
+	"done":      {flagList},
+	"remove":    {flagList},
+	"edit":      {flagList, flagDue, flagPriority, flagDesc, flagRepeat, flagTag},
+	"assign":    {flagList},
+	"checklist": {flagList},
+	"move":      {flagList},
+	"channel":   {flagDue, flagPriority, flagDesc, flagTag},
+	"snooze":    {flagList},
+	"unsnooze":  {flagList},
+	"admin":     {flagFix},
+}
+
 // ExecuteCommand executes a given command and returns a command response.
 func (p *Plugin) ExecuteCommand(_ *plugin.Context, args *model.CommandArgs) (*model.CommandResponse, *model.AppError) {
-	spaceRegExp := regexp.MustCompile(`\s+`)
-	trimmedArgs := spaceRegExp.ReplaceAllString(strings.TrimSpace(args.Command), " ")
-	stringArgs := strings.Split(trimmedArgs, " ")
-	lengthOfArgs := len(stringArgs)
-	restOfArgs := []string{}
-
-	var handler func([]string, *model.CommandArgs) (bool, error)
-	if lengthOfArgs == 1 {
+	p.ensureReminderUser(args.UserId)
+
+	parsedArgs, err := parseCommandArgs(args.Command)
+	if err != nil {
+		p.postCommandResponse(args, fmt.Sprintf("__Error: %s.__\n\nRun `/todo help` for usage instructions.", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	command := ""
+	var handler func(*commandArgs, *model.CommandArgs) (bool, error)
+	if parsedArgs.len() < 2 {
 		handler = p.runListCommand
 		p.trackCommand(args.UserId, "")
 	} else {
-		command := stringArgs[1]
-		if lengthOfArgs > 2 {
-			restOfArgs = stringArgs[2:]
-		}
+		command = parsedArgs.values[1]
 		switch command {
 		case "add":
 			handler = p.runAddCommand
@@ -129,6 +369,36 @@ func (p *Plugin) ExecuteCommand(_ *plugin.Context, args *model.CommandArgs) (*mo
 			handler = p.runSendCommand
 		case "settings":
 			handler = p.runSettingsCommand
+		case "undo":
+			handler = p.runUndoCommand
+		case "done":
+			handler = p.runDoneCommand
+		case "remove":
+			handler = p.runRemoveCommand
+		case "edit":
+			handler = p.runEditCommand
+		case "accept":
+			handler = p.runAcceptCommand
+		case "decline":
+			handler = p.runDeclineCommand
+		case "bump":
+			handler = p.runBumpCommand
+		case "assign":
+			handler = p.runAssignCommand
+		case "checklist":
+			handler = p.runChecklistCommand
+		case "lists":
+			handler = p.runListsCommand
+		case "move":
+			handler = p.runMoveCommand
+		case "channel":
+			handler = p.runChannelCommand
+		case "snooze":
+			handler = p.runSnoozeCommand
+		case "unsnooze":
+			handler = p.runUnsnoozeCommand
+		case "admin":
+			handler = p.runAdminCommand
 		default:
 			if command == "help" {
 				p.trackCommand(args.UserId, command)
@@ -140,7 +410,12 @@ func (p *Plugin) ExecuteCommand(_ *plugin.Context, args *model.CommandArgs) (*mo
 		}
 		p.trackCommand(args.UserId, command)
 	}
-	isUserError, err := handler(restOfArgs, args)
+
+	isUserError := true
+	err = checkAllowedFlags(parsedArgs, command)
+	if err == nil {
+		isUserError, err = handler(parsedArgs.rest(2), args)
+	}
 	if err != nil {
 		if isUserError {
 			p.postCommandResponse(args, fmt.Sprintf("__Error: %s.__\n\nRun `/todo help` for usage instructions.", err.Error()))
@@ -153,69 +428,95 @@ func (p *Plugin) ExecuteCommand(_ *plugin.Context, args *model.CommandArgs) (*mo
 	return &model.CommandResponse{}, nil
 }
 
-func (p *Plugin) runSendCommand(args []string, extra *model.CommandArgs) (bool, error) {
-	if len(args) < 2 {
+// checkAllowedFlags returns an error if args has flags not supported by command. The command
+// without name lists the issues.
+func checkAllowedFlags(args *commandArgs, command string) error {
+	if command == "" {
+		command = "list"
+	}
+
+	for name := range args.flags {
+		allowed := false
+		for _, allowedName := range commandAllowedFlags[command] {
+			allowed = allowed || allowedName == name
+		}
+		if !allowed {
+			return fmt.Errorf("`--%s` is not supported by `%s`", name, command)
+		}
+	}
+
+	return nil
+}
+
+func (p *Plugin) runSendCommand(args *commandArgs, extra *model.CommandArgs) (bool, error) {
+	if args.len() < 2 {
 		p.postCommandResponse(extra, "You must specify a user and a message.\n"+getHelp())
 		return false, nil
 	}
 
-	userName := args[0]
-	if args[0][0] == '@' {
-		userName = args[0][1:]
+	// The first argument names the receivers, and so do the following ones written as @user or ~channel
+	targets := parseRecipients(args.values[0])
+	n := 1
+	for ; n < args.len()-1 && isRecipientArg(args.values[n]); n++ {
+		targets = append(targets, args.values[n])
 	}
-	receiver, appErr := p.API.GetUserByUsername(userName)
-	if appErr != nil {
-		p.postCommandResponse(extra, "Please, provide a valid user.\n"+getHelp())
-		return false, nil
+
+	receivers, includesSender, err := p.resolveRecipients(extra.UserId, extra.TeamId, targets)
+	if err != nil {
+		return errors.Is(err, ErrInvalidRecipients), err
 	}
 
-	if receiver.Id == extra.UserId {
-		return p.runAddCommand(args[1:], extra)
+	if len(receivers) == 0 {
+		if includesSender {
+			return p.runAddCommand(args.rest(n), extra)
+		}
+		return true, fmt.Errorf("%w: there is nobody else to send the Todo to", ErrInvalidRecipients)
 	}
 
-	receiverAllowIncomingTaskRequestsPreference, err := p.getAllowIncomingTaskRequestsPreference(receiver.Id)
+	issue, err := newIssueFromArgs(args.rest(n), p.getUserLocation(extra.UserId))
 	if err != nil {
-		p.API.LogError("Error when getting allow incoming task request preference, err=", err)
-		receiverAllowIncomingTaskRequestsPreference = true
+		return true, err
 	}
-	if !receiverAllowIncomingTaskRequestsPreference {
-		p.postCommandResponse(extra, fmt.Sprintf("@%s has blocked Todo requests", userName))
+
+	if issue.Message == "" {
+		p.postCommandResponse(extra, "You must specify a user and a message.\n"+getHelp())
 		return false, nil
 	}
 
-	message := strings.Join(args[1:], " ")
+	senderName := p.listManager.GetUserName(extra.UserId)
+	receiverMessage := fmt.Sprintf("You have received a new Todo from @%s", senderName)
 
-	receiverIssueID, err := p.listManager.SendIssue(extra.UserId, receiver.Id, message, "", "", "")
+	sent, blocked, err := p.sendIssueToReceivers(extra.UserId, issue, receivers, receiverMessage)
 	if err != nil {
 		return false, err
 	}
 
-	p.trackSendIssue(extra.UserId, sourceCommand, false)
-
-	p.sendRefreshEvent(extra.UserId, []string{OutListKey})
-	p.sendRefreshEvent(receiver.Id, []string{InListKey})
-
-	responseMessage := fmt.Sprintf("Todo sent to @%s.", userName)
+	responseMessage := ""
+	if len(sent) > 0 {
+		p.trackSendIssue(extra.UserId, sourceCommand, false)
 
-	senderName := p.listManager.GetUserName(extra.UserId)
-
-	receiverMessage := fmt.Sprintf("You have received a new Todo from @%s", senderName)
+		responseMessage = fmt.Sprintf("Todo sent to %s.", usernamesText(sent))
+	}
+	if len(blocked) > 0 {
+		responseMessage += "\n" + blockedText(blocked)
+	}
 
-	postPermalink := ""
-	p.PostBotCustomDM(receiver.Id, receiverMessage, message, postPermalink, receiverIssueID)
-	p.postCommandResponse(extra, responseMessage)
+	p.postCommandResponse(extra, strings.TrimSpace(responseMessage))
 	return false, nil
 }
 
-func (p *Plugin) runAddCommand(args []string, extra *model.CommandArgs) (bool, error) {
-	message := strings.Join(args, " ")
+func (p *Plugin) runAddCommand(args *commandArgs, extra *model.CommandArgs) (bool, error) {
+	issue, err := newIssueFromArgs(args, p.getUserLocation(extra.UserId))
+	if err != nil {
+		return true, err
+	}
 
-	if message == "" {
+	if issue.Message == "" {
 		p.postCommandResponse(extra, "Please add a task.")
 		return false, nil
 	}
 
-	newIssue, err := p.listManager.AddIssue(extra.UserId, message, "", "", "")
+	err = p.listManager.AddIssue(extra.UserId, issue)
 	if err != nil {
 		return false, err
 	}
@@ -237,31 +538,52 @@ func (p *Plugin) runAddCommand(args []string, extra *model.CommandArgs) (bool, e
 	// list not containing the newly-added issue, so we check for that and
 	// append the issue manually if necessary.
 	var issueIncluded bool
-	for _, issue := range issues {
-		if newIssue.ID == issue.ID {
+	for _, listIssue := range issues {
+		if issue.ID == listIssue.ID {
 			issueIncluded = true
 			break
 		}
 	}
 	if !issueIncluded {
 		issues = append(issues, &ExtendedIssue{
-			Issue: *newIssue,
+			Issue: *issue,
 		})
 	}
 
 	responseMessage += listHeaderMessage
-	responseMessage += issuesListToString(issues)
+	responseMessage += issuesListToString(issues, p.getUserLocation(extra.UserId))
 	p.postCommandResponse(extra, responseMessage)
 
 	return false, nil
 }
 
-func (p *Plugin) runListCommand(args []string, extra *model.CommandArgs) (bool, error) {
+func (p *Plugin) runListCommand(args *commandArgs, extra *model.CommandArgs) (bool, error) {
 	listID := MyListKey
 	responseMessage := "Todo List:\n\n"
 
-	if len(args) > 0 {
-		switch args[0] {
+	sortBy := args.flag(flagSort)
+	if sortBy != "" && sortBy != sortByPriority {
+		return true, fmt.Errorf("invalid sort `%s`, the only allowed value is %s", sortBy, sortByPriority)
+	}
+
+	label := ""
+	if args.hasFlag(flagTag) {
+		var err error
+		if label, err = normalizeLabel(args.flag(flagTag)); err != nil {
+			return true, err
+		}
+	}
+
+	listName := args.flag(flagList)
+	if args.len() > 0 {
+		if listName != "" {
+			return true, errors.New("too many arguments")
+		}
+		listName = args.text()
+	}
+
+	if listName != "" {
+		switch listName {
 		case MyFlag:
 		case InFlag:
 			listID = InListKey
@@ -269,9 +591,19 @@ func (p *Plugin) runListCommand(args []string, extra *model.CommandArgs) (bool,
 		case OutFlag:
 			listID = OutListKey
 			responseMessage = "Sent Todo list:\n\n"
+		case DoneFlag:
+			listID = DoneListKey
+			responseMessage = "Completed Todo list:\n\n"
 		default:
-			p.postCommandResponse(extra, getHelp())
-			return true, nil
+			list, err := p.listManager.GetCustomList(extra.UserId, listName)
+			if errors.Is(err, ErrListNotFound) {
+				return true, fmt.Errorf("there is no list `%s`, run `/todo lists` to see yours", listName)
+			}
+			if err != nil {
+				return false, err
+			}
+			listID = list.ID
+			responseMessage = fmt.Sprintf("%s list:\n\n", list.Name)
 		}
 	}
 
@@ -280,18 +612,43 @@ func (p *Plugin) runListCommand(args []string, extra *model.CommandArgs) (bool,
 		return false, err
 	}
 
+	var positions map[string]int
+	if sortBy == sortByPriority || label != "" {
+		positions = map[string]int{}
+		for i, issue := range issues {
+			positions[issue.ID] = i + 1
+		}
+	}
+	if label != "" {
+		issues = filterIssuesByLabel(issues, label)
+	}
+	if sortBy == sortByPriority {
+		sortIssuesByPriority(issues)
+	}
+
 	p.sendRefreshEvent(extra.UserId, []string{MyListKey, OutListKey, InListKey})
 
-	responseMessage += issuesListToString(issues)
+	responseMessage += issuesListWithPositionsToString(issues, positions, p.getUserLocation(extra.UserId))
 	p.postCommandResponse(extra, responseMessage)
 
 	return false, nil
 }
 
-func (p *Plugin) runPopCommand(_ []string, extra *model.CommandArgs) (bool, error) {
-	issue, foreignID, err := p.listManager.PopIssue(extra.UserId)
+func (p *Plugin) runPopCommand(args *commandArgs, extra *model.CommandArgs) (bool, error) {
+	var issue *Issue
+	var foreignID string
+	var err error
+
+	switch {
+	case args.len() == 0:
+		issue, foreignID, err = p.listManager.PopIssue(extra.UserId)
+	case args.len() == 1 && args.values[0] == sortByPriority:
+		issue, foreignID, err = p.listManager.PopHighestPriorityIssue(extra.UserId)
+	default:
+		return true, fmt.Errorf("invalid arguments, use `pop` or `pop %s`", sortByPriority)
+	}
 	if err != nil {
-		if err.Error() == "cannot find issue" {
+		if errors.Is(err, ErrIssueNotFound) {
 			p.postCommandResponse(extra, "There are no Todos to pop.")
 			return false, nil
 		}
@@ -310,6 +667,9 @@ func (p *Plugin) runPopCommand(_ []string, extra *model.CommandArgs) (bool, erro
 	p.sendRefreshEvent(extra.UserId, []string{MyListKey})
 
 	responseMessage := "Removed top Todo."
+	if next := p.repeatIssue(extra.UserId, issue, MyListKey, foreignID); next != nil {
+		responseMessage += " " + p.nextOccurrenceText(extra.UserId, next)
+	}
 
 	replyMessage := fmt.Sprintf("@%s popped a todo attached to this thread", userName)
 	p.postReplyIfNeeded(issue.PostID, replyMessage, issue.Message, issue.PostPermalink)
@@ -322,136 +682,1175 @@ func (p *Plugin) runPopCommand(_ []string, extra *model.CommandArgs) (bool, erro
 	}
 
 	responseMessage += listHeaderMessage
-	responseMessage += issuesListToString(issues)
+	responseMessage += issuesListToString(issues, p.getUserLocation(extra.UserId))
 	p.postCommandResponse(extra, responseMessage)
 
 	return false, nil
 }
 
-func (p *Plugin) runSettingsCommand(args []string, extra *model.CommandArgs) (bool, error) {
-	const (
-		on  = "on"
-		off = "off"
-	)
-	if len(args) < 1 {
-		currentSummarySetting := p.getReminderPreference(extra.UserId)
-		currentAllowIncomingTaskRequestsSetting, err := p.getAllowIncomingTaskRequestsPreference(extra.UserId)
-		if err != nil {
-			p.API.LogError("Error when getting allow incoming task request preference, err=", err)
-			currentAllowIncomingTaskRequestsSetting = true
+func (p *Plugin) runUndoCommand(_ *commandArgs, extra *model.CommandArgs) (bool, error) {
+	tombstone, err := p.listManager.UndoLastAction(extra.UserId)
+	if err != nil {
+		if errors.Is(err, ErrNothingToUndo) {
+			p.postCommandResponse(extra, "There is nothing to undo.")
+			return false, nil
 		}
-		p.postCommandResponse(extra, getAllSettings(currentSummarySetting, currentAllowIncomingTaskRequestsSetting))
-		return false, nil
+		return false, err
 	}
 
-	switch args[0] {
-	case "summary":
-		if len(args) < 2 {
-			currentSummarySetting := p.getReminderPreference(extra.UserId)
-			p.postCommandResponse(extra, getSummarySetting(currentSummarySetting))
-			return false, nil
+	p.trackUndo(extra.UserId, tombstone.Action)
+
+	p.notifyUndo(extra.UserId, tombstone)
+
+	p.postCommandResponse(extra, fmt.Sprintf("Restored Todo: %s", tombstone.Issue.Message))
+	return false, nil
+}
+
+// listIDFromFlag returns the list named by flag, among the lists todos can be acted on from
+func listIDFromFlag(flag string) (string, bool) {
+	switch flag {
+	case MyFlag:
+		return MyListKey, true
+	case InFlag:
+		return InListKey, true
+	case OutFlag:
+		return OutListKey, true
+	}
+	return "", false
+}
+
+// listIDFromName returns the list of userID called name, which is a list flag or the name of one of
+// their custom lists
+func (p *Plugin) listIDFromName(userID, name string) (string, bool, error) {
+	if listID, ok := listIDFromFlag(name); ok {
+		return listID, true, nil
+	}
+
+	list, err := p.listManager.GetCustomList(userID, name)
+	if errors.Is(err, ErrListNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	return list.ID, true, nil
+}
+
+// findIssueFromArgs finds the issue addressed by the first arguments, as an optional list name
+// followed by the position of the issue on that list, its short ID such as #42, or its ID. listID
+// is used when no list name is given. It returns the issue along with the rest of the arguments.
+func (p *Plugin) findIssueFromArgs(args *commandArgs, userID, listID string, allowListName bool) (*ExtendedIssue, *commandArgs, bool, error) {
+	listNamed := false
+	listName := listDisplayName(listID)
+	if name := args.flag(flagList); allowListName && name != "" {
+		id, ok, err := p.listIDFromName(userID, name)
+		if err != nil {
+			return nil, nil, false, err
 		}
-		if len(args) > 2 {
-			return true, errors.New("too many arguments")
+		if !ok {
+			return nil, nil, true, fmt.Errorf("invalid list `%s`, allowed values are %s, %s, %s and the names of your lists", name, MyFlag, InFlag, OutFlag)
 		}
-		var responseMessage string
-		var err error
-
-		switch args[1] {
-		case on:
-			err = p.saveReminderPreference(extra.UserId, true)
-			responseMessage = "You will start receiving daily summaries."
-		case off:
-			err = p.saveReminderPreference(extra.UserId, false)
-			responseMessage = "You will stop receiving daily summaries."
-		default:
-			responseMessage = "invalid input, allowed values for \"settings summary\" are `on` or `off`"
-			return true, errors.New(responseMessage)
+		listID, listName = id, name
+		listNamed = true
+	} else if allowListName && args.len() > 1 {
+		id, ok, err := p.listIDFromName(userID, args.values[0])
+		if err != nil {
+			return nil, nil, false, err
 		}
+		if ok {
+			listID, listName = id, args.values[0]
+			listNamed = true
+			args = args.rest(1)
+		}
+	}
+
+	if args.len() == 0 {
+		return nil, nil, true, errors.New("you must specify a Todo by its position on the list or its #id")
+	}
 
+	ref := args.values[0]
+	if position, err := strconv.Atoi(ref); err == nil {
+		issues, err := p.listManager.GetIssueList(userID, listID)
 		if err != nil {
-			responseMessage = "error saving the reminder preference"
-			p.API.LogDebug("runSettingsCommand: error saving the reminder preference", "error", err.Error())
-			return false, errors.New(responseMessage)
+			return nil, nil, false, err
+		}
+		if position < 1 || position > len(issues) {
+			return nil, nil, true, fmt.Errorf("there is no Todo at position %d of the %s list", position, listName)
 		}
+		return issues[position-1], args.rest(1), false, nil
+	}
 
-		p.postCommandResponse(extra, responseMessage)
+	issueID, err := p.listManager.ResolveIssueID(userID, ref)
+	if err != nil {
+		if errors.Is(err, ErrIssueNotFound) {
+			return nil, nil, true, fmt.Errorf("there is no Todo `%s`", ref)
+		}
+		return nil, nil, false, err
+	}
 
-	case "allow_incoming_task_requests":
-		if len(args) < 2 {
-			currentAllowIncomingTaskRequestsSetting, err := p.getAllowIncomingTaskRequestsPreference(extra.UserId)
-			if err != nil {
-				p.API.LogError("unable to parse the allow incoming task requests preference, err=", err.Error())
-				currentAllowIncomingTaskRequestsSetting = true
-			}
-			p.postCommandResponse(extra, getAllowIncomingTaskRequestsSetting(currentAllowIncomingTaskRequestsSetting))
-			return false, nil
+	// Todos addressed by ID are looked for on all the lists, unless one is given
+	listIDs := []string{listID}
+	if allowListName && !listNamed {
+		listIDs = []string{MyListKey, InListKey, OutListKey}
+		customLists, err := p.listManager.GetCustomLists(userID)
+		if err != nil {
+			return nil, nil, false, err
 		}
-		if len(args) > 2 {
-			return true, errors.New("too many arguments")
+		for _, list := range customLists {
+			listIDs = append(listIDs, list.ID)
 		}
-		var responseMessage string
-		var err error
+	}
 
-		switch args[1] {
-		case on:
-			err = p.saveAllowIncomingTaskRequestsPreference(extra.UserId, true)
-			responseMessage = "Other users can send task for you to accept/decline"
-		case off:
-			err = p.saveAllowIncomingTaskRequestsPreference(extra.UserId, false)
-			responseMessage = "Other users cannot send you task request. They will see a message saying you have blocked incoming task requests"
-		default:
-			responseMessage = "invalid input, allowed values for \"settings allow_incoming_task_requests\" are `on` or `off`"
-			return true, errors.New(responseMessage)
+	for _, id := range listIDs {
+		issues, err := p.listManager.GetIssueList(userID, id)
+		if err != nil {
+			return nil, nil, false, err
 		}
 
-		if err != nil {
-			responseMessage = "error saving the block_incoming preference"
-			p.API.LogDebug("runSettingsCommand: error saving the block_incoming preference", "error", err.Error())
-			return false, errors.New(responseMessage)
+		for _, issue := range issues {
+			if issue.ID == issueID {
+				return issue, args.rest(1), false, nil
+			}
 		}
+	}
 
-		p.postCommandResponse(extra, responseMessage)
-	default:
-		return true, fmt.Errorf("setting `%s` not recognized", args[0])
+	if len(listIDs) > 1 {
+		return nil, nil, true, fmt.Errorf("there is no open Todo `%s`", ref)
 	}
-	return false, nil
+	return nil, nil, true, fmt.Errorf("there is no Todo `%s` on the %s list", ref, listName)
 }
 
-func getAutocompleteData() *model.AutocompleteData {
-	todo := model.NewAutocompleteData("todo", "[command]", "Available commands: list, add, pop, send, settings, help")
+func (p *Plugin) runDoneCommand(args *commandArgs, extra *model.CommandArgs) (bool, error) {
+	todo, args, isUserError, err := p.findIssueFromArgs(args, extra.UserId, MyListKey, true)
+	if err != nil {
+		return isUserError, err
+	}
+	if args.len() > 0 {
+		return true, errors.New("too many arguments")
+	}
 
-	add := model.NewAutocompleteData("add", "[message]", "Adds a Todo")
-	add.AddTextArgument("E.g. be awesome", "[message]", "")
-	todo.AddCommand(add)
+	issue, foreignID, listToUpdate, err := p.listManager.CompleteIssue(extra.UserId, todo.ID)
+	if err != nil {
+		return false, err
+	}
 
-	list := model.NewAutocompleteData("list", "[name]", "Lists your Todo issues")
-	items := []model.AutocompleteListItem{{
-		HelpText: "Received Todos",
-		Hint:     "(optional)",
-		Item:     "in",
-	}, {
-		HelpText: "Sent Todos",
-		Hint:     "(optional)",
-		Item:     "out",
-	}}
-	list.AddStaticListArgument("Lists your Todo issues", false, items)
-	todo.AddCommand(list)
+	p.trackCompleteIssue(extra.UserId)
 
-	pop := model.NewAutocompleteData("pop", "", "Removes the Todo issue at the top of the list")
-	todo.AddCommand(pop)
+	p.notifyComplete(extra.UserId, issue, foreignID, listToUpdate)
 
-	send := model.NewAutocompleteData("send", "[user] [todo]", "Sends a Todo to a specified user")
-	send.AddTextArgument("Whom to send", "[@awesomePerson]", "")
+	responseMessage := fmt.Sprintf("Completed Todo: %s", issue.Message)
+	if next := p.repeatIssue(extra.UserId, issue, listToUpdate, foreignID); next != nil {
+		responseMessage += "\n\n" + p.nextOccurrenceText(extra.UserId, next)
+	}
+	p.postCommandResponse(extra, responseMessage)
+	return false, nil
+}
+
+func (p *Plugin) runRemoveCommand(args *commandArgs, extra *model.CommandArgs) (bool, error) {
+	return p.removeIssueFromCommand(args, extra, MyListKey, true, "Removed")
+}
+
+func (p *Plugin) runDeclineCommand(args *commandArgs, extra *model.CommandArgs) (bool, error) {
+	return p.removeIssueFromCommand(args, extra, InListKey, false, "Declined")
+}
+
+func (p *Plugin) removeIssueFromCommand(args *commandArgs, extra *model.CommandArgs, listID string, allowListName bool, verb string) (bool, error) {
+	todo, args, isUserError, err := p.findIssueFromArgs(args, extra.UserId, listID, allowListName)
+	if err != nil {
+		return isUserError, err
+	}
+	if args.len() > 0 {
+		return true, errors.New("too many arguments")
+	}
+
+	issue, foreignID, isSender, listToUpdate, err := p.listManager.RemoveIssue(extra.UserId, todo.ID)
+	if err != nil {
+		return false, err
+	}
+
+	p.trackRemoveIssue(extra.UserId)
+
+	p.notifyRemove(extra.UserId, issue, foreignID, isSender, listToUpdate)
+
+	p.postCommandResponse(extra, fmt.Sprintf("%s Todo: %s", verb, issue.Message))
+	return false, nil
+}
+
+func (p *Plugin) runAcceptCommand(args *commandArgs, extra *model.CommandArgs) (bool, error) {
+	todo, args, isUserError, err := p.findIssueFromArgs(args, extra.UserId, InListKey, false)
+	if err != nil {
+		return isUserError, err
+	}
+	if args.len() > 0 {
+		return true, errors.New("too many arguments")
+	}
+
+	todoMessage, sender, err := p.listManager.AcceptIssue(extra.UserId, todo.ID)
+	if err != nil {
+		return false, err
+	}
+
+	p.trackAcceptIssue(extra.UserId)
+
+	p.notifyAccept(extra.UserId, todoMessage, sender)
+
+	p.postCommandResponse(extra, fmt.Sprintf("Accepted Todo: %s", todoMessage))
+	return false, nil
+}
+
+func (p *Plugin) runChecklistCommand(args *commandArgs, extra *model.CommandArgs) (bool, error) {
+	if args.len() < 1 {
+		return true, errors.New("you must specify a checklist action: add, check, remove, move or show")
+	}
+
+	action := args.values[0]
+	todo, args, isUserError, err := p.findIssueFromArgs(args.rest(1), extra.UserId, MyListKey, true)
+	if err != nil {
+		return isUserError, err
+	}
+
+	var update *ChecklistUpdate
+	switch action {
+	case "show":
+		if args.len() > 0 {
+			return true, errors.New("too many arguments")
+		}
+		p.postCommandResponse(extra, fmt.Sprintf("Checklist of the Todo: %s\n\n%s", issueSummary(&todo.Issue), checklistToString(&todo.Issue)))
+		return false, nil
+	case "add":
+		if args.len() == 0 {
+			return true, errors.New("you must specify the text of the item")
+		}
+		update = newChecklistAdd(args.text())
+	case "check", "remove":
+		if args.len() != 1 {
+			return true, errors.New("you must specify the item by its position on the checklist")
+		}
+		itemID, err := checklistItemIDFromArg(&todo.Issue, args.values[0])
+		if err != nil {
+			return true, err
+		}
+		update = &ChecklistUpdate{Action: ChecklistToggle, ItemID: itemID}
+		if action == "remove" {
+			update.Action = ChecklistRemove
+		}
+	case "move":
+		if args.len() != 2 {
+			return true, errors.New("you must specify the item and its new position on the checklist")
+		}
+		itemID, err := checklistItemIDFromArg(&todo.Issue, args.values[0])
+		if err != nil {
+			return true, err
+		}
+		position, err := strconv.Atoi(args.values[1])
+		if err != nil || position < 1 {
+			return true, fmt.Errorf("invalid position `%s`", args.values[1])
+		}
+		update = &ChecklistUpdate{Action: ChecklistMove, ItemID: itemID, Position: position - 1}
+	default:
+		return true, fmt.Errorf("invalid checklist action `%s`, allowed values are add, check, remove, move and show", action)
+	}
+
+	issue, foreignUserID, list, err := p.listManager.UpdateChecklist(extra.UserId, todo.ID, update)
+	if err != nil {
+		if errors.Is(err, ErrChecklistFull) {
+			return true, err
+		}
+		return false, err
+	}
+
+	p.notifyChecklist(extra.UserId, foreignUserID, list, issue, update)
+
+	p.postCommandResponse(extra, fmt.Sprintf("Updated the checklist of the Todo: %s\n\n%s", issueSummary(issue), checklistToString(issue)))
+	return false, nil
+}
+
+// checklistItemIDFromArg returns the ID of the checklist item of issue at the position given by arg
+func checklistItemIDFromArg(issue *Issue, arg string) (string, error) {
+	position, err := strconv.Atoi(arg)
+	if err != nil || position < 1 || position > len(issue.Checklist) {
+		return "", fmt.Errorf("there is no checklist item at position `%s`", arg)
+	}
+	return issue.Checklist[position-1].ID, nil
+}
+
+func (p *Plugin) runListsCommand(args *commandArgs, extra *model.CommandArgs) (bool, error) {
+	if args.len() == 0 {
+		lists, err := p.listManager.GetCustomLists(extra.UserId)
+		if err != nil {
+			return false, err
+		}
+		if len(lists) == 0 {
+			p.postCommandResponse(extra, "You have no lists yet. Create one with `/todo lists create [name]`.")
+			return false, nil
+		}
+
+		responseMessage := "Your lists:\n\n"
+		for i, list := range lists {
+			issues, err := p.listManager.GetIssueList(extra.UserId, list.ID)
+			if err != nil {
+				return false, err
+			}
+			responseMessage += fmt.Sprintf("%d. %s (%s)\n", i+1, list.Name, todoCountText(len(issues)))
+		}
+		p.postCommandResponse(extra, responseMessage)
+		return false, nil
+	}
+
+	action, args := args.values[0], args.rest(1)
+	var responseMessage string
+	switch action {
+	case "create":
+		list, err := p.listManager.CreateList(extra.UserId, args.text())
+		if err != nil {
+			return isCustomListUserError(err), err
+		}
+		responseMessage = fmt.Sprintf("Created the list %s. Move Todos to it with `/todo move [position] %s`.", list.Name, list.Name)
+	case "rename":
+		if args.len() != 2 {
+			return true, errors.New("you must specify the list and its new name, quote the names with spaces")
+		}
+		list, err := p.listManager.GetCustomList(extra.UserId, args.values[0])
+		if err != nil {
+			return isCustomListUserError(err), err
+		}
+		renamed, err := p.listManager.RenameList(extra.UserId, list.ID, args.values[1])
+		if err != nil {
+			return isCustomListUserError(err), err
+		}
+		p.sendRefreshEvent(extra.UserId, []string{list.ID})
+		responseMessage = fmt.Sprintf("Renamed the list %s to %s.", list.Name, renamed.Name)
+	case "delete":
+		list, err := p.listManager.GetCustomList(extra.UserId, args.text())
+		if err != nil {
+			return isCustomListUserError(err), err
+		}
+		_, moved, err := p.listManager.DeleteList(extra.UserId, list.ID)
+		if err != nil {
+			return isCustomListUserError(err), err
+		}
+		p.sendRefreshEvent(extra.UserId, []string{MyListKey, list.ID})
+		responseMessage = fmt.Sprintf("Deleted the list %s.", list.Name)
+		if moved > 0 {
+			responseMessage += fmt.Sprintf(" Its %s moved to your own list.", todoCountText(moved))
+		}
+	case "move":
+		if args.len() != 2 {
+			return true, errors.New("you must specify the list and its new position, quote the names with spaces")
+		}
+		list, err := p.listManager.GetCustomList(extra.UserId, args.values[0])
+		if err != nil {
+			return isCustomListUserError(err), err
+		}
+		position, err := strconv.Atoi(args.values[1])
+		if err != nil || position < 1 {
+			return true, fmt.Errorf("invalid position `%s`", args.values[1])
+		}
+		if err = p.listManager.MoveList(extra.UserId, list.ID, position-1); err != nil {
+			return isCustomListUserError(err), err
+		}
+		p.sendRefreshEvent(extra.UserId, []string{list.ID})
+		responseMessage = fmt.Sprintf("Moved the list %s to position %d.", list.Name, position)
+	default:
+		return true, fmt.Errorf("invalid lists action `%s`, allowed values are create, rename, delete and move", action)
+	}
+
+	p.trackCustomList(extra.UserId, action)
+
+	p.postCommandResponse(extra, responseMessage)
+	return false, nil
+}
+
+// isCustomListUserError returns whether err is caused by the custom list given by the user
+func isCustomListUserError(err error) bool {
+	return errors.Is(err, ErrListNotFound) || errors.Is(err, ErrListExists) || errors.Is(err, ErrInvalidListName) || errors.Is(err, ErrTooManyLists)
+}
+
+func todoCountText(count int) string {
+	if count == 1 {
+		return "1 Todo"
+	}
+	return fmt.Sprintf("%d Todos", count)
+}
+
+func (p *Plugin) runChannelCommand(args *commandArgs, extra *model.CommandArgs) (bool, error) {
+	if err := p.checkChannelMember(extra.ChannelId, extra.UserId); err != nil {
+		return errors.Is(err, ErrNotChannelMember), err
+	}
+
+	action := "list"
+	if args.len() > 0 {
+		action, args = args.values[0], args.rest(1)
+	}
+	if action != "add" && len(args.flags) > 0 {
+		return true, errors.New("flags are only supported by `channel add`")
+	}
+
+	var responseMessage string
+	switch action {
+	case "list":
+		listID := ChannelListKey
+		responseMessage = "Channel Todo list:\n\n"
+		switch args.text() {
+		case "":
+		case DoneFlag:
+			listID = ChannelDoneListKey
+			responseMessage = "Completed channel Todo list:\n\n"
+		default:
+			return true, errors.New("the channel only has its own list and the done list")
+		}
+
+		issues, err := p.listManager.GetIssueList(extra.ChannelId, listID)
+		if err != nil {
+			return false, err
+		}
+		responseMessage += issuesListToString(issues, p.getUserLocation(extra.UserId))
+	case "add":
+		issue, err := newIssueFromArgs(args, p.getUserLocation(extra.UserId))
+		if err != nil {
+			return true, err
+		}
+		if issue.Message == "" {
+			p.postCommandResponse(extra, "Please add a task.")
+			return false, nil
+		}
+
+		if err = p.listManager.AddChannelIssue(extra.ChannelId, issue); err != nil {
+			return false, err
+		}
+		responseMessage = fmt.Sprintf("Added Todo to the channel list: %s", issue.Message)
+	case "claim", "done", "remove":
+		issueID, isUserError, err := p.channelIssueIDFromArgs(extra.ChannelId, args)
+		if err != nil {
+			return isUserError, err
+		}
+
+		var issue *Issue
+		switch action {
+		case "claim":
+			issue, err = p.listManager.ClaimChannelIssue(extra.ChannelId, extra.UserId, issueID)
+			responseMessage = "Claimed Todo: %s"
+		case "done":
+			issue, err = p.listManager.CompleteChannelIssue(extra.ChannelId, extra.UserId, issueID)
+			responseMessage = "Completed Todo: %s"
+		default:
+			issue, err = p.listManager.RemoveChannelIssue(extra.ChannelId, issueID)
+			responseMessage = "Removed Todo: %s"
+		}
+		if err != nil {
+			return errors.Is(err, ErrIssueNotFound), err
+		}
+		responseMessage = fmt.Sprintf(responseMessage, issue.Message)
+	default:
+		return true, fmt.Errorf("invalid channel action `%s`, allowed values are list, add, claim, done and remove", action)
+	}
+
+	p.trackChannelList(extra.UserId, sourceCommand, action)
+
+	if action != "list" {
+		p.sendChannelRefreshEvent(extra.ChannelId)
+	}
+
+	p.postCommandResponse(extra, responseMessage)
+	return false, nil
+}
+
+// channelIssueIDFromArgs returns the ID of the open Todo of the list of channelID given by args, by
+// its position on the list or its #id
+func (p *Plugin) channelIssueIDFromArgs(channelID string, args *commandArgs) (string, bool, error) {
+	if args.len() != 1 {
+		return "", true, errors.New("you must specify the position or #id of a Todo of the channel list")
+	}
+	ref := args.values[0]
+
+	if strings.HasPrefix(ref, "#") {
+		issueID, err := p.listManager.ResolveIssueID(channelID, ref)
+		if errors.Is(err, ErrIssueNotFound) {
+			return "", true, fmt.Errorf("there is no Todo `%s` on the channel list", ref)
+		}
+		return issueID, false, err
+	}
+
+	issues, err := p.listManager.GetIssueList(channelID, ChannelListKey)
+	if err != nil {
+		return "", false, err
+	}
+	position, err := strconv.Atoi(ref)
+	if err != nil || position < 1 || position > len(issues) {
+		return "", true, fmt.Errorf("there is no Todo `%s` on the channel list", ref)
+	}
+	return issues[position-1].ID, false, nil
+}
+
+func (p *Plugin) runMoveCommand(args *commandArgs, extra *model.CommandArgs) (bool, error) {
+	todo, args, isUserError, err := p.findIssueFromArgs(args, extra.UserId, MyListKey, true)
+	if err != nil {
+		return isUserError, err
+	}
+	if args.len() == 0 {
+		return true, errors.New("you must specify the list or the position to move the Todo to")
+	}
+
+	// List names cannot be numbers, so a number is a position on the list of the Todo
+	if position, err := strconv.Atoi(args.text()); err == nil {
+		if position < 1 {
+			return true, fmt.Errorf("invalid position `%d`", position)
+		}
+
+		listID, err := p.listManager.MoveIssue(extra.UserId, todo.ID, position-1)
+		if err != nil {
+			if errors.Is(err, ErrIssueNotFound) {
+				return true, err
+			}
+			return false, err
+		}
+
+		p.trackMoveIssue(extra.UserId)
+
+		p.sendRefreshEvent(extra.UserId, []string{listID})
+
+		p.postCommandResponse(extra, fmt.Sprintf("Moved Todo to position %d: %s", position, todo.Message))
+		return false, nil
+	}
+
+	name := args.text()
+	listID, ok, err := p.listIDFromName(extra.UserId, name)
+	if err != nil {
+		return false, err
+	}
+	if !ok || (listID != MyListKey && !isCustomListKey(listID)) {
+		return true, fmt.Errorf("there is no list `%s` to move the Todo to, run `/todo lists` to see yours", name)
+	}
+
+	fromListID, err := p.listManager.MoveIssueToList(extra.UserId, todo.ID, listID)
+	if err != nil {
+		if errors.Is(err, ErrCannotMove) || isCustomListUserError(err) {
+			return true, err
+		}
+		return false, err
+	}
+
+	p.trackMoveIssue(extra.UserId)
+
+	p.sendRefreshEvent(extra.UserId, []string{fromListID, listID})
+
+	p.postCommandResponse(extra, fmt.Sprintf("Moved Todo to the %s list: %s", name, todo.Message))
+	return false, nil
+}
+
+func (p *Plugin) runSnoozeCommand(args *commandArgs, extra *model.CommandArgs) (bool, error) {
+	todo, args, isUserError, err := p.findIssueFromArgs(args, extra.UserId, MyListKey, true)
+	if err != nil {
+		return isUserError, err
+	}
+	if args.len() == 0 {
+		return true, errors.New("you must specify until when to snooze the Todo")
+	}
+
+	loc := p.getUserLocation(extra.UserId)
+	until, err := parseSnoozeTime(args.text(), loc)
+	if err != nil {
+		return true, err
+	}
+
+	issue, err := p.listManager.SnoozeIssue(extra.UserId, todo.ID, until)
+	if err != nil {
+		if errors.Is(err, ErrCannotSnooze) {
+			return true, err
+		}
+		return false, err
+	}
+
+	p.trackSnoozeIssue(extra.UserId)
+
+	p.sendRefreshEvent(extra.UserId, []string{MyListKey, InListKey})
+
+	p.postCommandResponse(extra, fmt.Sprintf("Snoozed Todo until %s: %s", formatSnoozeTime(until, loc), issue.Message))
+	return false, nil
+}
+
+func (p *Plugin) runUnsnoozeCommand(args *commandArgs, extra *model.CommandArgs) (bool, error) {
+	todo, args, isUserError, err := p.findIssueFromArgs(args, extra.UserId, MyListKey, true)
+	if err != nil {
+		return isUserError, err
+	}
+	if args.len() > 0 {
+		return true, errors.New("too many arguments")
+	}
+	if todo.SnoozedUntil == 0 {
+		return true, fmt.Errorf("the Todo `%s` is not snoozed", todo.Message)
+	}
+
+	issue, listID, err := p.listManager.WakeIssue(extra.UserId, todo.ID)
+	if err != nil {
+		return false, err
+	}
+
+	p.sendRefreshEvent(extra.UserId, []string{listID})
+
+	p.postCommandResponse(extra, fmt.Sprintf("Unsnoozed Todo: %s", issue.Message))
+	return false, nil
+}
+
+func (p *Plugin) runBumpCommand(args *commandArgs, extra *model.CommandArgs) (bool, error) {
+	todo, args, isUserError, err := p.findIssueFromArgs(args, extra.UserId, OutListKey, false)
+	if err != nil {
+		return isUserError, err
+	}
+	if args.len() > 0 {
+		return true, errors.New("too many arguments")
+	}
+
+	receiverIssue, receiver, receiverIssueID, err := p.listManager.BumpIssue(extra.UserId, todo.ID)
+	if err != nil {
+		return errors.Is(err, ErrSentToGroup), err
+	}
+
+	p.trackBumpIssue(extra.UserId)
+
+	p.notifyBump(extra.UserId, receiverIssue, receiver, receiverIssueID)
+
+	p.postCommandResponse(extra, fmt.Sprintf("Bumped Todo: %s", todo.Message))
+	return false, nil
+}
+
+func (p *Plugin) runEditCommand(args *commandArgs, extra *model.CommandArgs) (bool, error) {
+	todo, args, isUserError, err := p.findIssueFromArgs(args, extra.UserId, MyListKey, true)
+	if err != nil {
+		return isUserError, err
+	}
+
+	if args.len() == 0 && !args.hasFlag(flagDesc) && !args.hasFlag(flagDue) && !args.hasFlag(flagPriority) && !args.hasFlag(flagRepeat) && !args.hasFlag(flagTag) {
+		return true, errors.New("you must specify the new message of the Todo, or the flags to change")
+	}
+
+	update, err := issueUpdateFromArgs(args, &todo.Issue, p.getUserLocation(extra.UserId))
+	if err != nil {
+		return true, err
+	}
+
+	foreignUserID, list, oldMessage, err := p.listManager.EditIssue(extra.UserId, todo.ID, update)
+	if err != nil {
+		return false, err
+	}
+
+	p.trackEditIssue(extra.UserId)
+
+	p.notifyEdit(extra.UserId, foreignUserID, list, oldMessage, update.Message)
+
+	p.postCommandResponse(extra, fmt.Sprintf("Edited Todo: %s", update.Message))
+	return false, nil
+}
+
+func (p *Plugin) runAssignCommand(args *commandArgs, extra *model.CommandArgs) (bool, error) {
+	todo, args, isUserError, err := p.findIssueFromArgs(args, extra.UserId, MyListKey, true)
+	if err != nil {
+		return isUserError, err
+	}
+	if args.len() != 1 {
+		return true, errors.New("you must specify the user to assign the Todo to")
+	}
+
+	userName := strings.TrimPrefix(args.values[0], "@")
+	receiver, appErr := p.API.GetUserByUsername(userName)
+	if appErr != nil {
+		return true, fmt.Errorf("cannot find user @%s", userName)
+	}
+
+	issue, oldOwner, err := p.listManager.ChangeAssignment(todo.ID, extra.UserId, receiver.Id)
+	if err != nil {
+		return errors.Is(err, ErrSentToGroup), err
+	}
+
+	p.trackChangeAssignment(extra.UserId)
+
+	p.notifyChangeAssignment(extra.UserId, receiver.Id, issue, todo.ID, oldOwner)
+
+	p.postCommandResponse(extra, fmt.Sprintf("Assigned Todo to @%s: %s", userName, issue.Message))
+	return false, nil
+}
+
+func (p *Plugin) runAdminCommand(args *commandArgs, extra *model.CommandArgs) (bool, error) {
+	if !p.API.HasPermissionTo(extra.UserId, model.PermissionManageSystem) {
+		return true, errors.New("only system administrators can run admin commands")
+	}
+
+	if args.len() < 1 || args.values[0] != "fsck" {
+		p.postCommandResponse(extra, getAdminHelp())
+		return false, nil
+	}
+
+	if args.len() > 2 {
+		return true, errors.New("too many arguments")
+	}
+
+	userID := ""
+	if args.len() == 2 {
+		user, appErr := p.API.GetUserByUsername(strings.TrimPrefix(args.values[1], "@"))
+		if appErr != nil {
+			return true, fmt.Errorf("user `%s` not found", args.values[1])
+		}
+		userID = user.Id
+	}
+
+	fix := args.hasFlag(flagFix)
+	report, err := p.listManager.Fsck(userID, fix)
+	if err != nil {
+		return false, err
+	}
+
+	p.postCommandResponse(extra, fsckReportToString(report, p.listManager.GetUserName))
+	return false, nil
+}
+
+// runSummaryScheduleCommand updates one field of the reminder schedule, from the "field value" args
+func (p *Plugin) runSummaryScheduleCommand(args *commandArgs, extra *model.CommandArgs) (bool, error) {
+	if args.len() > 2 {
+		return true, errors.New("too many arguments")
+	}
+
+	schedule, err := p.getReminderSchedule(extra.UserId)
+	if err != nil {
+		return false, err
+	}
+
+	switch args.values[0] {
+	case "hour":
+		if schedule.Hour, err = parseReminderHour(args.values[1]); err != nil {
+			return true, err
+		}
+	case "days":
+		if schedule.Weekdays, err = parseWeekdays(args.values[1]); err != nil {
+			return true, err
+		}
+	case "frequency":
+		schedule.Frequency = args.values[1]
+		if schedule.Frequency != ReminderDaily && schedule.Frequency != ReminderWeekly {
+			return true, fmt.Errorf("invalid frequency `%s`, allowed values are `%s` or `%s`", args.values[1], ReminderDaily, ReminderWeekly)
+		}
+	}
+
+	if err = p.saveReminderSchedule(extra.UserId, schedule); err != nil {
+		p.API.LogDebug("runSettingsCommand: error saving the reminder schedule", "error", err.Error())
+		return false, errors.New("error saving the reminder schedule")
+	}
+
+	p.postCommandResponse(extra, fmt.Sprintf("You will receive reminders %s.", schedule.String()))
+	return false, nil
+}
+
+func (p *Plugin) getTimezoneSettingMessage(userID string) string {
+	timezone, err := p.getTimezonePreference(userID)
+	if err != nil {
+		p.API.LogError("Error when getting timezone preference, err=", err.Error())
+	}
+	return getTimezoneSetting(timezone, p.getUserLocation(userID))
+}
+
+func (p *Plugin) runSettingsCommand(args *commandArgs, extra *model.CommandArgs) (bool, error) {
+	const (
+		on  = "on"
+		off = "off"
+	)
+	if args.len() < 1 {
+		currentSummarySetting := p.getReminderPreference(extra.UserId)
+		currentAllowIncomingTaskRequestsSetting, err := p.getAllowIncomingTaskRequestsPreference(extra.UserId)
+		if err != nil {
+			p.API.LogError("Error when getting allow incoming task request preference, err=", err)
+			currentAllowIncomingTaskRequestsSetting = true
+		}
+		schedule, err := p.getReminderSchedule(extra.UserId)
+		if err != nil {
+			return false, err
+		}
+		p.postCommandResponse(extra, getAllSettings(currentSummarySetting, schedule, currentAllowIncomingTaskRequestsSetting, p.getTimezoneSettingMessage(extra.UserId)))
+		return false, nil
+	}
+
+	switch args.values[0] {
+	case "summary":
+		if args.len() < 2 {
+			currentSummarySetting := p.getReminderPreference(extra.UserId)
+			schedule, err := p.getReminderSchedule(extra.UserId)
+			if err != nil {
+				return false, err
+			}
+			p.postCommandResponse(extra, getSummarySetting(currentSummarySetting, schedule))
+			return false, nil
+		}
+		if args.len() > 2 {
+			if args.values[1] == "hour" || args.values[1] == "days" || args.values[1] == "frequency" {
+				return p.runSummaryScheduleCommand(args.rest(1), extra)
+			}
+			return true, errors.New("too many arguments")
+		}
+		var responseMessage string
+		var err error
+
+		switch args.values[1] {
+		case on:
+			err = p.saveReminderPreference(extra.UserId, true)
+			responseMessage = "You will start receiving daily summaries."
+		case off:
+			err = p.saveReminderPreference(extra.UserId, false)
+			responseMessage = "You will stop receiving daily summaries."
+		default:
+			responseMessage = "invalid input, allowed values for \"settings summary\" are `on`, `off`, `hour`, `days` or `frequency`"
+			return true, errors.New(responseMessage)
+		}
+
+		if err != nil {
+			responseMessage = "error saving the reminder preference"
+			p.API.LogDebug("runSettingsCommand: error saving the reminder preference", "error", err.Error())
+			return false, errors.New(responseMessage)
+		}
+
+		p.postCommandResponse(extra, responseMessage)
+
+	case "allow_incoming_task_requests":
+		if args.len() < 2 {
+			currentAllowIncomingTaskRequestsSetting, err := p.getAllowIncomingTaskRequestsPreference(extra.UserId)
+			if err != nil {
+				p.API.LogError("unable to parse the allow incoming task requests preference, err=", err.Error())
+				currentAllowIncomingTaskRequestsSetting = true
+			}
+			p.postCommandResponse(extra, getAllowIncomingTaskRequestsSetting(currentAllowIncomingTaskRequestsSetting))
+			return false, nil
+		}
+		if args.len() > 2 {
+			return true, errors.New("too many arguments")
+		}
+		var responseMessage string
+		var err error
+
+		switch args.values[1] {
+		case on:
+			err = p.saveAllowIncomingTaskRequestsPreference(extra.UserId, true)
+			responseMessage = "Other users can send task for you to accept/decline"
+		case off:
+			err = p.saveAllowIncomingTaskRequestsPreference(extra.UserId, false)
+			responseMessage = "Other users cannot send you task request. They will see a message saying you have blocked incoming task requests"
+		default:
+			responseMessage = "invalid input, allowed values for \"settings allow_incoming_task_requests\" are `on` or `off`"
+			return true, errors.New(responseMessage)
+		}
+
+		if err != nil {
+			responseMessage = "error saving the block_incoming preference"
+			p.API.LogDebug("runSettingsCommand: error saving the block_incoming preference", "error", err.Error())
+			return false, errors.New(responseMessage)
+		}
+
+		p.postCommandResponse(extra, responseMessage)
+
+	case "timezone":
+		if args.len() < 2 {
+			p.postCommandResponse(extra, p.getTimezoneSettingMessage(extra.UserId))
+			return false, nil
+		}
+		if args.len() > 2 {
+			return true, errors.New("too many arguments")
+		}
+
+		timezone := args.values[1]
+		responseMessage := fmt.Sprintf("Your reminders and due dates will use the `%s` timezone.", timezone)
+		if timezone == "auto" {
+			timezone = ""
+			responseMessage = "Your reminders and due dates will use your profile timezone."
+		} else if _, err := time.LoadLocation(timezone); err != nil || timezone == "Local" {
+			return true, fmt.Errorf("unknown timezone `%s`, use an IANA name like `America/New_York`", timezone)
+		}
+
+		if err := p.saveTimezonePreference(extra.UserId, timezone); err != nil {
+			p.API.LogDebug("runSettingsCommand: error saving the timezone preference", "error", err.Error())
+			return false, errors.New("error saving the timezone preference")
+		}
+
+		p.postCommandResponse(extra, responseMessage)
+	default:
+		return true, fmt.Errorf("setting `%s` not recognized", args.values[0])
+	}
+	return false, nil
+}
+
+// newIssueFromArgs creates a new issue from the command arguments, using the values as the message
+// and the flags for the rest of the fields
+func newIssueFromArgs(args *commandArgs, loc *time.Location) (*Issue, error) {
+	issue := newIssue("", "", "", "")
+
+	update, err := issueUpdateFromArgs(args, issue, loc)
+	if err != nil {
+		return nil, err
+	}
+	update.apply(issue)
+
+	return issue, nil
+}
+
+// issueUpdateFromArgs returns the update of issue from the command arguments. The values replace
+// the message, if any, and the flags the rest of the fields.
+func issueUpdateFromArgs(args *commandArgs, issue *Issue, loc *time.Location) (*IssueUpdate, error) {
+	update := &IssueUpdate{
+		Message:     issue.Message,
+		Description: issue.Description,
+	}
+
+	if args.len() > 0 {
+		update.Message = args.text()
+	}
+
+	if args.hasFlag(flagDesc) {
+		update.Description = args.flag(flagDesc)
+	}
+
+	if args.hasFlag(flagPriority) {
+		priority := args.flag(flagPriority)
+		if !isValidPriority(priority) {
+			return nil, fmt.Errorf("invalid priority `%s`, allowed values are low, normal, high and urgent", priority)
+		}
+		update.Priority = &priority
+	}
+
+	if args.hasFlag(flagDue) {
+		dueAt, err := parseDueDate(args.flag(flagDue), loc)
+		if err != nil {
+			return nil, err
+		}
+		update.DueAt = &dueAt
+	}
+
+	if args.hasFlag(flagRepeat) {
+		dueAt := issue.DueAt
+		if update.DueAt != nil {
+			dueAt = *update.DueAt
+		}
+		recurrence, err := normalizeRecurrence(args.flag(flagRepeat), dueAt, loc)
+		if err != nil {
+			return nil, err
+		}
+		update.Recurrence = &recurrence
+	}
+
+	if args.hasFlag(flagTag) {
+		labels, err := parseLabels(args.flag(flagTag))
+		if err != nil {
+			return nil, err
+		}
+		update.Labels = &labels
+	}
+
+	return update, nil
+}
+
+// parseDueDate parses a due date on the given location, either written as YYYY-MM-DD, YYYY-MM-DDTHH:MM
+// or in natural language such as "tomorrow" or "next friday 3pm". Dates without time are due at the end
+// of the day, and dueDateNone removes the due date.
+func parseDueDate(value string, loc *time.Location) (int64, error) {
+	if value == dueDateNone {
+		return 0, nil
+	}
+
+	t, err := dateparse.Parse(value, time.Now().In(loc))
+	if err != nil {
+		return 0, fmt.Errorf("invalid due date `%s`, use a date like tomorrow, next friday 3pm, in 2 days or 2025-01-31", value)
+	}
+
+	return t.UnixMilli(), nil
+}
+
+func getPriorityItems() []model.AutocompleteListItem {
+	return []model.AutocompleteListItem{
+		{Item: PriorityLow},
+		{Item: PriorityNormal},
+		{Item: PriorityHigh},
+		{Item: PriorityUrgent},
+	}
+}
+
+func getAutocompleteData() *model.AutocompleteData {
+	todo := model.NewAutocompleteData("todo", "[command]", "Available commands: list, lists, add, pop, send, move, done, remove, edit, accept, decline, bump, assign, checklist, snooze, unsnooze, undo, settings, help")
+
+	add := model.NewAutocompleteData("add", "[message]", "Adds a Todo")
+	add.AddNamedTextArgument("due", "Due date, like tomorrow, next friday 3pm, in 2 days or 2025-01-31", "[date]", "", false)
+	add.AddNamedStaticListArgument("priority", "Priority of the Todo", false, getPriorityItems())
+	add.AddNamedTextArgument("desc", "Description of the Todo", "[description]", "", false)
+	add.AddNamedTextArgument("repeat", "Repetition, like daily, weekdays, weekly on mon,fri or monthly", "[rule]", "", false)
+	add.AddNamedTextArgument("tag", "Comma separated tags, like release,oncall", "[tags]", "", false)
+	add.AddTextArgument("E.g. be awesome", "[message]", "")
+	todo.AddCommand(add)
+
+	list := model.NewAutocompleteData("list", "[name]", "Lists your Todo issues")
+	items := []model.AutocompleteListItem{{
+		HelpText: "Received Todos",
+		Hint:     "(optional)",
+		Item:     "in",
+	}, {
+		HelpText: "Sent Todos",
+		Hint:     "(optional)",
+		Item:     "out",
+	}, {
+		HelpText: "Completed Todos",
+		Hint:     "(optional)",
+		Item:     "done",
+	}}
+	list.AddStaticListArgument("Lists your Todo issues", false, items)
+	list.AddNamedStaticListArgument("sort", "Sort order of the list", false, []model.AutocompleteListItem{{
+		HelpText: "Sort by priority",
+		Item:     sortByPriority,
+	}})
+	list.AddNamedTextArgument("tag", "Only list the Todos with this tag", "[tag]", "", false)
+	todo.AddCommand(list)
+
+	pop := model.NewAutocompleteData("pop", "[priority]", "Removes the Todo issue at the top of the list")
+	pop.AddStaticListArgument("Which Todo to remove", false, []model.AutocompleteListItem{{
+		HelpText: "Remove the first Todo with the highest priority",
+		Hint:     "(optional)",
+		Item:     sortByPriority,
+	}})
+	todo.AddCommand(pop)
+
+	send := model.NewAutocompleteData("send", "[users] [todo]", "Sends a Todo to the specified users")
+	send.AddTextArgument("Whom to send, as @user, @group or ~channel", "[@awesomePerson]", "")
+	send.AddNamedTextArgument("due", "Due date, like tomorrow, next friday 3pm, in 2 days or 2025-01-31", "[date]", "", false)
+	send.AddNamedStaticListArgument("priority", "Priority of the Todo", false, getPriorityItems())
+	send.AddNamedTextArgument("desc", "Description of the Todo", "[description]", "", false)
+	send.AddNamedTextArgument("repeat", "Repetition, like daily, weekdays, weekly on mon,fri or monthly", "[rule]", "", false)
+	send.AddNamedTextArgument("tag", "Comma separated tags, like release,oncall", "[tags]", "", false)
 	send.AddTextArgument("Todo message", "[message]", "")
 	todo.AddCommand(send)
 
+	done := model.NewAutocompleteData("done", "[list] [position]", "Completes a Todo")
+	done.AddDynamicListArgument("Todo to complete, by its position on the list or its #id", autocompleteIssuesURL(autocompleteOpenList), true)
+	todo.AddCommand(done)
+
+	remove := model.NewAutocompleteData("remove", "[list] [position]", "Removes a Todo")
+	remove.AddDynamicListArgument("Todo to remove, by its position on the list or its #id", autocompleteIssuesURL(autocompleteOpenList), true)
+	todo.AddCommand(remove)
+
+	edit := model.NewAutocompleteData("edit", "[list] [position] [message]", "Changes the message of a Todo")
+	edit.AddDynamicListArgument("Todo to edit, by its position on the list or its #id", autocompleteIssuesURL(autocompleteOpenList), true)
+	edit.AddNamedTextArgument("due", "New due date, like tomorrow, in 2 days or 2025-01-31, or none", "[date]", "", false)
+	edit.AddNamedStaticListArgument("priority", "New priority of the Todo", false, getPriorityItems())
+	edit.AddNamedTextArgument("desc", "New description of the Todo", "[description]", "", false)
+	edit.AddNamedTextArgument("repeat", "New repetition, like daily or weekly on mon,fri, or none", "[rule]", "", false)
+	edit.AddNamedTextArgument("tag", "New comma separated tags, or none", "[tags]", "", false)
+	edit.AddTextArgument("New message", "[message]", "")
+	todo.AddCommand(edit)
+
+	accept := model.NewAutocompleteData("accept", "[position]", "Accepts a received Todo")
+	accept.AddDynamicListArgument("Position of the Todo on your received list, or its #id", autocompleteIssuesURL(InFlag), true)
+	todo.AddCommand(accept)
+
+	decline := model.NewAutocompleteData("decline", "[position]", "Declines a received Todo")
+	decline.AddDynamicListArgument("Position of the Todo on your received list, or its #id", autocompleteIssuesURL(InFlag), true)
+	todo.AddCommand(decline)
+
+	bump := model.NewAutocompleteData("bump", "[position]", "Moves a sent Todo to the top of its receiver's list")
+	bump.AddDynamicListArgument("Position of the Todo on your sent list, or its #id", autocompleteIssuesURL(OutFlag), true)
+	todo.AddCommand(bump)
+
+	assign := model.NewAutocompleteData("assign", "[list] [position] [user]", "Sends a Todo to a specified user")
+	assign.AddDynamicListArgument("Todo to assign, by its position on the list or its #id", autocompleteIssuesURL(autocompleteOpenList), true)
+	assign.AddTextArgument("Whom to assign", "[@awesomePerson]", "")
+	todo.AddCommand(assign)
+
+	checklist := model.NewAutocompleteData("checklist", "[action]", "Changes the checklist of a Todo")
+	checklistAdd := model.NewAutocompleteData("add", "[position] [text]", "Adds an item to the checklist of a Todo")
+	checklistAdd.AddDynamicListArgument("Todo to add the item to, by its position on the list or its #id", autocompleteIssuesURL(autocompleteOpenList), true)
+	checklistAdd.AddTextArgument("Text of the item", "[text]", "")
+	checklistCheck := model.NewAutocompleteData("check", "[position] [item]", "Checks or unchecks an item of the checklist of a Todo")
+	checklistCheck.AddDynamicListArgument("Todo of the item, by its position on the list or its #id", autocompleteIssuesURL(autocompleteOpenList), true)
+	checklistCheck.AddTextArgument("Position of the item on the checklist", "[item]", "")
+	checklistRemove := model.NewAutocompleteData("remove", "[position] [item]", "Removes an item from the checklist of a Todo")
+	checklistRemove.AddDynamicListArgument("Todo of the item, by its position on the list or its #id", autocompleteIssuesURL(autocompleteOpenList), true)
+	checklistRemove.AddTextArgument("Position of the item on the checklist", "[item]", "")
+	checklistMove := model.NewAutocompleteData("move", "[position] [item] [new position]", "Moves an item of the checklist of a Todo")
+	checklistMove.AddDynamicListArgument("Todo of the item, by its position on the list or its #id", autocompleteIssuesURL(autocompleteOpenList), true)
+	checklistMove.AddTextArgument("Position of the item on the checklist, and its new position", "[item] [new position]", "")
+	checklistShow := model.NewAutocompleteData("show", "[position]", "Shows the checklist of a Todo")
+	checklistShow.AddDynamicListArgument("Todo to show, by its position on the list or its #id", autocompleteIssuesURL(autocompleteOpenList), true)
+	checklist.AddCommand(checklistAdd)
+	checklist.AddCommand(checklistCheck)
+	checklist.AddCommand(checklistRemove)
+	checklist.AddCommand(checklistMove)
+	checklist.AddCommand(checklistShow)
+	todo.AddCommand(checklist)
+
+	lists := model.NewAutocompleteData("lists", "[action]", "Manages the lists you created")
+	listsCreate := model.NewAutocompleteData("create", "[name]", "Creates a new list")
+	listsCreate.AddTextArgument("Name of the list", "[name]", "")
+	listsRename := model.NewAutocompleteData("rename", "[name] [new name]", "Renames a list")
+	listsRename.AddTextArgument("Name of the list and its new name, quoted if they have spaces", "[name] [new name]", "")
+	listsDelete := model.NewAutocompleteData("delete", "[name]", "Deletes a list, moving its Todos to your own list")
+	listsDelete.AddTextArgument("Name of the list", "[name]", "")
+	listsMove := model.NewAutocompleteData("move", "[name] [position]", "Moves a list to another position")
+	listsMove.AddTextArgument("Name of the list and its new position", "[name] [position]", "")
+	lists.AddCommand(listsCreate)
+	lists.AddCommand(listsRename)
+	lists.AddCommand(listsDelete)
+	lists.AddCommand(listsMove)
+	todo.AddCommand(lists)
+
+	channel := model.NewAutocompleteData("channel", "[action]", "Manages the Todo list shared by the members of the current channel")
+	channelList := model.NewAutocompleteData("list", "[done]", "Lists the Todos of the channel")
+	channelList.AddStaticListArgument("List", false, []model.AutocompleteListItem{
+		{Item: DoneFlag, HelpText: "Completed Todos"},
+	})
+	channelAdd := model.NewAutocompleteData("add", "[message]", "Adds a Todo to the channel list")
+	channelAdd.AddTextArgument("Message of the Todo", "[message]", "")
+	channel.AddCommand(channelList)
+	channel.AddCommand(channelAdd)
+	channelClaim := model.NewAutocompleteData("claim", "[position]", "Tells the channel you are taking care of a Todo")
+	channelClaim.AddDynamicListArgument("Todo to claim, by its position on the channel list or its #id", autocompleteIssuesURL(autocompleteChannelList), true)
+	channelDone := model.NewAutocompleteData("done", "[position]", "Completes a Todo of the channel list")
+	channelDone.AddDynamicListArgument("Todo to complete, by its position on the channel list or its #id", autocompleteIssuesURL(autocompleteChannelList), true)
+	channelRemove := model.NewAutocompleteData("remove", "[position]", "Removes a Todo from the channel list")
+	channelRemove.AddDynamicListArgument("Todo to remove, by its position on the channel list or its #id", autocompleteIssuesURL(autocompleteChannelList), true)
+	channel.AddCommand(channelClaim)
+	channel.AddCommand(channelDone)
+	channel.AddCommand(channelRemove)
+	todo.AddCommand(channel)
+
+	move := model.NewAutocompleteData("move", "[position] [list or new position]", "Moves a Todo to another of your lists or to another position")
+	move.AddDynamicListArgument("Todo to move, by its position on the list or its #id", autocompleteIssuesURL(autocompleteOpenList), true)
+	move.AddTextArgument("List to move the Todo to, my or the name of one of your lists, or its new position", "[list or new position]", "")
+	todo.AddCommand(move)
+
+	snooze := model.NewAutocompleteData("snooze", "[position] [time]", "Hides a Todo until the given time")
+	snooze.AddDynamicListArgument("Todo to snooze, by its position on the list or its #id", autocompleteIssuesURL(autocompleteOpenList), true)
+	snooze.AddTextArgument("When the Todo comes back, like tomorrow, monday 9am or in 2 hours", "[time]", "")
+	todo.AddCommand(snooze)
+
+	unsnooze := model.NewAutocompleteData("unsnooze", "[position]", "Moves a snoozed Todo back to the top of its list")
+	unsnooze.AddDynamicListArgument("Todo to unsnooze, by its position on the list or its #id", autocompleteIssuesURL(autocompleteSnoozedList), true)
+	todo.AddCommand(unsnooze)
+
+	undo := model.NewAutocompleteData("undo", "", "Restores the last Todo you completed, removed or popped")
+	todo.AddCommand(undo)
+
 	settings := model.NewAutocompleteData("settings", "[setting] [on] [off]", "Sets the user settings")
-	summary := model.NewAutocompleteData("summary", "[on] [off]", "Sets the summary settings")
+	summary := model.NewAutocompleteData("summary", "[on] [off] [hour] [days] [frequency]", "Sets the summary settings")
 	summaryOn := model.NewAutocompleteData("on", "", "sets the daily reminder to enable")
 	summaryOff := model.NewAutocompleteData("off", "", "sets the daily reminder to disable")
+	summaryHour := model.NewAutocompleteData("hour", "[0-23]", "sets the hour of the day after which the reminder is sent")
+	summaryHour.AddTextArgument("Hour of the day", "[9]", "")
+	summaryDays := model.NewAutocompleteData("days", "[days]", "sets the days of the week the reminder is sent on")
+	summaryDays.AddStaticListArgument("Days of the week", true, []model.AutocompleteListItem{
+		{Item: "all", HelpText: "Every day"},
+		{Item: "weekdays", HelpText: "Monday to Friday"},
+		{Item: "sun,mon,tue,wed,thu", HelpText: "Sunday to Thursday"},
+	})
+	summaryFrequency := model.NewAutocompleteData("frequency", "[daily] [weekly]", "sets how often the reminder is sent")
+	summaryFrequency.AddStaticListArgument("Frequency", true, []model.AutocompleteListItem{
+		{Item: ReminderDaily, HelpText: "Every day"},
+		{Item: ReminderWeekly, HelpText: "Once a week"},
+	})
 	summary.AddCommand(summaryOn)
 	summary.AddCommand(summaryOff)
+	summary.AddCommand(summaryHour)
+	summary.AddCommand(summaryDays)
+	summary.AddCommand(summaryFrequency)
 
 	allowIncomingTask := model.NewAutocompleteData("allow_incoming_task_requests", "[on] [off]", "Allow other Mattermost users to send a task for you to accept/decline?")
 	allowIncomingTaskOn := model.NewAutocompleteData("on", "", "Allow others to send you a Task, you can accept/decline")
@@ -459,10 +1858,21 @@ func getAutocompleteData() *model.AutocompleteData {
 	allowIncomingTask.AddCommand(allowIncomingTaskOn)
 	allowIncomingTask.AddCommand(allowIncomingTaskOff)
 
+	timezone := model.NewAutocompleteData("timezone", "[timezone] [auto]", "Sets the timezone of your reminders and due dates")
+	timezone.AddTextArgument("IANA timezone, or auto to use your profile timezone", "[Europe/Madrid]", "")
+
 	settings.AddCommand(summary)
 	settings.AddCommand(allowIncomingTask)
+	settings.AddCommand(timezone)
 	todo.AddCommand(settings)
 
+	admin := model.NewAutocompleteData("admin", "[command]", "Runs admin commands")
+	admin.RoleID = model.SystemAdminRoleId
+	fsck := model.NewAutocompleteData("fsck", "[user] [--fix]", "Checks the Todo lists for broken references")
+	fsck.AddTextArgument("User to check, all users if empty", "[@awesomePerson]", "")
+	admin.AddCommand(fsck)
+	todo.AddCommand(admin)
+
 	help := model.NewAutocompleteData("help", "", "Display usage")
 	todo.AddCommand(help)
 	return todo
@@ -1,17 +1,26 @@
 package main
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSetttingsCommand(t *testing.T) {
 	api := &plugintest.API{}
 	api.On("SendEphemeralPost", mock.AnythingOfType("string"), mock.Anything).Return(nil)
 	api.On("KVSet", mock.AnythingOfType("string"), mock.Anything).Return(nil)
+	api.On("KVDelete", mock.AnythingOfType("string")).Return(nil)
+	api.On("KVGet", timezoneKey("")).Return([]byte("Europe/Madrid"), nil)
+	api.On("KVGet", reminderScheduleKey("")).Return(nil, nil)
+	api.On("KVGet", StoreReminderUsersKey).Return([]byte("[]"), nil)
+	api.On("KVCompareAndSet", StoreReminderUsersKey, []byte("[]"), mock.Anything).Return(true, nil)
 	api.On("KVGet", mock.AnythingOfType("string"), mock.Anything).Return([]byte("true"), nil)
 
 	apiKVSetFailed := &plugintest.API{}
@@ -103,6 +112,69 @@ func TestSetttingsCommand(t *testing.T) {
 			wantErr: true,
 			want:    true,
 		},
+		{
+			name:    "Setting summary hour successful",
+			api:     api,
+			args:    []string{"summary", "hour", "8"},
+			wantErr: false,
+			want:    false,
+		},
+		{
+			name:    "Setting summary hour as a time successful",
+			api:     api,
+			args:    []string{"summary", "hour", "9pm"},
+			wantErr: false,
+			want:    false,
+		},
+		{
+			name:    "Setting summary hour failed due to minutes",
+			api:     api,
+			args:    []string{"summary", "hour", "9:30am"},
+			wantErr: true,
+			want:    true,
+		},
+		{
+			name:    "Setting summary days successful",
+			api:     api,
+			args:    []string{"summary", "days", "sun,mon,tue,wed,thu"},
+			wantErr: false,
+			want:    false,
+		},
+		{
+			name:    "Setting summary frequency failed due to invalid argument",
+			api:     api,
+			args:    []string{"summary", "frequency", "hourly"},
+			wantErr: true,
+			want:    true,
+		},
+		{
+			name:    "Setting timezone successful",
+			api:     api,
+			args:    []string{"timezone", "America/New_York"},
+			wantErr: false,
+			want:    false,
+		},
+		{
+			name:    "Setting timezone to auto successful",
+			api:     api,
+			args:    []string{"timezone", "auto"},
+			wantErr: false,
+			want:    false,
+		},
+		{
+			name:    "Setting timezone successful due to no arguments",
+			api:     api,
+			args:    []string{"timezone"},
+			wantErr: false,
+			want:    false,
+		},
+		{
+			name:    "Setting timezone failed due to invalid argument",
+			api:     api,
+			args:    []string{"timezone", "Mars/Olympus_Mons"},
+			wantErr: true,
+			want:    true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -110,7 +182,7 @@ func TestSetttingsCommand(t *testing.T) {
 			plugin := Plugin{}
 			plugin.SetAPI(tt.api)
 
-			resp, err := plugin.runSettingsCommand(tt.args, &model.CommandArgs{})
+			resp, err := plugin.runSettingsCommand(mustParseCommandArgs(t, strings.Join(tt.args, " ")), &model.CommandArgs{})
 			if tt.wantErr != (err != nil) {
 				t.Errorf("runSettingsCommand wantErr= %v got err= %v", tt.wantErr, err)
 				return
@@ -122,3 +194,212 @@ func TestSetttingsCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestNewIssueFromArgs(t *testing.T) {
+	loc := time.UTC
+
+	tests := []struct {
+		name         string
+		command      string
+		wantMessage  string
+		wantDesc     string
+		wantDue      int64
+		wantPriority string
+		wantErr      bool
+	}{
+		{
+			name:        "No flags",
+			command:     "be awesome",
+			wantMessage: "be awesome",
+		},
+		{
+			name:        "Due date only",
+			command:     "--due 2025-01-31 be awesome",
+			wantMessage: "be awesome",
+			wantDue:     time.Date(2025, 1, 31, 23, 59, 0, 0, loc).UnixMilli(),
+		},
+		{
+			name:        "Due date and time at the end",
+			command:     "be awesome --due 2025-01-31T10:30",
+			wantMessage: "be awesome",
+			wantDue:     time.Date(2025, 1, 31, 10, 30, 0, 0, loc).UnixMilli(),
+		},
+		{
+			name:         "Priority and due date",
+			command:      "--priority urgent be awesome --due=2025-01-31",
+			wantMessage:  "be awesome",
+			wantDue:      time.Date(2025, 1, 31, 23, 59, 0, 0, loc).UnixMilli(),
+			wantPriority: PriorityUrgent,
+		},
+		{
+			name:        "Quoted message and description",
+			command:     "\"fix the build\" --desc \"it fails on\nthe CI\"",
+			wantMessage: "fix the build",
+			wantDesc:    "it fails on\nthe CI",
+		},
+		{
+			name:        "Multi-line message",
+			command:     "fix the build\n\nthen  release",
+			wantMessage: "fix the build\n\nthen release",
+		},
+		{
+			name:    "Invalid due date",
+			command: "--due someday be awesome",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid priority",
+			command: "--priority whenever be awesome",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issue, err := newIssueFromArgs(mustParseCommandArgs(t, tt.command), loc)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMessage, issue.Message)
+			assert.Equal(t, tt.wantDesc, issue.Description)
+			assert.Equal(t, tt.wantDue, issue.DueAt)
+			assert.Equal(t, tt.wantPriority, issue.Priority)
+		})
+	}
+}
+
+func TestFindIssueFromArgs(t *testing.T) {
+	userID := model.NewId()
+	store := newMemStore()
+	first := newIssue("first", "", "", "")
+	second := newIssue("second", "", "", "")
+	received := newIssue("received", "", "", "")
+	for _, issue := range []*Issue{first, second, received} {
+		store.issues[issue.ID] = issue
+	}
+	store.lists[listKey(userID, MyListKey)] = []*IssueRef{{IssueID: first.ID}, {IssueID: second.ID}}
+	store.lists[listKey(userID, InListKey)] = []*IssueRef{{IssueID: received.ID}}
+	received.ShortID = 7
+	store.shortIDIssues[shortIDIssueKey(userID, received.ShortID)] = received.ID
+	errand := newIssue("errand", "", "", "")
+	store.issues[errand.ID] = errand
+	errands := &CustomList{ID: newCustomListKey(), Name: "Errands"}
+	store.custom[userID] = []*CustomList{errands}
+	store.lists[listKey(userID, errands.ID)] = []*IssueRef{{IssueID: errand.ID}}
+
+	p := &Plugin{listManager: &listManager{store: store, api: &plugintest.API{}}}
+
+	tests := []struct {
+		name          string
+		args          []string
+		listID        string
+		allowListName bool
+		wantID        string
+		wantRest      []string
+		wantUserError bool
+	}{
+		{
+			name:     "Position on the default list",
+			args:     []string{"2", "new", "message"},
+			listID:   MyListKey,
+			wantID:   second.ID,
+			wantRest: []string{"new", "message"},
+		},
+		{
+			name:          "Position on a named list",
+			args:          []string{"in", "1"},
+			listID:        MyListKey,
+			allowListName: true,
+			wantID:        received.ID,
+			wantRest:      []string{},
+		},
+		{
+			name:     "List name not allowed",
+			args:     []string{"1"},
+			listID:   InListKey,
+			wantID:   received.ID,
+			wantRest: []string{},
+		},
+		{
+			name:     "Issue ID",
+			args:     []string{first.ID},
+			listID:   MyListKey,
+			wantID:   first.ID,
+			wantRest: []string{},
+		},
+		{
+			name:          "Position on a custom list",
+			args:          []string{"errands", "1", "soon"},
+			listID:        MyListKey,
+			allowListName: true,
+			wantID:        errand.ID,
+			wantRest:      []string{"soon"},
+		},
+		{
+			name:          "Short ID on any list",
+			args:          []string{"#7"},
+			listID:        MyListKey,
+			allowListName: true,
+			wantID:        received.ID,
+			wantRest:      []string{},
+		},
+		{
+			name:          "Short ID on other list",
+			args:          []string{"out", "#7"},
+			listID:        MyListKey,
+			allowListName: true,
+			wantUserError: true,
+		},
+		{
+			name:          "List flag",
+			args:          []string{"1", "--list", "in"},
+			listID:        MyListKey,
+			allowListName: true,
+			wantID:        received.ID,
+			wantRest:      []string{},
+		},
+		{
+			name:          "Invalid list flag",
+			args:          []string{"1", "--list", "done"},
+			listID:        MyListKey,
+			allowListName: true,
+			wantUserError: true,
+		},
+		{
+			name:          "Position out of range",
+			args:          []string{"3"},
+			listID:        MyListKey,
+			wantUserError: true,
+		},
+		{
+			name:          "Unknown issue",
+			args:          []string{"awesome"},
+			listID:        MyListKey,
+			wantUserError: true,
+		},
+		{
+			name:          "Missing position",
+			args:          []string{},
+			listID:        MyListKey,
+			wantUserError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issue, rest, isUserError, err := p.findIssueFromArgs(mustParseCommandArgs(t, strings.Join(tt.args, " ")), userID, tt.listID, tt.allowListName)
+			if tt.wantUserError {
+				require.Error(t, err)
+				assert.True(t, isUserError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, issue.ID)
+			assert.Equal(t, tt.wantRest, rest.values)
+		})
+	}
+}
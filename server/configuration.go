@@ -2,6 +2,8 @@ package main
 
 import (
 	"reflect"
+	"regexp"
+	"strings"
 
 	"github.com/mattermost/mattermost/server/public/pluginapi/experimental/bot/logger"
 	"github.com/mattermost/mattermost/server/public/pluginapi/experimental/telemetry"
@@ -20,9 +22,25 @@ import (
 // If you add non-reference types to your configuration struct, be sure to rewrite Clone as a deep
 // copy appropriate for your types.
 type configuration struct {
-	HideTeamSidebar bool `json:"hide_team_sidebar"`
+	HideTeamSidebar bool   `json:"hide_team_sidebar"`
+	StorageType     string `json:"storage_type"`
+	ReactionEmoji   string `json:"reaction_emoji"`
+
+	AutoCaptureKeywords string `json:"auto_capture_keywords"`
+	AutoCaptureChannels string `json:"auto_capture_channels"`
+
+	// autoCapturePattern matches the lines of posts offered to be added as Todos, built from
+	// AutoCaptureKeywords. It is nil when no keyword is set.
+	autoCapturePattern *regexp.Regexp
 }
 
+const (
+	// StorageKV stores the todos as JSON values in the plugin KV store
+	StorageKV = "kv"
+	// StorageDatabase stores the todos in dedicated database tables
+	StorageDatabase = "database"
+)
+
 // Clone shallow copies the configuration. Your implementation may require a deep copy if
 // your configuration has reference types.
 func (c *configuration) Clone() *configuration {
@@ -31,9 +49,21 @@ func (c *configuration) Clone() *configuration {
 }
 
 func (c *configuration) IsValid() error {
+	switch c.StorageType {
+	case "", StorageKV, StorageDatabase:
+	default:
+		return errors.Errorf("invalid storage type %q", c.StorageType)
+	}
+
 	return nil
 }
 
+// reactionEmojiName returns the name of the emoji that adds the posts reacted with to the Todo list,
+// without colons, or an empty string if reacting to posts does not add them
+func (c *configuration) reactionEmojiName() string {
+	return strings.Trim(strings.TrimSpace(c.ReactionEmoji), ":")
+}
+
 // getConfiguration retrieves the active configuration under lock, making it safe to use
 // concurrently. The active configuration may change underneath the client of this method, but
 // the struct returned by this API call is considered immutable.
@@ -89,6 +119,8 @@ func (p *Plugin) OnConfigurationChange() error {
 		return errors.Wrap(err, "failed to load plugin configuration")
 	}
 
+	configuration.autoCapturePattern = newAutoCapturePattern(configuration.AutoCaptureKeywords)
+
 	shouldUpdateClient := p.hasClientConfigChanged(p.configuration, configuration)
 	p.setConfiguration(configuration)
 
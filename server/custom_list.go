@@ -0,0 +1,261 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+const (
+	// CustomListKeyPrefix starts the IDs of the lists created by users, followed by a random ID
+	CustomListKeyPrefix = "_list_"
+
+	maxCustomLists          = 50
+	maxCustomListNameLength = 64
+)
+
+var (
+	// ErrListNotFound is returned when a custom list does not exist
+	ErrListNotFound = errors.New("cannot find list")
+	// ErrListExists is returned when naming a custom list like another one of the same user
+	ErrListExists = errors.New("a list with that name already exists")
+	// ErrInvalidListName is returned when a custom list name cannot be used
+	ErrInvalidListName = errors.New("invalid list name")
+	// ErrTooManyLists is returned when creating a custom list for a user that has the maximum number of them
+	ErrTooManyLists = fmt.Errorf("you can have at most %d lists", maxCustomLists)
+	// ErrCannotMove is returned when moving to another list an issue that is not on the my list or a custom list
+	ErrCannotMove = errors.New("only the todos on your own lists can be moved to another list, accept received todos first")
+)
+
+// CustomList is a list created by a user to organize their own todos, besides the my list. Its ID
+// is used as the list ID of the references to its issues.
+type CustomList struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// CustomListIssues holds a custom list along with its issues
+type CustomListIssues struct {
+	CustomList
+	Issues []*ExtendedIssue `json:"issues"`
+}
+
+func newCustomListKey() string {
+	return CustomListKeyPrefix + model.NewId()
+}
+
+// isCustomListKey returns whether listID is the ID of a custom list
+func isCustomListKey(listID string) bool {
+	return strings.HasPrefix(listID, CustomListKeyPrefix) && model.IsValidId(strings.TrimPrefix(listID, CustomListKeyPrefix))
+}
+
+// normalizeCustomListName returns name with its whitespace collapsed, or an error if it cannot be
+// used as a list name. Names must not be mistaken for the built-in lists, positions or short IDs.
+func normalizeCustomListName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+
+	switch {
+	case name == "":
+		return "", fmt.Errorf("%w: the name cannot be empty", ErrInvalidListName)
+	case len(name) > maxCustomListNameLength:
+		return "", fmt.Errorf("%w: names can have at most %d characters", ErrInvalidListName, maxCustomListNameLength)
+	case strings.HasPrefix(name, "#"), strings.HasPrefix(name, "-"):
+		return "", fmt.Errorf("%w: names cannot start with # or -", ErrInvalidListName)
+	}
+
+	switch strings.ToLower(name) {
+	case MyFlag, InFlag, OutFlag, DoneFlag:
+		return "", fmt.Errorf("%w: `%s` is a built-in list", ErrInvalidListName, name)
+	}
+
+	if _, err := strconv.Atoi(name); err == nil {
+		return "", fmt.Errorf("%w: names cannot be numbers", ErrInvalidListName)
+	}
+
+	return name, nil
+}
+
+// findCustomList returns the position on lists of the list with the given ID or name, compared
+// case insensitively, or -1 if there is none
+func findCustomList(lists []*CustomList, ref string) int {
+	for i, list := range lists {
+		if list.ID == ref || strings.EqualFold(list.Name, ref) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (l *listManager) GetCustomLists(userID string) ([]*CustomList, error) {
+	return l.store.GetCustomLists(userID)
+}
+
+func (l *listManager) GetCustomList(userID, ref string) (*CustomList, error) {
+	lists, err := l.store.GetCustomLists(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	n := findCustomList(lists, ref)
+	if n < 0 {
+		return nil, ErrListNotFound
+	}
+
+	return lists[n], nil
+}
+
+func (l *listManager) CreateList(userID, name string) (*CustomList, error) {
+	name, err := normalizeCustomListName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &CustomList{ID: newCustomListKey(), Name: name}
+	err = l.store.UpdateCustomLists(userID, func(lists []*CustomList) ([]*CustomList, error) {
+		if findCustomList(lists, name) >= 0 {
+			return nil, ErrListExists
+		}
+		if len(lists) >= maxCustomLists {
+			return nil, ErrTooManyLists
+		}
+		return append(lists, list), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (l *listManager) RenameList(userID, listID, name string) (*CustomList, error) {
+	name, err := normalizeCustomListName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var renamed *CustomList
+	err = l.store.UpdateCustomLists(userID, func(lists []*CustomList) ([]*CustomList, error) {
+		n := findCustomList(lists, listID)
+		if n < 0 || lists[n].ID != listID {
+			return nil, ErrListNotFound
+		}
+		if other := findCustomList(lists, name); other >= 0 && other != n {
+			return nil, ErrListExists
+		}
+
+		lists[n].Name = name
+		renamed = lists[n]
+		return lists, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return renamed, nil
+}
+
+func (l *listManager) MoveList(userID, listID string, position int) error {
+	return l.store.UpdateCustomLists(userID, func(lists []*CustomList) ([]*CustomList, error) {
+		n := findCustomList(lists, listID)
+		if n < 0 || lists[n].ID != listID {
+			return nil, ErrListNotFound
+		}
+
+		list := lists[n]
+		lists = append(lists[:n:n], lists[n+1:]...)
+		if position < 0 || position > len(lists) {
+			position = len(lists)
+		}
+		return append(lists[:position:position], append([]*CustomList{list}, lists[position:]...)...), nil
+	})
+}
+
+func (l *listManager) DeleteList(userID, listID string) (*CustomList, int, error) {
+	list, err := l.GetCustomList(userID, listID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if list.ID != listID {
+		return nil, 0, ErrListNotFound
+	}
+
+	irs, err := l.store.GetList(userID, listID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entry := newJournalEntry(JournalMove, userID)
+	for _, ir := range irs {
+		moveReferenceSteps(entry, userID, ir, listID, MyListKey)
+	}
+	if err = l.runJournaled(entry); err != nil {
+		return nil, 0, err
+	}
+
+	err = l.store.UpdateCustomLists(userID, func(lists []*CustomList) ([]*CustomList, error) {
+		n := findCustomList(lists, listID)
+		if n < 0 || lists[n].ID != listID {
+			return nil, ErrListNotFound
+		}
+		return append(lists[:n:n], lists[n+1:]...), nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return list, len(irs), nil
+}
+
+func (l *listManager) MoveIssueToList(userID, issueID, listID string) (string, error) {
+	fromListID, ir, _ := l.store.GetIssueListAndReference(userID, issueID)
+	if ir == nil {
+		return "", ErrIssueNotFound
+	}
+	if fromListID != MyListKey && !isCustomListKey(fromListID) {
+		return "", ErrCannotMove
+	}
+
+	if listID != MyListKey {
+		if _, err := l.GetCustomList(userID, listID); err != nil {
+			return "", err
+		}
+	}
+
+	if fromListID == listID {
+		return fromListID, nil
+	}
+
+	entry := newJournalEntry(JournalMove, userID)
+	moveReferenceSteps(entry, userID, ir, fromListID, listID)
+	if err := l.runJournaled(entry); err != nil {
+		return "", err
+	}
+
+	return fromListID, nil
+}
+
+// moveReferenceSteps adds to entry the steps to move the reference ir of userID from fromListID to
+// the end of toListID, keeping its foreign counterpart
+func moveReferenceSteps(entry *JournalEntry, userID string, ir *IssueRef, fromListID, toListID string) {
+	entry.insertReference(userID, toListID, &IssueRef{
+		IssueID:        ir.IssueID,
+		ForeignIssueID: ir.ForeignIssueID,
+		ForeignUserID:  ir.ForeignUserID,
+	}, -1)
+	entry.removeReference(userID, ir.IssueID, fromListID)
+}
+
+// existingListID returns listID, or the my list if listID is a custom list of userID that no longer exists
+func (l *listManager) existingListID(userID, listID string) string {
+	if !isCustomListKey(listID) {
+		return listID
+	}
+
+	if _, err := l.GetCustomList(userID, listID); errors.Is(err, ErrListNotFound) {
+		return MyListKey
+	}
+	return listID
+}
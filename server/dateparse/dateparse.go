@@ -0,0 +1,443 @@
+// Package dateparse parses the dates and times people type, like "tomorrow", "next friday 3pm",
+// "in 2 days" or "eod", relative to a given time.
+package dateparse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// EndOfDayHour and EndOfDayMinute are the time of dates given without time
+	EndOfDayHour   = 23
+	EndOfDayMinute = 59
+	// EndOfWorkdayHour is the time of "eod" and "eow"
+	EndOfWorkdayHour = 17
+	// EveningHour is the time of "tonight"
+	EveningHour = 20
+)
+
+// Clock is a time of the day
+type Clock struct {
+	Hour   int
+	Minute int
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+// parser holds the state of parsing the words of a text. A text is made of a date part and a
+// time part in any order, or of a duration from now.
+type parser struct {
+	words []string
+	pos   int
+	now   time.Time
+
+	date  *time.Time
+	clock *Clock
+	// defaultClock is the time used when the text has a date but no time
+	defaultClock *Clock
+	exact        *time.Time
+}
+
+// Parse returns the time described by text, relative to now and on its location. Dates without
+// time are due at the end of the day, and times without date are today, or tomorrow if they
+// already passed.
+func Parse(text string, now time.Time) (time.Time, error) {
+	return ParseWithDefaultClock(text, now, Clock{Hour: EndOfDayHour, Minute: EndOfDayMinute})
+}
+
+// ParseWithDefaultClock is like Parse, but dates without time are at defaultClock
+func ParseWithDefaultClock(text string, now time.Time, defaultClock Clock) (time.Time, error) {
+	words := strings.Fields(strings.ToLower(strings.ReplaceAll(text, ",", " ")))
+	if len(words) == 0 {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+
+	p := &parser{words: words, now: now}
+	for p.pos < len(p.words) {
+		if err := p.parseNext(); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	return p.result(defaultClock)
+}
+
+// ParseClock parses a time of the day like "9am", "5:30 pm" or "17:30"
+func ParseClock(text string) (Clock, error) {
+	p := &parser{words: strings.Fields(strings.ToLower(text))}
+	clock, n, ok := p.readClock(0)
+	if !ok || n != len(p.words) {
+		return Clock{}, fmt.Errorf("cannot understand time %q", text)
+	}
+	return clock, nil
+}
+
+func (p *parser) parseNext() error {
+	word := p.words[p.pos]
+
+	// Connectors must be followed by what they introduce, so the text following a date, like
+	// "friday on call rotation", is not taken as part of it
+	switch word {
+	case "on", "at", "by", "due", "in":
+		if p.peek(1) == "" {
+			return fmt.Errorf("nothing after %q", word)
+		}
+	}
+
+	switch word {
+	case "on", "at", "by", "due":
+		// Filler words, but "at" may introduce a bare hour
+		if word == "at" {
+			if hour, err := strconv.Atoi(p.peek(1)); err == nil && hour >= 0 && hour < 24 {
+				p.pos += 2
+				return p.setClock(Clock{Hour: hour})
+			}
+		}
+		p.pos++
+		return nil
+	case "today":
+		p.pos++
+		return p.setDate(p.today())
+	case "tonight":
+		p.pos++
+		p.defaultClock = &Clock{Hour: EveningHour}
+		return p.setDate(p.today())
+	case "tomorrow", "tmr", "tmrw":
+		p.pos++
+		return p.setDate(p.today().AddDate(0, 0, 1))
+	case "eod":
+		p.pos++
+		p.defaultClock = &Clock{Hour: EndOfWorkdayHour}
+		return p.setDate(p.today())
+	case "eow":
+		p.pos++
+		p.defaultClock = &Clock{Hour: EndOfWorkdayHour}
+		return p.setDate(p.nextWeekday(time.Friday, true))
+	case "noon":
+		p.pos++
+		return p.setClock(Clock{Hour: 12})
+	case "next", "this":
+		return p.parseRelativeWeek()
+	case "in":
+		p.pos++
+		return p.parseDuration()
+	}
+
+	if weekday, ok := weekdays[word]; ok {
+		p.pos++
+		return p.setDate(p.nextWeekday(weekday, false))
+	}
+
+	if date, ok := p.readMonthDate(); ok {
+		return p.setDate(date)
+	}
+
+	if date, clock, ok := readISO(word, p.now.Location()); ok {
+		p.pos++
+		if clock != nil {
+			if err := p.setClock(*clock); err != nil {
+				return err
+			}
+		}
+		return p.setDate(date)
+	}
+
+	if clock, n, ok := p.readClock(p.pos); ok {
+		p.pos += n
+		return p.setClock(clock)
+	}
+
+	if _, err := strconv.Atoi(word); err == nil && isUnit(p.peek(1)) {
+		return p.parseDuration()
+	}
+
+	return fmt.Errorf("cannot understand %q", word)
+}
+
+// parseRelativeWeek parses "this" followed by a weekday, which may be today, or "next" followed by
+// a weekday, "week" or "month"
+func (p *parser) parseRelativeWeek() error {
+	relative, word := p.peek(0), p.peek(1)
+	p.pos += 2
+
+	if weekday, ok := weekdays[word]; ok {
+		return p.setDate(p.nextWeekday(weekday, relative == "this"))
+	}
+	if relative == "this" {
+		return fmt.Errorf("cannot understand \"this %s\"", word)
+	}
+
+	today := p.today()
+	switch word {
+	case "week":
+		return p.setDate(p.nextWeekday(time.Monday, false))
+	case "month":
+		return p.setDate(time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location()))
+	}
+
+	return fmt.Errorf("cannot understand \"next %s\"", word)
+}
+
+// parseDuration parses "N unit", "a unit" or "an unit". Minutes and hours are added to now, longer
+// units to today.
+func (p *parser) parseDuration() error {
+	amount, unit := p.peek(0), p.peek(1)
+	p.pos += 2
+
+	n := 1
+	if amount != "a" && amount != "an" {
+		var err error
+		if n, err = strconv.Atoi(amount); err != nil || n < 0 {
+			return fmt.Errorf("cannot understand \"in %s\"", amount)
+		}
+	}
+
+	today := p.today()
+	switch strings.TrimSuffix(unit, "s") {
+	case "min", "minute":
+		return p.setExact(p.now.Add(time.Duration(n) * time.Minute))
+	case "h", "hr", "hour":
+		return p.setExact(p.now.Add(time.Duration(n) * time.Hour))
+	case "d", "day":
+		return p.setDate(today.AddDate(0, 0, n))
+	case "w", "wk", "week":
+		return p.setDate(today.AddDate(0, 0, 7*n))
+	case "month":
+		return p.setDate(today.AddDate(0, n, 0))
+	}
+
+	return fmt.Errorf("cannot understand unit %q", unit)
+}
+
+func isUnit(word string) bool {
+	switch strings.TrimSuffix(word, "s") {
+	case "min", "minute", "h", "hr", "hour", "d", "day", "w", "wk", "week", "month":
+		return true
+	}
+	return false
+}
+
+// readMonthDate reads dates like "jan 31", "january 31st 2026" or "31 jan", which are this year,
+// or the next one if already passed
+func (p *parser) readMonthDate() (time.Time, bool) {
+	var month time.Month
+	var day, n int
+	if m, ok := months[p.peek(0)]; ok {
+		d, ok := parseDay(p.peek(1))
+		if !ok {
+			return time.Time{}, false
+		}
+		month, day, n = m, d, 2
+	} else if d, ok := parseDay(p.peek(0)); ok {
+		next := 1
+		if p.peek(1) == "of" {
+			next = 2
+		}
+		m, ok := months[p.peek(next)]
+		if !ok {
+			return time.Time{}, false
+		}
+		month, day, n = m, d, next+1
+	} else {
+		return time.Time{}, false
+	}
+
+	today := p.today()
+	year := today.Year()
+	explicitYear := false
+	if y, err := strconv.Atoi(p.peek(n)); err == nil && y >= 1000 && y <= 9999 {
+		year, explicitYear = y, true
+		n++
+	}
+
+	date := time.Date(year, month, day, 0, 0, 0, 0, today.Location())
+	if date.Day() != day {
+		return time.Time{}, false
+	}
+	if !explicitYear && date.Before(today) {
+		date = date.AddDate(1, 0, 0)
+	}
+
+	p.pos += n
+	return date, true
+}
+
+func parseDay(word string) (int, bool) {
+	for _, suffix := range []string{"st", "nd", "rd", "th"} {
+		word = strings.TrimSuffix(word, suffix)
+	}
+	day, err := strconv.Atoi(word)
+	if err != nil || day < 1 || day > 31 {
+		return 0, false
+	}
+	return day, true
+}
+
+// readISO reads the YYYY-MM-DD and YYYY-MM-DDTHH:MM formats
+func readISO(word string, loc *time.Location) (time.Time, *Clock, bool) {
+	if t, err := time.ParseInLocation("2006-01-02t15:04", word, loc); err == nil {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc), &Clock{Hour: t.Hour(), Minute: t.Minute()}, true
+	}
+	if t, err := time.ParseInLocation("2006-01-02", word, loc); err == nil {
+		return t, nil, true
+	}
+	return time.Time{}, nil, false
+}
+
+// readClock reads a time of the day starting at the word at pos, like "3pm", "3 pm", "3:30pm" or
+// "15:30", and returns the number of words read
+func (p *parser) readClock(pos int) (Clock, int, bool) {
+	word := p.peekAt(pos)
+	n := 1
+
+	suffix := ""
+	for _, s := range []string{"am", "pm", "a.m.", "p.m."} {
+		if strings.HasSuffix(word, s) && word != s {
+			suffix, word = s[:1], strings.TrimSuffix(word, s)
+			break
+		}
+	}
+	if suffix == "" {
+		switch p.peekAt(pos + 1) {
+		case "am", "a.m.":
+			suffix, n = "a", 2
+		case "pm", "p.m.":
+			suffix, n = "p", 2
+		}
+	}
+
+	hourText, minuteText, hasMinutes := strings.Cut(word, ":")
+	if !hasMinutes && suffix == "" {
+		return Clock{}, 0, false
+	}
+
+	hour, err := strconv.Atoi(hourText)
+	if err != nil || hour < 0 || hour > 23 {
+		return Clock{}, 0, false
+	}
+
+	minute := 0
+	if hasMinutes {
+		minute, err = strconv.Atoi(minuteText)
+		if err != nil || len(minuteText) != 2 || minute < 0 || minute > 59 {
+			return Clock{}, 0, false
+		}
+	}
+
+	if suffix != "" {
+		if hour < 1 || hour > 12 {
+			return Clock{}, 0, false
+		}
+		hour %= 12
+		if suffix == "p" {
+			hour += 12
+		}
+	}
+
+	return Clock{Hour: hour, Minute: minute}, n, true
+}
+
+func (p *parser) peek(offset int) string {
+	return p.peekAt(p.pos + offset)
+}
+
+func (p *parser) peekAt(pos int) string {
+	if pos >= len(p.words) {
+		return ""
+	}
+	return p.words[pos]
+}
+
+func (p *parser) today() time.Time {
+	return time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+}
+
+// nextWeekday returns the next date on weekday after today, or today if includeToday is set
+func (p *parser) nextWeekday(weekday time.Weekday, includeToday bool) time.Time {
+	today := p.today()
+	days := (int(weekday) - int(today.Weekday()) + 7) % 7
+	if days == 0 && !includeToday {
+		days = 7
+	}
+	return today.AddDate(0, 0, days)
+}
+
+func (p *parser) setDate(date time.Time) error {
+	if p.date != nil || p.exact != nil {
+		return fmt.Errorf("more than one date given")
+	}
+	p.date = &date
+	return nil
+}
+
+func (p *parser) setClock(clock Clock) error {
+	if p.clock != nil || p.exact != nil {
+		return fmt.Errorf("more than one time given")
+	}
+	p.clock = &clock
+	return nil
+}
+
+func (p *parser) setExact(t time.Time) error {
+	if p.date != nil || p.clock != nil || p.exact != nil {
+		return fmt.Errorf("more than one date given")
+	}
+	p.exact = &t
+	return nil
+}
+
+func (p *parser) result(defaultClock Clock) (time.Time, error) {
+	if p.exact != nil {
+		if p.date != nil || p.clock != nil {
+			return time.Time{}, fmt.Errorf("more than one date given")
+		}
+		return *p.exact, nil
+	}
+
+	if p.date == nil && p.clock == nil {
+		return time.Time{}, fmt.Errorf("no date given")
+	}
+
+	if p.date == nil {
+		t := time.Date(p.now.Year(), p.now.Month(), p.now.Day(), p.clock.Hour, p.clock.Minute, 0, 0, p.now.Location())
+		if t.Before(p.now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+
+	clock := defaultClock
+	if p.clock != nil {
+		clock = *p.clock
+	} else if p.defaultClock != nil {
+		clock = *p.defaultClock
+	}
+
+	return time.Date(p.date.Year(), p.date.Month(), p.date.Day(), clock.Hour, clock.Minute, 0, 0, p.date.Location()), nil
+}
@@ -0,0 +1,151 @@
+package dateparse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Madrid")
+	require.NoError(t, err)
+
+	// Wednesday
+	now := time.Date(2025, 1, 15, 10, 30, 0, 0, loc)
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2025, month, day, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		text    string
+		want    time.Time
+		wantErr bool
+	}{
+		{text: "today", want: at(time.January, 15, 23, 59)},
+		{text: "Today", want: at(time.January, 15, 23, 59)},
+		{text: "tonight", want: at(time.January, 15, 20, 0)},
+		{text: "tomorrow", want: at(time.January, 16, 23, 59)},
+		{text: "tmrw 9am", want: at(time.January, 16, 9, 0)},
+		{text: "tomorrow at 9", want: at(time.January, 16, 9, 0)},
+		{text: "tomorrow at 9:45", want: at(time.January, 16, 9, 45)},
+		{text: "at 3pm tomorrow", want: at(time.January, 16, 15, 0)},
+		{text: "eod", want: at(time.January, 15, 17, 0)},
+		{text: "eow", want: at(time.January, 17, 17, 0)},
+		{text: "noon", want: at(time.January, 15, 12, 0)},
+		{text: "3pm", want: at(time.January, 15, 15, 0)},
+		{text: "3 pm", want: at(time.January, 15, 15, 0)},
+		{text: "3:30 p.m.", want: at(time.January, 15, 15, 30)},
+		{text: "9am", want: at(time.January, 16, 9, 0)},
+		{text: "12am", want: at(time.January, 16, 0, 0)},
+		{text: "17:30", want: at(time.January, 15, 17, 30)},
+		{text: "friday", want: at(time.January, 17, 23, 59)},
+		{text: "fri", want: at(time.January, 17, 23, 59)},
+		{text: "on monday", want: at(time.January, 20, 23, 59)},
+		{text: "wednesday", want: at(time.January, 22, 23, 59)},
+		{text: "this wednesday", want: at(time.January, 15, 23, 59)},
+		{text: "next friday 3pm", want: at(time.January, 17, 15, 0)},
+		{text: "next friday, 3pm", want: at(time.January, 17, 15, 0)},
+		{text: "next week", want: at(time.January, 20, 23, 59)},
+		{text: "next month", want: at(time.February, 1, 23, 59)},
+		{text: "in 2 days", want: at(time.January, 17, 23, 59)},
+		{text: "2 days", want: at(time.January, 17, 23, 59)},
+		{text: "in a week", want: at(time.January, 22, 23, 59)},
+		{text: "in 1 month", want: at(time.February, 15, 23, 59)},
+		{text: "in 3 hours", want: at(time.January, 15, 13, 30)},
+		{text: "in an hour", want: at(time.January, 15, 11, 30)},
+		{text: "in 45 mins", want: at(time.January, 15, 11, 15)},
+		{text: "jan 31", want: at(time.January, 31, 23, 59)},
+		{text: "31st of january", want: at(time.January, 31, 23, 59)},
+		{text: "January 3rd", want: time.Date(2026, time.January, 3, 23, 59, 0, 0, loc)},
+		{text: "jan 3 2025", want: at(time.January, 3, 23, 59)},
+		{text: "feb 1 10am", want: at(time.February, 1, 10, 0)},
+		{text: "2025-01-31", want: at(time.January, 31, 23, 59)},
+		{text: "2025-01-31T10:30", want: at(time.January, 31, 10, 30)},
+		{text: "2025-01-31 10:30", want: at(time.January, 31, 10, 30)},
+		{text: "", wantErr: true},
+		{text: "someday", wantErr: true},
+		{text: "next year", wantErr: true},
+		{text: "this week", wantErr: true},
+		{text: "in 2 fortnights", wantErr: true},
+		{text: "feb 30", wantErr: true},
+		{text: "13pm", wantErr: true},
+		{text: "25:00", wantErr: true},
+		{text: "tomorrow friday", wantErr: true},
+		{text: "3pm 4pm", wantErr: true},
+		{text: "in 2 hours tomorrow", wantErr: true},
+		{text: "friday on", wantErr: true},
+		{text: "tomorrow at", wantErr: true},
+		{text: "3pm by", wantErr: true},
+		{text: "monday in", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := Parse(tt.text, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    Clock
+		wantErr bool
+	}{
+		{text: "9am", want: Clock{Hour: 9}},
+		{text: "5:30 pm", want: Clock{Hour: 17, Minute: 30}},
+		{text: "12pm", want: Clock{Hour: 12}},
+		{text: "17:00", want: Clock{Hour: 17}},
+		{text: "9", wantErr: true},
+		{text: "9am tomorrow", wantErr: true},
+		{text: "noon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := ParseClock(tt.text)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseWithDefaultClock(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Madrid")
+	require.NoError(t, err)
+
+	now := time.Date(2025, 1, 15, 10, 30, 0, 0, loc)
+	morning := Clock{Hour: 9}
+
+	tests := []struct {
+		text string
+		want time.Time
+	}{
+		{text: "tomorrow", want: time.Date(2025, 1, 16, 9, 0, 0, 0, loc)},
+		{text: "tomorrow 3pm", want: time.Date(2025, 1, 16, 15, 0, 0, 0, loc)},
+		{text: "eod", want: time.Date(2025, 1, 15, 17, 0, 0, 0, loc)},
+		{text: "in 2 hours", want: time.Date(2025, 1, 15, 12, 30, 0, 0, loc)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := ParseWithDefaultClock(tt.text, now, morning)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	// FsckOrphanReference is reported for references to issues that do not exist
+	FsckOrphanReference = "orphan_reference"
+	// FsckOrphanIssue is reported for issues that are not referenced by any list
+	FsckOrphanIssue = "orphan_issue"
+	// FsckForeignMismatch is reported for references whose foreign counterpart does not exist or does not point back to them
+	FsckForeignMismatch = "foreign_mismatch"
+	// FsckDuplicateReference is reported for issues referenced more than once in the lists of a user
+	FsckDuplicateReference = "duplicate_reference"
+)
+
+// fsckMaxReportedProblems is the number of problems listed on the command response
+const fsckMaxReportedProblems = 50
+
+// fsckLists are the lists checked for every user, with the custom lists of the user checked before
+// the done list. When an issue is referenced in several of them, the reference in the first one is kept.
+var fsckLists = []string{MyListKey, InListKey, OutListKey, DoneListKey}
+
+// FsckProblem is an inconsistency found by the checker
+type FsckProblem struct {
+	Type           string `json:"type"`
+	UserID         string `json:"user_id,omitempty"`
+	ListID         string `json:"list_id"`
+	IssueID        string `json:"issue_id"`
+	ForeignUserID  string `json:"foreign_user_id,omitempty"`
+	ForeignIssueID string `json:"foreign_issue_id,omitempty"`
+	Fixed          bool   `json:"fixed"`
+	FixError       string `json:"fix_error,omitempty"`
+
+	fix func() error
+}
+
+// FsckReport holds the results of checking the lists of one or all users
+type FsckReport struct {
+	Users      int            `json:"users"`
+	Channels   int            `json:"channels"`
+	References int            `json:"references"`
+	Issues     int            `json:"issues"`
+	Problems   []*FsckProblem `json:"problems"`
+}
+
+func (l *listManager) Fsck(userID string, fix bool) (*FsckReport, error) {
+	pending, err := l.pendingIssueIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := []string{userID}
+	if userID == "" {
+		userIDs, err = l.store.GetUserIDs()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	report := &FsckReport{Problems: []*FsckProblem{}}
+	referenced := map[string]bool{}
+	for _, id := range userIDs {
+		if err = l.checkUserLists(report, id, referenced, pending); err != nil {
+			return nil, errors.Wrapf(err, "failed to check lists of user %s", id)
+		}
+		report.Users++
+	}
+
+	// Orphan issues can only be detected when all the lists have been checked, including those of channels
+	if userID == "" {
+		channelIDs, err := l.store.GetChannelIDs()
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range channelIDs {
+			if err = l.checkLists(report, id, []string{ChannelListKey, ChannelDoneListKey}, referenced, pending); err != nil {
+				return nil, errors.Wrapf(err, "failed to check lists of channel %s", id)
+			}
+			report.Channels++
+		}
+
+		if err = l.checkOrphanIssues(report, referenced, pending); err != nil {
+			return nil, err
+		}
+	}
+
+	if !fix {
+		return report, nil
+	}
+
+	for _, problem := range report.Problems {
+		if err = problem.fix(); err != nil {
+			problem.FixError = err.Error()
+			l.api.LogWarn("Unable to fix todo inconsistency", "type", problem.Type, "user_id", problem.UserID, "issue_id", problem.IssueID, "err", err.Error())
+			continue
+		}
+		problem.Fixed = true
+	}
+
+	return report, nil
+}
+
+func (l *listManager) checkUserLists(report *FsckReport, userID string, referenced, pending map[string]bool) error {
+	customLists, err := l.store.GetCustomLists(userID)
+	if err != nil {
+		return err
+	}
+
+	listIDs := []string{}
+	for _, listID := range fsckLists {
+		if listID == DoneListKey {
+			for _, list := range customLists {
+				listIDs = append(listIDs, list.ID)
+			}
+		}
+		listIDs = append(listIDs, listID)
+	}
+
+	return l.checkLists(report, userID, listIDs, referenced, pending)
+}
+
+// checkLists checks the lists listIDs of userID, which is the ID of a channel for the channel lists
+func (l *listManager) checkLists(report *FsckReport, userID string, listIDs []string, referenced, pending map[string]bool) error {
+	seen := map[string]string{}
+	for _, listID := range listIDs {
+		irs, err := l.store.GetList(userID, listID)
+		if err != nil {
+			return err
+		}
+
+		for position, ir := range irs {
+			report.References++
+			referenced[ir.IssueID] = true
+			if pending[ir.IssueID] {
+				continue
+			}
+
+			problem := &FsckProblem{
+				UserID:         userID,
+				ListID:         listID,
+				IssueID:        ir.IssueID,
+				ForeignUserID:  ir.ForeignUserID,
+				ForeignIssueID: ir.ForeignIssueID,
+			}
+
+			if firstListID, ok := seen[ir.IssueID]; ok {
+				problem.Type = FsckDuplicateReference
+				problem.fix = l.fixDuplicateReference(userID, listID, firstListID, ir, position)
+				report.Problems = append(report.Problems, problem)
+				continue
+			}
+			seen[ir.IssueID] = listID
+
+			if _, err = l.store.GetIssue(ir.IssueID); err != nil {
+				if !errors.Is(err, ErrIssueNotFound) {
+					return err
+				}
+				problem.Type = FsckOrphanReference
+				problem.fix = func() error {
+					return l.store.RemoveReference(userID, ir.IssueID, listID)
+				}
+				report.Problems = append(report.Problems, problem)
+				continue
+			}
+
+			if ir.ForeignUserID == "" || pending[ir.ForeignIssueID] {
+				continue
+			}
+
+			matches, err := l.foreignReferenceMatches(userID, ir)
+			if err != nil {
+				return err
+			}
+			if !matches {
+				problem.Type = FsckForeignMismatch
+				problem.fix = l.fixForeignMismatch(userID, listID, ir, position)
+				report.Problems = append(report.Problems, problem)
+			}
+		}
+	}
+
+	return nil
+}
+
+// foreignReferenceMatches returns whether the foreign issue of ir exists and is referenced by
+// its user pointing back to ir
+func (l *listManager) foreignReferenceMatches(userID string, ir *IssueRef) (bool, error) {
+	foreignIssue, err := l.store.GetIssue(ir.ForeignIssueID)
+	if err != nil {
+		if errors.Is(err, ErrIssueNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	_, foreignIR, _ := l.store.GetIssueListAndReference(ir.ForeignUserID, ir.ForeignIssueID)
+	if foreignIR == nil {
+		foreignIR, _, _ = l.store.GetIssueReference(ir.ForeignUserID, ir.ForeignIssueID, DoneListKey)
+	}
+	if foreignIR == nil {
+		return false, nil
+	}
+
+	// The issues sent to several users point back to the copies of the recipients
+	if len(foreignIssue.Recipients) > 0 {
+		for _, recipient := range foreignIssue.Recipients {
+			if recipient.UserID == userID && recipient.IssueID == ir.IssueID {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	return foreignIR.ForeignIssueID == ir.IssueID && foreignIR.ForeignUserID == userID, nil
+}
+
+// fixDuplicateReference removes the reference ir from listID, keeping the one on firstListID. If both
+// are on the same list, only the first occurrence is kept.
+func (l *listManager) fixDuplicateReference(userID, listID, firstListID string, ir *IssueRef, position int) func() error {
+	return func() error {
+		if listID != firstListID {
+			return l.store.RemoveReference(userID, ir.IssueID, listID)
+		}
+
+		first, firstPosition, err := l.store.GetIssueReference(userID, ir.IssueID, listID)
+		if err != nil {
+			return err
+		}
+		if firstPosition == position {
+			return nil
+		}
+
+		if err = l.store.RemoveReference(userID, ir.IssueID, listID); err != nil && !errors.Is(err, ErrIssueNotFound) {
+			return err
+		}
+		if err = l.store.InsertReference(userID, listID, first, firstPosition); err != nil && !errors.Is(err, ErrReferenceExists) {
+			return err
+		}
+		return nil
+	}
+}
+
+// fixForeignMismatch unlinks ir from its foreign counterpart. Received and sent todos are moved to
+// the user's own list.
+func (l *listManager) fixForeignMismatch(userID, listID string, ir *IssueRef, position int) func() error {
+	return func() error {
+		newIR := &IssueRef{
+			IssueID:      ir.IssueID,
+			PreviousList: ir.PreviousList,
+		}
+
+		newListID := listID
+		if listID == InListKey || listID == OutListKey {
+			newListID = MyListKey
+			position = -1
+		}
+		if ir.PreviousList == InListKey || ir.PreviousList == OutListKey {
+			newIR.PreviousList = MyListKey
+		}
+
+		if err := l.store.RemoveReference(userID, ir.IssueID, listID); err != nil {
+			return err
+		}
+		return l.store.InsertReference(userID, newListID, newIR, position)
+	}
+}
+
+func (l *listManager) checkOrphanIssues(report *FsckReport, referenced, pending map[string]bool) error {
+	issueIDs, err := l.store.GetIssueIDs()
+	if err != nil {
+		return err
+	}
+
+	now := model.GetMillis()
+	for _, issueID := range issueIDs {
+		report.Issues++
+		if referenced[issueID] || pending[issueID] {
+			continue
+		}
+
+		issue, err := l.store.GetIssue(issueID)
+		if err != nil {
+			if errors.Is(err, ErrIssueNotFound) {
+				continue
+			}
+			return err
+		}
+
+		// Issues being added are stored before their reference
+		if now-issue.CreateAt < JournalRecoveryDelay {
+			continue
+		}
+
+		report.Problems = append(report.Problems, &FsckProblem{
+			Type:    FsckOrphanIssue,
+			IssueID: issueID,
+			fix: func() error {
+				return l.store.RemoveIssue("", issueID)
+			},
+		})
+	}
+
+	return nil
+}
+
+// pendingIssueIDs returns the issues modified by journaled operations that have not finished yet,
+// which must not be checked
+func (l *listManager) pendingIssueIDs() (map[string]bool, error) {
+	entries, err := l.store.GetJournalEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := map[string]bool{}
+	for _, entry := range entries {
+		for _, step := range entry.Steps {
+			pending[step.IssueID] = true
+			if step.Issue != nil {
+				pending[step.Issue.ID] = true
+			}
+			if step.Ref != nil {
+				pending[step.Ref.IssueID] = true
+				pending[step.Ref.ForeignIssueID] = true
+			}
+		}
+	}
+	delete(pending, "")
+
+	return pending, nil
+}
+
+// fsckReportToString formats report as a markdown message, using getUserName to resolve user IDs
+func fsckReportToString(report *FsckReport, getUserName func(userID string) string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Checked %d users, %d channels, %d references and %d issues.\n\n", report.Users, report.Channels, report.References, report.Issues))
+
+	if len(report.Problems) == 0 {
+		sb.WriteString("No problems found.")
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("Found %d problems:\n", len(report.Problems)))
+	for i, problem := range report.Problems {
+		if i == fsckMaxReportedProblems {
+			sb.WriteString(fmt.Sprintf("- ...and %d more\n", len(report.Problems)-i))
+			break
+		}
+
+		sb.WriteString(fmt.Sprintf("- `%s` issue `%s`", problem.Type, problem.IssueID))
+		if isChannelListKey(problem.ListID) {
+			sb.WriteString(fmt.Sprintf(" in the %s list of the channel `%s`", listDisplayName(problem.ListID), problem.UserID))
+		} else if problem.UserID != "" {
+			sb.WriteString(fmt.Sprintf(" in the %s list of @%s", listDisplayName(problem.ListID), getUserName(problem.UserID)))
+		}
+		if problem.FixError != "" {
+			sb.WriteString(fmt.Sprintf(" **(fix failed: %s)**", problem.FixError))
+		} else if problem.Fixed {
+			sb.WriteString(" **(fixed)**")
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func listDisplayName(listID string) string {
+	switch listID {
+	case InListKey:
+		return InFlag
+	case OutListKey:
+		return OutFlag
+	case DoneListKey, ChannelDoneListKey:
+		return DoneFlag
+	case ChannelListKey:
+		return "channel"
+	}
+	if isCustomListKey(listID) {
+		return "custom"
+	}
+	return MyFlag
+}
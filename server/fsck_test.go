@@ -0,0 +1,88 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFsck(t *testing.T) {
+	senderID, receiverID := model.NewId(), model.NewId()
+
+	newBrokenStore := func() *memStore {
+		store := newMemStore()
+
+		valid := newIssue("valid", "", "", "")
+		valid.CreateAt = 0
+		store.issues[valid.ID] = valid
+		store.lists[listKey(senderID, MyListKey)] = []*IssueRef{{IssueID: valid.ID}, {IssueID: "missing"}}
+		store.lists[listKey(senderID, DoneListKey)] = []*IssueRef{{IssueID: valid.ID}}
+
+		// Sent todo whose receiver reference was lost
+		sent := newIssue("sent", "", "", "")
+		received := newForeignIssue(sent)
+		store.issues[sent.ID] = sent
+		store.issues[received.ID] = received
+		store.lists[listKey(senderID, OutListKey)] = []*IssueRef{{IssueID: sent.ID, ForeignIssueID: received.ID, ForeignUserID: receiverID}}
+		store.lists[listKey(receiverID, InListKey)] = []*IssueRef{}
+
+		orphan := newIssue("orphan", "", "", "")
+		orphan.CreateAt = 0
+		store.issues[orphan.ID] = orphan
+
+		return store
+	}
+
+	problemTypes := func(report *FsckReport) []string {
+		types := []string{}
+		for _, problem := range report.Problems {
+			types = append(types, problem.Type)
+		}
+		return types
+	}
+
+	t.Run("problems are reported", func(t *testing.T) {
+		store := newBrokenStore()
+		l := &listManager{store: store, api: &plugintest.API{}}
+
+		report, err := l.Fsck("", false)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, report.Users)
+		assert.ElementsMatch(t, []string{FsckOrphanReference, FsckDuplicateReference, FsckForeignMismatch, FsckOrphanIssue}, problemTypes(report))
+		for _, problem := range report.Problems {
+			assert.False(t, problem.Fixed)
+		}
+	})
+
+	t.Run("problems are fixed", func(t *testing.T) {
+		store := newBrokenStore()
+		l := &listManager{store: store, api: &plugintest.API{}}
+
+		report, err := l.Fsck("", true)
+		require.NoError(t, err)
+		for _, problem := range report.Problems {
+			assert.True(t, problem.Fixed, problem.Type)
+		}
+
+		report, err = l.Fsck("", false)
+		require.NoError(t, err)
+		assert.Empty(t, report.Problems)
+		assert.Len(t, store.lists[listKey(senderID, MyListKey)], 2)
+		assert.Empty(t, store.lists[listKey(senderID, OutListKey)])
+	})
+
+	t.Run("orphan issues are not checked for a single user", func(t *testing.T) {
+		store := newBrokenStore()
+		l := &listManager{store: store, api: &plugintest.API{}}
+
+		report, err := l.Fsck(senderID, false)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, report.Users)
+		assert.NotContains(t, problemTypes(report), FsckOrphanIssue)
+	})
+}
@@ -2,19 +2,51 @@ package main
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/mattermost/mattermost/server/public/model"
 )
 
-// Issue represents a Todo issue
+const (
+	// PriorityLow is the priority for todos that can wait
+	PriorityLow = "low"
+	// PriorityNormal is the default priority of todos
+	PriorityNormal = "normal"
+	// PriorityHigh is the priority for important todos
+	PriorityHigh = "high"
+	// PriorityUrgent is the priority for todos that must be done as soon as possible
+	PriorityUrgent = "urgent"
+)
+
+// Issue represents a Todo issue. ShortID is a number unique among the issues of the user owning
+// the issue, easier to type than ID. SnoozedUntil is the time until which the issue is hidden from
+// the lists of its owner, if snoozed. Recurrence is the rule, as stored by normalizeRecurrence,
+// the todo is repeated with once finished. Checklist holds its subtasks, in order. Labels are the
+// tags of the issue, without the leading #, including those written on the message. Recipients are
+// the users the issue was sent to, when sent to several users at once. ClaimedBy is the member that
+// took an issue of a channel list. NextIssueID is the occurrence of NextUserID created on completing a
+// repeating issue, removed if the issue is reopened.
 type Issue struct {
-	ID            string `json:"id"`
-	Message       string `json:"message"`
-	PostPermalink string `json:"postPermalink"`
-	Description   string `json:"description,omitempty"`
-	CreateAt      int64  `json:"create_at"`
-	PostID        string `json:"post_id"`
+	ID            string          `json:"id"`
+	ShortID       int64           `json:"short_id,omitempty"`
+	Message       string          `json:"message"`
+	PostPermalink string          `json:"postPermalink"`
+	Description   string          `json:"description,omitempty"`
+	CreateAt      int64           `json:"create_at"`
+	DueAt         int64           `json:"due_at,omitempty"`
+	Priority      string          `json:"priority,omitempty"`
+	PostID        string          `json:"post_id"`
+	CompletedAt   int64           `json:"completed_at,omitempty"`
+	CompletedBy   string          `json:"completed_by,omitempty"`
+	SnoozedUntil  int64           `json:"snoozed_until,omitempty"`
+	Recurrence    string          `json:"recurrence,omitempty"`
+	Checklist     []ChecklistItem `json:"checklist,omitempty"`
+	Labels        []string        `json:"labels,omitempty"`
+	Recipients    []Recipient     `json:"recipients,omitempty"`
+	ClaimedBy     string          `json:"claimed_by,omitempty"`
+	NextIssueID   string          `json:"next_issue_id,omitempty"`
+	NextUserID    string          `json:"next_user_id,omitempty"`
 }
 
 // ExtendedIssue extends the information on Issue to be used on the front-end
@@ -23,13 +55,30 @@ type ExtendedIssue struct {
 	ForeignUser     string `json:"user"`
 	ForeignList     string `json:"list"`
 	ForeignPosition int    `json:"position"`
+	CompletedByUser string `json:"completed_by_user,omitempty"`
+	ClaimedByUser   string `json:"claimed_by_user,omitempty"`
+	// RecipientStatuses tell how far each recipient got, for issues sent to several users
+	RecipientStatuses []*RecipientStatus `json:"recipient_statuses,omitempty"`
 }
 
 // ListsIssue for all list issues
 type ListsIssue struct {
-	In  []*ExtendedIssue `json:"in"`
-	My  []*ExtendedIssue `json:"my"`
-	Out []*ExtendedIssue `json:"out"`
+	In    []*ExtendedIssue    `json:"in"`
+	My    []*ExtendedIssue    `json:"my"`
+	Out   []*ExtendedIssue    `json:"out"`
+	Lists []*CustomListIssues `json:"lists"`
+}
+
+// IssueUpdate holds the new values for the editable fields of an Issue.
+// Nil pointer fields are left untouched. Labels replace the labels of the issue, and the labels
+// written on the message are always added.
+type IssueUpdate struct {
+	Message     string
+	Description string
+	DueAt       *int64
+	Priority    *string
+	Recurrence  *string
+	Labels      *[]string
 }
 
 func newIssue(message, postPermalink, description, postID string) *Issue {
@@ -43,17 +92,173 @@ func newIssue(message, postPermalink, description, postID string) *Issue {
 	}
 }
 
-func issuesListToString(issues []*ExtendedIssue) string {
-	if len(issues) == 0 {
+// newForeignIssue creates a new issue with the same content as issue, to be used as
+// the counterpart stored for the other user of a sent todo.
+func newForeignIssue(issue *Issue) *Issue {
+	foreignIssue := newIssue(issue.Message, issue.PostPermalink, issue.Description, issue.PostID)
+	foreignIssue.DueAt = issue.DueAt
+	foreignIssue.Priority = issue.Priority
+	foreignIssue.Recurrence = issue.Recurrence
+	foreignIssue.Checklist = copyChecklist(issue.Checklist)
+	foreignIssue.Labels = append([]string(nil), issue.Labels...)
+	return foreignIssue
+}
+
+func copyIssue(issue *Issue) *Issue {
+	issueCopy := *issue
+	issueCopy.Checklist = copyChecklist(issue.Checklist)
+	issueCopy.Labels = append([]string(nil), issue.Labels...)
+	issueCopy.Recipients = append([]Recipient(nil), issue.Recipients...)
+	return &issueCopy
+}
+
+// IsOverdue returns whether the issue is not completed and has a due date previous to now
+func (i *Issue) IsOverdue(now int64) bool {
+	return i.DueAt != 0 && i.DueAt < now && i.CompletedAt == 0
+}
+
+// IsSnoozed returns whether the issue is hidden at now until its snooze ends
+func (i *Issue) IsSnoozed(now int64) bool {
+	return i.SnoozedUntil > now
+}
+
+// awakeIssues returns the issues that are not snoozed at now
+func awakeIssues(issues []*ExtendedIssue, now int64) []*ExtendedIssue {
+	awake := []*ExtendedIssue{}
+	for _, issue := range issues {
+		if !issue.IsSnoozed(now) {
+			awake = append(awake, issue)
+		}
+	}
+	return awake
+}
+
+// priorityRank returns a number that is higher the more important the priority is.
+// An empty priority is considered normal.
+func priorityRank(priority string) int {
+	switch priority {
+	case PriorityLow:
+		return 0
+	case PriorityHigh:
+		return 2
+	case PriorityUrgent:
+		return 3
+	default:
+		return 1
+	}
+}
+
+func isValidPriority(priority string) bool {
+	switch priority {
+	case "", PriorityLow, PriorityNormal, PriorityHigh, PriorityUrgent:
+		return true
+	}
+	return false
+}
+
+// sortIssuesByPriority sorts the issues from higher to lower priority, keeping the
+// list order between issues with the same priority.
+func sortIssuesByPriority(issues []*ExtendedIssue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		return priorityRank(issues[i].Priority) > priorityRank(issues[j].Priority)
+	})
+}
+
+func (u *IssueUpdate) apply(issue *Issue) {
+	issue.Message = u.Message
+	issue.Description = u.Description
+	if u.DueAt != nil {
+		issue.DueAt = *u.DueAt
+	}
+	if u.Priority != nil {
+		issue.Priority = *u.Priority
+	}
+	if u.Recurrence != nil {
+		issue.Recurrence = *u.Recurrence
+	}
+	if u.Labels != nil {
+		issue.Labels = mergeLabels(messageLabels(issue.Message), *u.Labels)
+	} else {
+		issue.Labels = mergeLabels(issue.Labels, messageLabels(issue.Message))
+	}
+}
+
+func issuesListToString(issues []*ExtendedIssue, loc *time.Location) string {
+	return issuesListWithPositionsToString(issues, nil, loc)
+}
+
+// issuesListWithPositionsToString formats issues showing the list position of each one, taken from
+// positions when given, so they can be addressed from commands even when the issues are sorted.
+// Snoozed issues are left out, keeping the positions of the rest. Times are shown on loc.
+func issuesListWithPositionsToString(issues []*ExtendedIssue, positions map[string]int, loc *time.Location) string {
+	now := model.GetMillis()
+	snoozed := len(issues) - len(awakeIssues(issues, now))
+	if len(issues) == snoozed {
+		if snoozed > 0 {
+			return fmt.Sprintf("Nothing to do! (%s)", snoozedCountText(snoozed))
+		}
 		return "Nothing to do!"
 	}
 
 	str := "\n\n"
 
-	for _, issue := range issues {
-		createAt := time.Unix(issue.CreateAt/1000, 0)
-		str += fmt.Sprintf("* %s\n  * (%s)\n", issue.Message, createAt.Format("January 2, 2006 at 15:04"))
+	for i, issue := range issues {
+		if issue.IsSnoozed(now) {
+			continue
+		}
+		position := i + 1
+		if positions != nil {
+			position = positions[issue.ID]
+		}
+		createAt := time.UnixMilli(issue.CreateAt).In(loc)
+		message := issueSummary(&issue.Issue)
+		if issue.Priority != "" && issue.Priority != PriorityNormal {
+			message = fmt.Sprintf("**[%s]** %s", issue.Priority, message)
+		}
+		if issue.ShortID != 0 {
+			message = fmt.Sprintf("#%d %s", issue.ShortID, message)
+		}
+		str += fmt.Sprintf("* `%d` %s\n  * (%s)\n", position, message, createAt.Format("January 2, 2006 at 15:04"))
+		if issue.DueAt != 0 {
+			dueAt := time.UnixMilli(issue.DueAt).In(loc)
+			str += fmt.Sprintf("  * Due %s", dueAt.Format("January 2, 2006 at 15:04"))
+			if issue.IsOverdue(now) {
+				str += " **(overdue)**"
+			}
+			str += "\n"
+		}
+		if len(issue.Labels) > 0 {
+			str += fmt.Sprintf("  * Tags: %s\n", labelsToString(issue.Labels))
+		}
+		if issue.ClaimedByUser != "" {
+			str += fmt.Sprintf("  * Claimed by @%s\n", issue.ClaimedByUser)
+		}
+		if len(issue.RecipientStatuses) > 0 {
+			str += fmt.Sprintf("  * Sent to %d users, %s\n", len(issue.RecipientStatuses), recipientsProgress(issue.RecipientStatuses))
+		}
+		if r, err := parseRecurrence(issue.Recurrence); err == nil {
+			str += fmt.Sprintf("  * Repeats %s\n", r.Describe())
+		}
+		if issue.CompletedAt != 0 {
+			completedAt := time.UnixMilli(issue.CompletedAt).In(loc)
+			str += fmt.Sprintf("  * Completed %s", completedAt.Format("January 2, 2006 at 15:04"))
+			if issue.CompletedByUser != "" {
+				str += fmt.Sprintf(" by @%s", issue.CompletedByUser)
+			}
+			str += "\n"
+		}
+	}
+
+	if snoozed > 0 {
+		str += fmt.Sprintf("\n_%s, not shown until they wake up._\n", snoozedCountText(snoozed))
 	}
 
 	return str
 }
+
+func snoozedCountText(count int) string {
+	if count == 1 {
+		return "1 snoozed Todo"
+	}
+	return fmt.Sprintf("%d snoozed Todos", count)
+}
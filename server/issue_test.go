@@ -0,0 +1,22 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssuesListToStringLocation(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	issue := newIssue("file taxes", "", "", "")
+	issue.CreateAt = time.Date(2025, time.March, 1, 20, 0, 0, 0, time.UTC).UnixMilli()
+	issue.DueAt = time.Date(2025, time.March, 31, 16, 30, 0, 0, time.UTC).UnixMilli()
+
+	text := issuesListToString([]*ExtendedIssue{{Issue: *issue}}, loc)
+	assert.Contains(t, text, "(March 2, 2025 at 05:00)")
+	assert.Contains(t, text, "Due April 1, 2025 at 01:30")
+}
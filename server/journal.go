@@ -0,0 +1,308 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	// JournalRecoveryDelay is the time in milliseconds after which a journaled operation that has not
+	// finished is considered interrupted
+	JournalRecoveryDelay = 60 * 1000
+	// JournalMaxAttempts is the number of times the recovery of an interrupted operation is tried before giving up
+	JournalMaxAttempts = 5
+	// JournalRecoveryInterval is how often the interrupted operations of all users are recovered
+	JournalRecoveryInterval = 15 * time.Minute
+
+	journalRecoveryJobKey = "journal_recovery"
+
+	// JournalSend is the journal operation of sent issues
+	JournalSend = "send"
+	// JournalChangeAssignment is the journal operation of reassigned issues
+	JournalChangeAssignment = "change_assignment"
+	// JournalComplete is the journal operation of completed issues
+	JournalComplete = "complete"
+	// JournalReopen is the journal operation of reopened issues
+	JournalReopen = "reopen"
+	// JournalRemove is the journal operation of removed issues
+	JournalRemove = "remove"
+	// JournalPop is the journal operation of popped issues
+	JournalPop = "pop"
+	// JournalMove is the journal operation of issues moved to another list
+	JournalMove = "move"
+
+	journalStepSaveIssue       = "save_issue"
+	journalStepRemoveIssue     = "remove_issue"
+	journalStepInsertReference = "insert_reference"
+	journalStepRemoveReference = "remove_reference"
+)
+
+// JournalEntry is the intent record of an operation that writes several keys, stored before
+// applying it so it can be completed if it gets interrupted.
+type JournalEntry struct {
+	ID        string         `json:"id"`
+	Operation string         `json:"operation"`
+	UserID    string         `json:"user_id"`
+	CreateAt  int64          `json:"create_at"`
+	Attempts  int            `json:"attempts"`
+	Steps     []*JournalStep `json:"steps"`
+	// RollingBack is set when Steps undo an operation that failed
+	RollingBack bool `json:"rolling_back,omitempty"`
+}
+
+// JournalStep is a single write of a journaled operation. Applying a step that was already
+// applied has no effect, so interrupted operations can be completed by applying all of them again.
+type JournalStep struct {
+	Action   string    `json:"action"`
+	UserID   string    `json:"user_id,omitempty"`
+	ListID   string    `json:"list_id,omitempty"`
+	IssueID  string    `json:"issue_id,omitempty"`
+	Issue    *Issue    `json:"issue,omitempty"`
+	Ref      *IssueRef `json:"ref,omitempty"`
+	Position int       `json:"position,omitempty"`
+	// Previous is the issue replaced by a save step, so it is not saved over later changes
+	Previous *Issue `json:"previous,omitempty"`
+}
+
+func newJournalEntry(operation, userID string) *JournalEntry {
+	return &JournalEntry{
+		ID:        model.NewId(),
+		Operation: operation,
+		UserID:    userID,
+		CreateAt:  model.GetMillis(),
+	}
+}
+
+func (e *JournalEntry) saveIssue(issue *Issue) {
+	e.Steps = append(e.Steps, &JournalStep{Action: journalStepSaveIssue, Issue: issue})
+}
+
+// restoreIssue saves issue back for userID, along with its short ID
+func (e *JournalEntry) restoreIssue(userID string, issue *Issue) {
+	e.Steps = append(e.Steps, &JournalStep{Action: journalStepSaveIssue, UserID: userID, Issue: issue})
+}
+
+// removeIssue removes issueID, owned by userID
+func (e *JournalEntry) removeIssue(userID, issueID string) {
+	e.Steps = append(e.Steps, &JournalStep{Action: journalStepRemoveIssue, UserID: userID, IssueID: issueID})
+}
+
+// insertReference adds ir to listID for userID at position. Negative positions append it.
+func (e *JournalEntry) insertReference(userID, listID string, ir *IssueRef, position int) {
+	e.Steps = append(e.Steps, &JournalStep{Action: journalStepInsertReference, UserID: userID, ListID: listID, Ref: ir, Position: position})
+}
+
+func (e *JournalEntry) removeReference(userID, issueID, listID string) {
+	e.Steps = append(e.Steps, &JournalStep{Action: journalStepRemoveReference, UserID: userID, ListID: listID, IssueID: issueID})
+}
+
+// runJournaled saves entry and applies its steps, removing it once they have all been applied.
+// If a step fails, the ones already applied are rolled back and the error is returned. Operations
+// interrupted before finishing either way are finished by RecoverOperations.
+func (l *listManager) runJournaled(entry *JournalEntry) error {
+	for _, step := range entry.Steps {
+		if step.Action == journalStepSaveIssue {
+			if previous, err := l.store.GetIssue(step.Issue.ID); err == nil {
+				step.Previous = previous
+			}
+		}
+	}
+
+	if err := l.store.SaveJournalEntry(entry); err != nil {
+		return err
+	}
+
+	rollback, err := l.applyJournalSteps(entry.Steps)
+	if err != nil {
+		l.rollbackJournaled(entry, rollback)
+		return err
+	}
+
+	if err := l.store.RemoveJournalEntry(entry.ID); err != nil {
+		l.api.LogError("cannot remove journal entry, Err=", err.Error())
+	}
+
+	return nil
+}
+
+// rollbackJournaled undoes the steps of entry already applied, given by rollback
+func (l *listManager) rollbackJournaled(entry *JournalEntry, rollback []*JournalStep) {
+	// From now on, recovering the operation means finishing the rollback
+	entry.Steps = rollback
+	entry.RollingBack = true
+	if err := l.store.SaveJournalEntry(entry); err != nil {
+		l.api.LogError("cannot update journal entry, Err=", err.Error())
+	}
+
+	if _, err := l.applyJournalSteps(rollback); err != nil {
+		l.api.LogWarn("Unable to roll back failed operation, it will be rolled back later", "operation", entry.Operation, "id", entry.ID, "err", err.Error())
+		return
+	}
+
+	if err := l.store.RemoveJournalEntry(entry.ID); err != nil {
+		l.api.LogError("cannot remove journal entry, Err=", err.Error())
+	}
+}
+
+// applyJournalSteps applies steps in order. It returns the steps undoing the ones applied, in the
+// order they must be applied, even if a step fails.
+func (l *listManager) applyJournalSteps(steps []*JournalStep) ([]*JournalStep, error) {
+	undo := &JournalEntry{}
+	for _, step := range steps {
+		if err := l.applyJournalStep(step, undo); err != nil {
+			return reverseSteps(undo.Steps), errors.Wrapf(err, "failed to apply %s step", step.Action)
+		}
+	}
+
+	return reverseSteps(undo.Steps), nil
+}
+
+// applyJournalStep applies step, adding the step that undoes it to undo if it changed anything
+func (l *listManager) applyJournalStep(step *JournalStep, undo *JournalEntry) error {
+	switch step.Action {
+	case journalStepSaveIssue:
+		current, err := l.store.GetIssue(step.Issue.ID)
+		if err != nil && !errors.Is(err, ErrIssueNotFound) {
+			return err
+		}
+		if current == nil && step.Previous != nil {
+			// Removed since
+			return nil
+		}
+		if current != nil && !sameIssue(current, step.Previous) && !sameIssue(current, step.Issue) {
+			// Changed since
+			return nil
+		}
+
+		if err = l.store.SaveIssue(step.Issue); err != nil {
+			return err
+		}
+		if step.UserID != "" && step.Issue.ShortID != 0 {
+			if err = l.store.SaveShortID(step.UserID, step.Issue.ShortID, step.Issue.ID); err != nil {
+				return err
+			}
+		}
+
+		if current == nil {
+			undo.removeIssue(step.UserID, step.Issue.ID)
+		} else {
+			undo.Steps = append(undo.Steps, &JournalStep{Action: journalStepSaveIssue, UserID: step.UserID, Issue: current, Previous: step.Issue})
+		}
+	case journalStepRemoveIssue:
+		current, err := l.store.GetIssue(step.IssueID)
+		if errors.Is(err, ErrIssueNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err = l.store.RemoveIssue(step.UserID, step.IssueID); err != nil {
+			return err
+		}
+		undo.restoreIssue(step.UserID, current)
+	case journalStepInsertReference:
+		err := l.store.InsertReference(step.UserID, step.ListID, step.Ref, step.Position)
+		if errors.Is(err, ErrReferenceExists) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		undo.removeReference(step.UserID, step.Ref.IssueID, step.ListID)
+	case journalStepRemoveReference:
+		ir, n, err := l.store.GetIssueReference(step.UserID, step.IssueID, step.ListID)
+		if errors.Is(err, ErrIssueNotFound) || (err == nil && ir == nil) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = l.store.RemoveReference(step.UserID, step.IssueID, step.ListID)
+		if errors.Is(err, ErrIssueNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		undo.insertReference(step.UserID, step.ListID, ir, n)
+	default:
+		return errors.Errorf("unknown journal step %q", step.Action)
+	}
+
+	return nil
+}
+
+func reverseSteps(steps []*JournalStep) []*JournalStep {
+	reversed := make([]*JournalStep, 0, len(steps))
+	for i := len(steps) - 1; i >= 0; i-- {
+		reversed = append(reversed, steps[i])
+	}
+	return reversed
+}
+
+// sameIssue returns whether a and b have the same content as stored
+func sameIssue(a, b *Issue) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	jsonA, errA := json.Marshal(a)
+	jsonB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(jsonA) == string(jsonB)
+}
+
+func (l *listManager) RecoverOperations() error {
+	entries, err := l.store.GetJournalEntries()
+	if err != nil {
+		return err
+	}
+
+	now := model.GetMillis()
+	for _, entry := range entries {
+		if now-entry.CreateAt < JournalRecoveryDelay {
+			continue
+		}
+
+		_, err = l.applyJournalSteps(entry.Steps)
+		if err == nil {
+			if entry.RollingBack {
+				l.api.LogInfo("Rolled back failed operation", "operation", entry.Operation, "id", entry.ID)
+			} else {
+				l.api.LogInfo("Completed interrupted operation", "operation", entry.Operation, "id", entry.ID)
+			}
+			if err = l.store.RemoveJournalEntry(entry.ID); err != nil {
+				l.api.LogError("cannot remove journal entry, Err=", err.Error())
+			}
+			continue
+		}
+
+		entry.Attempts++
+		if entry.Attempts >= JournalMaxAttempts {
+			l.api.LogError("Giving up completing interrupted operation", "operation", entry.Operation, "id", entry.ID, "err", err.Error())
+			if err = l.store.RemoveJournalEntry(entry.ID); err != nil {
+				l.api.LogError("cannot remove journal entry, Err=", err.Error())
+			}
+			continue
+		}
+
+		l.api.LogWarn("Unable to complete interrupted operation", "operation", entry.Operation, "id", entry.ID, "err", err.Error())
+		if err = l.store.SaveJournalEntry(entry); err != nil {
+			l.api.LogError("cannot update journal entry, Err=", err.Error())
+		}
+	}
+
+	return nil
+}
+
+// recoverOperations completes the interrupted operations of all users. It is run periodically
+// by the journal recovery job.
+func (p *Plugin) recoverOperations() {
+	if err := p.listManager.RecoverOperations(); err != nil {
+		p.API.LogError("Unable to recover interrupted operations", "err", err.Error())
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverOperations(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("LogInfo", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	senderID, receiverID := model.NewId(), model.NewId()
+	senderIssue := newIssue("message", "", "", "")
+	receiverIssue := newForeignIssue(senderIssue)
+	outIR := &IssueRef{IssueID: senderIssue.ID, ForeignIssueID: receiverIssue.ID, ForeignUserID: receiverID}
+	inIR := &IssueRef{IssueID: receiverIssue.ID, ForeignIssueID: senderIssue.ID, ForeignUserID: senderID}
+
+	newHalfAppliedSend := func(createAt int64) *memStore {
+		store := newMemStore()
+		entry := newJournalEntry(JournalSend, senderID)
+		entry.CreateAt = createAt
+		entry.saveIssue(senderIssue)
+		entry.saveIssue(receiverIssue)
+		entry.insertReference(senderID, OutListKey, outIR, -1)
+		entry.insertReference(receiverID, InListKey, inIR, -1)
+		store.journal = []*JournalEntry{entry}
+
+		// The operation was interrupted after adding the sender reference
+		store.issues[senderIssue.ID] = senderIssue
+		store.issues[receiverIssue.ID] = receiverIssue
+		store.lists[listKey(senderID, OutListKey)] = []*IssueRef{outIR}
+		return store
+	}
+
+	t.Run("interrupted operation is completed", func(t *testing.T) {
+		store := newHalfAppliedSend(model.GetMillis() - 2*JournalRecoveryDelay)
+		l := &listManager{store: store, api: api}
+
+		require.NoError(t, l.RecoverOperations())
+
+		assert.Equal(t, []*IssueRef{outIR}, store.lists[listKey(senderID, OutListKey)])
+		assert.Equal(t, []*IssueRef{inIR}, store.lists[listKey(receiverID, InListKey)])
+		assert.Empty(t, store.journal)
+	})
+
+	t.Run("issue changed since is not overwritten", func(t *testing.T) {
+		store := newHalfAppliedSend(model.GetMillis() - 2*JournalRecoveryDelay)
+		edited := *senderIssue
+		edited.Message = "edited after the operation"
+		store.issues[senderIssue.ID] = &edited
+		store.journal[0].Steps[0].Previous = &Issue{ID: senderIssue.ID, Message: "before the operation"}
+		l := &listManager{store: store, api: api}
+
+		require.NoError(t, l.RecoverOperations())
+
+		assert.Equal(t, "edited after the operation", store.issues[senderIssue.ID].Message)
+		assert.Equal(t, []*IssueRef{inIR}, store.lists[listKey(receiverID, InListKey)])
+	})
+
+	t.Run("recent operation is left alone", func(t *testing.T) {
+		store := newHalfAppliedSend(model.GetMillis())
+		l := &listManager{store: store, api: api}
+
+		require.NoError(t, l.RecoverOperations())
+
+		assert.Empty(t, store.lists[listKey(receiverID, InListKey)])
+		assert.Len(t, store.journal, 1)
+	})
+}
+
+// failingStore is a memStore failing to insert references on failListID
+type failingStore struct {
+	*memStore
+	failListID string
+}
+
+func (s *failingStore) InsertReference(userID, listID string, ir *IssueRef, position int) error {
+	if listID == s.failListID {
+		return errors.New("insert failed")
+	}
+	return s.memStore.InsertReference(userID, listID, ir, position)
+}
+
+func TestRunJournaledRollback(t *testing.T) {
+	api := &plugintest.API{}
+
+	userID := model.NewId()
+	issue := newIssue("message", "", "", "")
+	issue.ShortID = 4
+	otherIssue := newIssue("other", "", "", "")
+	sentIssue := newIssue("sent", "", "", "")
+	myList := []*IssueRef{{IssueID: otherIssue.ID}, {IssueID: issue.ID}}
+
+	store := &failingStore{memStore: newMemStore(), failListID: OutListKey}
+	store.issues[issue.ID] = issue
+	store.issues[otherIssue.ID] = otherIssue
+	store.lists[listKey(userID, MyListKey)] = append([]*IssueRef{}, myList...)
+	store.shortIDIssues[shortIDIssueKey(userID, issue.ShortID)] = issue.ID
+	l := &listManager{store: store, api: api}
+
+	entry := newJournalEntry(JournalSend, userID)
+	entry.removeReference(userID, issue.ID, MyListKey)
+	entry.removeIssue(userID, issue.ID)
+	entry.saveIssue(sentIssue)
+	entry.insertReference(userID, OutListKey, &IssueRef{IssueID: sentIssue.ID}, -1)
+
+	require.Error(t, l.runJournaled(entry))
+
+	// Nothing is left of the failed operation
+	assert.Equal(t, myList, store.lists[listKey(userID, MyListKey)])
+	assert.Equal(t, issue, store.issues[issue.ID])
+	assert.Equal(t, issue.ID, store.shortIDIssues[shortIDIssueKey(userID, issue.ShortID)])
+	assert.NotContains(t, store.issues, sentIssue.ID)
+	assert.Empty(t, store.journal)
+}
+
+func TestListStoreJournal(t *testing.T) {
+	entry := newJournalEntry(JournalSend, model.NewId())
+	jsonEntry, err := json.Marshal(entry)
+	require.NoError(t, err)
+
+	api := &plugintest.API{}
+	api.On("KVSet", "journal_"+entry.ID, jsonEntry).Return(nil)
+	api.On("KVList", 0, kvListPerPage).Return([]string{"journal_recovery", "journal_" + entry.ID, issueKey(model.NewId())}, nil)
+	api.On("KVGet", "journal_"+entry.ID).Return(jsonEntry, nil)
+	api.On("KVDelete", "journal_"+entry.ID).Return(nil)
+	defer api.AssertExpectations(t)
+
+	store := NewListStore(api)
+	require.NoError(t, store.SaveJournalEntry(entry))
+
+	entries, err := store.GetJournalEntries()
+	require.NoError(t, err)
+	assert.Equal(t, []*JournalEntry{entry}, entries)
+
+	require.NoError(t, store.RemoveJournalEntry(entry.ID))
+}
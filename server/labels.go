@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	maxLabels      = 20
+	maxLabelLength = 64
+	labelsNone     = "none"
+)
+
+var (
+	// labelPattern matches the valid labels, which start with a letter so they are not mistaken for
+	// short IDs such as #42
+	labelPattern = regexp.MustCompile(`^\p{L}[\p{L}\p{N}_-]*$`)
+	// messageLabelPattern matches the labels written on a message as #label
+	messageLabelPattern = regexp.MustCompile(`(?:^|\s)#(\p{L}[\p{L}\p{N}_-]*)`)
+)
+
+// normalizeLabel returns label without the leading # and in lower case, or an error if it is not a valid label
+func normalizeLabel(label string) (string, error) {
+	normalized := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(label), "#"))
+	if !labelPattern.MatchString(normalized) || len(normalized) > maxLabelLength {
+		return "", fmt.Errorf("invalid tag `%s`, tags start with a letter and have only letters, numbers, - and _", label)
+	}
+	return normalized, nil
+}
+
+// normalizeLabels normalizes labels and removes the duplicated ones
+func normalizeLabels(labels []string) ([]string, error) {
+	var normalized []string
+	for _, label := range labels {
+		l, err := normalizeLabel(label)
+		if err != nil {
+			return nil, err
+		}
+		normalized = mergeLabels(normalized, []string{l})
+	}
+
+	if len(normalized) > maxLabels {
+		return nil, fmt.Errorf("todos can have at most %d tags", maxLabels)
+	}
+	return normalized, nil
+}
+
+// parseLabels parses a comma or space separated list of labels, as given to --tag. labelsNone
+// stands for no labels.
+func parseLabels(value string) ([]string, error) {
+	if value == labelsNone {
+		return []string{}, nil
+	}
+
+	return normalizeLabels(strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' '
+	}))
+}
+
+// messageLabels returns the labels written on message as #label
+func messageLabels(message string) []string {
+	var labels []string
+	for _, match := range messageLabelPattern.FindAllStringSubmatch(message, -1) {
+		if len(match[1]) <= maxLabelLength {
+			labels = mergeLabels(labels, []string{strings.ToLower(match[1])})
+		}
+	}
+	return labels
+}
+
+// mergeLabels returns the labels on a followed by those on b that are not on a
+func mergeLabels(a, b []string) []string {
+	merged := append([]string(nil), a...)
+	for _, label := range b {
+		if !containsLabel(merged, label) {
+			merged = append(merged, label)
+		}
+	}
+	return merged
+}
+
+func containsLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// filterIssuesByLabel returns the issues having label
+func filterIssuesByLabel(issues []*ExtendedIssue, label string) []*ExtendedIssue {
+	filtered := []*ExtendedIssue{}
+	for _, issue := range issues {
+		if containsLabel(issue.Labels, label) {
+			filtered = append(filtered, issue)
+		}
+	}
+	return filtered
+}
+
+// labelsToString formats labels as they are written on messages
+func labelsToString(labels []string) string {
+	return "#" + strings.Join(labels, " #")
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLabels(t *testing.T) {
+	labels, err := parseLabels("#Release, oncall backend-2 release")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"release", "oncall", "backend-2"}, labels)
+
+	labels, err = parseLabels(labelsNone)
+	require.NoError(t, err)
+	assert.Empty(t, labels)
+
+	for _, value := range []string{"42", "#42", "rel.ease", "-x"} {
+		_, err = parseLabels(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestMessageLabels(t *testing.T) {
+	assert.Equal(t, []string{"release", "oncall"}, messageLabels("#Release notes for #42 #oncall and#not, #release again"))
+	assert.Empty(t, messageLabels("Fix #42"))
+}
+
+func TestIssueUpdateLabels(t *testing.T) {
+	issue := newIssue("Update the changelog #release", "", "", "")
+	(&IssueUpdate{Message: issue.Message}).apply(issue)
+	assert.Equal(t, []string{"release"}, issue.Labels)
+
+	tags := []string{"oncall"}
+	(&IssueUpdate{Message: issue.Message, Labels: &tags}).apply(issue)
+	assert.Equal(t, []string{"release", "oncall"}, issue.Labels)
+
+	(&IssueUpdate{Message: "Update the changelog #docs"}).apply(issue)
+	assert.Equal(t, []string{"release", "oncall", "docs"}, issue.Labels)
+
+	none := []string{}
+	(&IssueUpdate{Message: issue.Message, Labels: &none}).apply(issue)
+	assert.Equal(t, []string{"docs"}, issue.Labels)
+
+	issues := []*ExtendedIssue{{Issue: *issue}, {Issue: *newIssue("untagged", "", "", "")}}
+	filtered := filterIssuesByLabel(issues, "docs")
+	require.Len(t, filtered, 1)
+	assert.Equal(t, issue.ID, filtered[0].ID)
+	assert.Contains(t, issuesListToString(issues, time.UTC), "  * Tags: #docs\n")
+}
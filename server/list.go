@@ -2,7 +2,10 @@ package main
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
+	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/mattermost/mattermost/server/public/plugin"
 	"github.com/pkg/errors"
 )
@@ -14,6 +17,8 @@ const (
 	InListKey = "_in"
 	// OutListKey is the key used to store the list of sent todos
 	OutListKey = "_out"
+	// DoneListKey is the key used to store the list of completed todos
+	DoneListKey = "_done"
 )
 
 // ListStore represents the KVStore operations for lists
@@ -21,96 +26,159 @@ type ListStore interface {
 	// Issue related function
 	SaveIssue(issue *Issue) error
 	GetIssue(issueID string) (*Issue, error)
-	RemoveIssue(issueID string) error
-	GetAndRemoveIssue(issueID string) (*Issue, error)
+	// RemoveIssue removes the issue issueID of userID, along with its short ID
+	RemoveIssue(userID, issueID string) error
+	GetAndRemoveIssue(userID, issueID string) (*Issue, error)
 
 	// Issue References related functions
 
 	// AddReference creates a new IssueRef with the issueID, foreignUSerID and foreignIssueID, and stores it
 	// on the listID for userID.
 	AddReference(userID, issueID, listID, foreignUserID, foreignIssueID string) error
+	// InsertReference stores ir on the listID for userID at the given position. Out of range positions
+	// append ir at the end of the list.
+	InsertReference(userID, listID string, ir *IssueRef, position int) error
 	// RemoveReference removes the IssueRef for issueID in listID for userID
 	RemoveReference(userID, issueID, listID string) error
 	// PopReference removes the first IssueRef in listID for userID and returns it
 	PopReference(userID, listID string) (*IssueRef, error)
 	// BumpReference moves the Issue reference for issueID in listID for userID to the beginning of the list
 	BumpReference(userID, issueID, listID string) error
+	// MoveReference moves the Issue reference for issueID in listID for userID to newPosition. Out of
+	// range positions move it to the end of the list.
+	MoveReference(userID, issueID, listID string, newPosition int) error
+	// ReorderReferences atomically sorts listID for userID with the references for issueIDs first, in
+	// that order, followed by the rest in their current order. Returns ErrInvalidOrder if any of issueIDs
+	// is repeated or not on the list.
+	ReorderReferences(userID, listID string, issueIDs []string) error
 	// GetIssueReference gets the IssueRef and position of the issue issueID on user userID's list listID
 	GetIssueReference(userID, issueID, listID string) (*IssueRef, int, error)
 	// GetIssueListAndReference gets the issue list, IssueRef and position for user userID
 	GetIssueListAndReference(userID, issueID string) (string, *IssueRef, int)
 	// GetList returns the list of IssueRef in listID for userID
 	GetList(userID, listID string) ([]*IssueRef, error)
+	// GetUserIDs returns the users that have any list stored
+	GetUserIDs() ([]string, error)
+	// GetChannelIDs returns the channels that have any list stored
+	GetChannelIDs() ([]string, error)
+	// GetIssueIDs returns the IDs of all the stored issues
+	GetIssueIDs() ([]string, error)
+	// NextShortID atomically allocates a new short ID for an issue of userID
+	NextShortID(userID string) (int64, error)
+	// SaveShortID indexes issueID as the issue with shortID of userID
+	SaveShortID(userID string, shortID int64, issueID string) error
+	// GetIssueIDByShortID returns the ID of the issue with shortID of userID
+	GetIssueIDByShortID(userID string, shortID int64) (string, error)
+
+	// Custom list related functions
+
+	// GetCustomLists returns the custom lists of userID, in order
+	GetCustomLists(userID string) ([]*CustomList, error)
+	// UpdateCustomLists atomically replaces the custom lists of userID with the ones returned by update,
+	// which may be called several times. Errors returned by update are returned unchanged.
+	UpdateCustomLists(userID string, update func([]*CustomList) ([]*CustomList, error)) error
+
+	// Undo related functions
+
+	// SaveTombstone stores the information needed to undo the last destructive operation of userID,
+	// replacing any previous one. It expires after UndoExpirySeconds.
+	SaveTombstone(userID string, tombstone *Tombstone) error
+	// GetAndRemoveTombstone returns and removes the last tombstone stored for userID, or nil if none
+	GetAndRemoveTombstone(userID string) (*Tombstone, error)
+
+	// Journal related functions
+
+	// SaveJournalEntry stores entry, replacing any previous entry with the same ID
+	SaveJournalEntry(entry *JournalEntry) error
+	// RemoveJournalEntry removes the entry with entryID, if any
+	RemoveJournalEntry(entryID string) error
+	// GetJournalEntries returns all the stored journal entries
+	GetJournalEntries() ([]*JournalEntry, error)
 }
 
+var (
+	// ErrIssueNotFound is returned when an issue or an issue reference does not exist
+	ErrIssueNotFound = errors.New("cannot find issue")
+	// ErrReferenceExists is returned when adding an issue reference to a list that already contains it
+	ErrReferenceExists = errors.New("issue id already exists in list")
+	// ErrNothingToUndo is returned when there is no recent operation to undo
+	ErrNothingToUndo = errors.New("nothing to undo")
+	// ErrCannotSnooze is returned when snoozing an issue that is not on the my or in lists
+	ErrCannotSnooze = errors.New("only the todos on your own, custom and received lists can be snoozed")
+	// ErrInvalidOrder is returned when reordering a list with todos that are repeated or not on the list
+	ErrInvalidOrder = errors.New("the order must have each todo of the list at most once")
+	// ErrCannotReopen is returned when reopening a todo whose list or foreign counterpart no longer exists
+	ErrCannotReopen = errors.New("the todo cannot be reopened, its list or the todo of the other user no longer exists")
+)
+
 type listManager struct {
 	store ListStore
 	api   plugin.API
 }
 
 // NewListManager creates a new listManager
-func NewListManager(api plugin.API) ListManager {
+func NewListManager(api plugin.API, store ListStore) ListManager {
 	return &listManager{
-		store: NewListStore(api),
+		store: store,
 		api:   api,
 	}
 }
 
-func (l *listManager) AddIssue(userID, message, postPermalink, description, postID string) (*Issue, error) {
-	issue := newIssue(message, postPermalink, description, postID)
+func (l *listManager) AddIssue(userID string, issue *Issue) error {
+	if err := l.assignShortID(userID, issue); err != nil {
+		return err
+	}
 
 	if err := l.store.SaveIssue(issue); err != nil {
-		return nil, err
+		return err
 	}
 
 	if err := l.store.AddReference(userID, issue.ID, MyListKey, "", ""); err != nil {
-		if rollbackError := l.store.RemoveIssue(issue.ID); rollbackError != nil {
+		if rollbackError := l.store.RemoveIssue(userID, issue.ID); rollbackError != nil {
 			l.api.LogError("cannot rollback issue after add error, Err=", err.Error())
 		}
-		return nil, err
+		return err
 	}
 
-	return issue, nil
+	return nil
 }
 
-func (l *listManager) SendIssue(senderID, receiverID, message, postPermalink, description, postID string) (string, error) {
-	senderIssue := newIssue(message, postPermalink, description, postID)
-	if err := l.store.SaveIssue(senderIssue); err != nil {
+func (l *listManager) SendIssue(senderID, receiverID string, senderIssue *Issue) (string, error) {
+	receiverIssue := newForeignIssue(senderIssue)
+	if err := l.assignShortID(senderID, senderIssue); err != nil {
 		return "", err
 	}
-
-	receiverIssue := newIssue(message, postPermalink, description, postID)
-	if err := l.store.SaveIssue(receiverIssue); err != nil {
-		if rollbackError := l.store.RemoveIssue(senderIssue.ID); rollbackError != nil {
-			l.api.LogError("cannot rollback sender issue after send error, Err=", err.Error())
-		}
+	if err := l.assignShortID(receiverID, receiverIssue); err != nil {
 		return "", err
 	}
 
-	if err := l.store.AddReference(senderID, senderIssue.ID, OutListKey, receiverID, receiverIssue.ID); err != nil {
-		if rollbackError := l.store.RemoveIssue(senderIssue.ID); rollbackError != nil {
-			l.api.LogError("cannot rollback sender issue after send error, Err=", err.Error())
-		}
-		if rollbackError := l.store.RemoveIssue(receiverIssue.ID); rollbackError != nil {
-			l.api.LogError("cannot rollback receiver issue after send error, Err=", err.Error())
-		}
+	entry := newJournalEntry(JournalSend, senderID)
+	entry.saveIssue(senderIssue)
+	entry.saveIssue(receiverIssue)
+	entry.insertReference(senderID, OutListKey, &IssueRef{
+		IssueID:        senderIssue.ID,
+		ForeignIssueID: receiverIssue.ID,
+		ForeignUserID:  receiverID,
+	}, -1)
+	entry.insertReference(receiverID, InListKey, &IssueRef{
+		IssueID:        receiverIssue.ID,
+		ForeignIssueID: senderIssue.ID,
+		ForeignUserID:  senderID,
+	}, -1)
+
+	if err := l.runJournaled(entry); err != nil {
 		return "", err
 	}
 
-	if err := l.store.AddReference(receiverID, receiverIssue.ID, InListKey, senderID, senderIssue.ID); err != nil {
-		if rollbackError := l.store.RemoveIssue(senderIssue.ID); rollbackError != nil {
-			l.api.LogError("cannot rollback sender issue after send error, Err=", err.Error())
-		}
-		if rollbackError := l.store.RemoveIssue(receiverIssue.ID); rollbackError != nil {
-			l.api.LogError("cannot rollback receiver issue after send error ,Err=", err.Error())
-		}
-		if rollbackError := l.store.RemoveReference(senderID, senderIssue.ID, OutListKey); rollbackError != nil {
-			l.api.LogError("cannot rollback sender list after send error, Err=", err.Error())
-		}
-		return "", err
+	return receiverIssue.ID, nil
+}
+
+func (l *listManager) GetIssue(userID, issueID string) (*Issue, error) {
+	if _, ir, _ := l.store.GetIssueListAndReference(userID, issueID); ir == nil {
+		return nil, ErrIssueNotFound
 	}
 
-	return receiverIssue.ID, nil
+	return l.store.GetIssue(issueID)
 }
 
 func (l *listManager) GetIssueList(userID, listID string) ([]*ExtendedIssue, error) {
@@ -146,46 +214,192 @@ func (l *listManager) GetAllList(userID string) (listsIssue *ListsIssue, err err
 	if err != nil {
 		return nil, err
 	}
-	return &ListsIssue{
-		In:  inListIssue,
-		My:  myListIssue,
-		Out: outListIssue,
-	}, nil
+
+	// Snoozed issues are hidden until they wake up
+	now := model.GetMillis()
+	listsIssue = &ListsIssue{
+		In:    awakeIssues(inListIssue, now),
+		My:    awakeIssues(myListIssue, now),
+		Out:   outListIssue,
+		Lists: []*CustomListIssues{},
+	}
+
+	customLists, err := l.store.GetCustomLists(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, list := range customLists {
+		issues, err := l.GetIssueList(userID, list.ID)
+		if err != nil {
+			return nil, err
+		}
+		listsIssue.Lists = append(listsIssue.Lists, &CustomListIssues{
+			CustomList: *list,
+			Issues:     awakeIssues(issues, now),
+		})
+	}
+
+	return listsIssue, nil
+}
+
+func (l *listManager) GetDoneIssueList(userID string, page, perPage int) ([]*ExtendedIssue, error) {
+	irs, err := l.store.GetList(userID, DoneListKey)
+	if err != nil {
+		return nil, err
+	}
+
+	start := page * perPage
+	if start >= len(irs) {
+		return []*ExtendedIssue{}, nil
+	}
+	end := start + perPage
+	if end > len(irs) {
+		end = len(irs)
+	}
+
+	extendedIssues := []*ExtendedIssue{}
+	for _, ir := range irs[start:end] {
+		issue, err := l.store.GetIssue(ir.IssueID)
+		if err != nil {
+			continue
+		}
+
+		extendedIssue := l.extendIssueInfo(issue, ir)
+		extendedIssues = append(extendedIssues, extendedIssue)
+	}
+
+	return extendedIssues, nil
 }
 
 func (l *listManager) CompleteIssue(userID, issueID string) (issue *Issue, foreignID string, listToUpdate string, err error) {
-	issueList, ir, _ := l.store.GetIssueListAndReference(userID, issueID)
+	issueList, ir, n := l.store.GetIssueListAndReference(userID, issueID)
 	if ir == nil {
 		return nil, "", issueList, fmt.Errorf("cannot find element")
 	}
 
-	if err = l.store.RemoveReference(userID, issueID, issueList); err != nil {
+	issue, err = l.store.GetIssue(issueID)
+	if err != nil {
 		return nil, "", issueList, err
 	}
 
-	issue, err = l.store.GetAndRemoveIssue(issueID)
-	if err != nil {
-		l.api.LogError("cannot remove issue, Err=", err.Error())
+	tombstone := &Tombstone{
+		Action:   TombstoneComplete,
+		Issue:    copyIssue(issue),
+		ListID:   issueList,
+		Position: n,
+		Ref:      ir,
 	}
 
-	if ir.ForeignUserID == "" {
-		return issue, "", issueList, nil
+	completedAt := model.GetMillis()
+	entry := newJournalEntry(JournalComplete, userID)
+	archiveSteps(entry, userID, issue, ir, issueList, userID, completedAt)
+
+	if ir.ForeignUserID != "" && !l.sentToGroup(ir) {
+		foreignList, foreignIR, foreignN := l.store.GetIssueListAndReference(ir.ForeignUserID, ir.ForeignIssueID)
+		foreignIssue, foreignErr := l.store.GetIssue(ir.ForeignIssueID)
+		switch {
+		case foreignIR == nil:
+			l.api.LogError("cannot find foreigner reference after complete")
+		case foreignErr != nil:
+			l.api.LogError("cannot find foreigner issue after complete, Err=", foreignErr.Error())
+		default:
+			tombstone.ForeignIssue = copyIssue(foreignIssue)
+			tombstone.ForeignListID = foreignList
+			tombstone.ForeignPosition = foreignN
+			tombstone.ForeignRef = foreignIR
+			archiveSteps(entry, ir.ForeignUserID, foreignIssue, foreignIR, foreignList, userID, completedAt)
+		}
+	}
+
+	if err = l.runJournaled(entry); err != nil {
+		return nil, "", issueList, err
 	}
+	l.saveTombstone(userID, tombstone)
 
-	err = l.store.RemoveReference(ir.ForeignUserID, ir.ForeignIssueID, OutListKey)
+	return issue, ir.ForeignUserID, issueList, nil
+}
+
+// archiveSteps adds to entry the steps to move the reference ir of userID from listID to the done list,
+// and marks the issue as completed
+func archiveSteps(entry *JournalEntry, userID string, issue *Issue, ir *IssueRef, listID, completedBy string, completedAt int64) {
+	issue.CompletedAt = completedAt
+	issue.CompletedBy = completedBy
+	issue.SnoozedUntil = 0
+
+	entry.removeReference(userID, issue.ID, listID)
+	entry.insertReference(userID, DoneListKey, &IssueRef{
+		IssueID:        ir.IssueID,
+		ForeignIssueID: ir.ForeignIssueID,
+		ForeignUserID:  ir.ForeignUserID,
+		PreviousList:   listID,
+	}, 0)
+	entry.saveIssue(issue)
+}
+
+func (l *listManager) ReopenIssue(userID, issueID string) (issue *Issue, foreignID string, listToUpdate string, err error) {
+	ir, _, err := l.store.GetIssueReference(userID, issueID, DoneListKey)
 	if err != nil {
-		l.api.LogError("cannot clean foreigner list after complete, Err=", err.Error())
+		return nil, "", "", err
 	}
 
-	issue, err = l.store.GetAndRemoveIssue(ir.ForeignIssueID)
+	issue, err = l.store.GetIssue(issueID)
 	if err != nil {
-		l.api.LogError("cannot clean foreigner issue after complete, Err=", err.Error())
+		return nil, "", "", err
 	}
 
-	return issue, ir.ForeignUserID, issueList, nil
+	// The custom list the issue was completed from may have been deleted since
+	if l.existingListID(userID, ir.PreviousList) != ir.PreviousList {
+		return nil, "", "", ErrCannotReopen
+	}
+
+	nextUserID, nextIssueID := issue.NextUserID, issue.NextIssueID
+	entry := newJournalEntry(JournalReopen, userID)
+
+	if l.sentToGroup(ir) {
+		foreignID = ir.ForeignUserID
+	} else if ir.ForeignUserID != "" {
+		foreignIR, _, foreignErr := l.store.GetIssueReference(ir.ForeignUserID, ir.ForeignIssueID, DoneListKey)
+		if foreignErr != nil {
+			return nil, "", "", ErrCannotReopen
+		}
+		foreignIssue, foreignErr := l.store.GetIssue(ir.ForeignIssueID)
+		if foreignErr != nil {
+			return nil, "", "", ErrCannotReopen
+		}
+		if l.existingListID(ir.ForeignUserID, foreignIR.PreviousList) != foreignIR.PreviousList {
+			return nil, "", "", ErrCannotReopen
+		}
+		unarchiveSteps(entry, ir.ForeignUserID, foreignIssue, foreignIR)
+		foreignID = ir.ForeignUserID
+	}
+	unarchiveSteps(entry, userID, issue, ir)
+
+	if err = l.runJournaled(entry); err != nil {
+		return nil, "", "", err
+	}
+	l.removeNextOccurrence(nextUserID, nextIssueID)
+
+	return issue, foreignID, ir.PreviousList, nil
+}
+
+// unarchiveSteps adds to entry the steps to move the reference ir of userID from the done list back
+// to its previous list, and marks the issue as not completed
+func unarchiveSteps(entry *JournalEntry, userID string, issue *Issue, ir *IssueRef) {
+	issue.CompletedAt = 0
+	issue.CompletedBy = ""
+	issue.NextIssueID = ""
+	issue.NextUserID = ""
+
+	entry.removeReference(userID, issue.ID, DoneListKey)
+	entry.insertReference(userID, ir.PreviousList, &IssueRef{
+		IssueID:        ir.IssueID,
+		ForeignIssueID: ir.ForeignIssueID,
+		ForeignUserID:  ir.ForeignUserID,
+	}, -1)
+	entry.saveIssue(issue)
 }
 
-func (l *listManager) EditIssue(userID, issueID, newMessage, newDescription string) (foreignUserID, list, oldMessage string, err error) {
+func (l *listManager) EditIssue(userID, issueID string, update *IssueUpdate) (foreignUserID, list, oldMessage string, err error) {
 	issue, err := l.store.GetIssue(issueID)
 	if err != nil {
 		return "", "", "", err
@@ -196,12 +410,14 @@ func (l *listManager) EditIssue(userID, issueID, newMessage, newDescription stri
 		return "", "", "", errors.New("reference not found")
 	}
 
-	if ir.ForeignIssueID != "" {
+	foreignUserID = ir.ForeignUserID
+	if l.sentToGroup(ir) {
+		foreignUserID = ""
+	} else if ir.ForeignIssueID != "" {
 		foreignIssue, foreignErr := l.store.GetIssue(ir.ForeignIssueID)
 		if foreignErr == nil {
 			oldMessage = foreignIssue.Message
-			foreignIssue.Message = newMessage
-			foreignIssue.Description = newDescription
+			update.apply(foreignIssue)
 			foreignErr = l.store.SaveIssue(foreignIssue)
 			if foreignErr != nil {
 				l.api.LogError("cannot edit foreign issue after edit", "error", foreignErr.Error())
@@ -209,14 +425,52 @@ func (l *listManager) EditIssue(userID, issueID, newMessage, newDescription stri
 		}
 	}
 
-	issue.Message = newMessage
-	issue.Description = newDescription
+	update.apply(issue)
 	err = l.store.SaveIssue(issue)
 	if err != nil {
 		return "", "", "", err
 	}
 
-	return ir.ForeignUserID, list, oldMessage, nil
+	return foreignUserID, list, oldMessage, nil
+}
+
+func (l *listManager) UpdateChecklist(userID, issueID string, update *ChecklistUpdate) (issue *Issue, foreignUserID, list string, err error) {
+	list, ir, _ := l.store.GetIssueListAndReference(userID, issueID)
+	if ir == nil {
+		return nil, "", "", ErrIssueNotFound
+	}
+
+	issue, err = l.store.GetIssue(issueID)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	if err = update.apply(issue); err != nil {
+		return nil, "", "", err
+	}
+
+	if err = l.store.SaveIssue(issue); err != nil {
+		return nil, "", "", err
+	}
+
+	if l.sentToGroup(ir) {
+		return issue, "", list, nil
+	}
+
+	if ir.ForeignIssueID != "" {
+		foreignIssue, foreignErr := l.store.GetIssue(ir.ForeignIssueID)
+		if foreignErr == nil {
+			foreignErr = update.apply(foreignIssue)
+		}
+		if foreignErr == nil {
+			foreignErr = l.store.SaveIssue(foreignIssue)
+		}
+		if foreignErr != nil {
+			l.api.LogError("cannot update foreign issue checklist", "error", foreignErr.Error())
+		}
+	}
+
+	return issue, ir.ForeignUserID, list, nil
 }
 
 func (l *listManager) ChangeAssignment(issueID string, userID string, sendTo string) (issue *Issue, oldOwner string, err error) {
@@ -230,10 +484,16 @@ func (l *listManager) ChangeAssignment(issueID string, userID string, sendTo str
 		return nil, "", errors.New("reference not found")
 	}
 
-	if (list == InListKey) || (ir.ForeignIssueID != "" && list == MyListKey) {
+	if (list == InListKey) || (ir.ForeignIssueID != "" && list != OutListKey) {
 		return nil, "", errors.New("trying to change the assignment of a todo not owned")
 	}
 
+	if len(issue.Recipients) > 0 {
+		return nil, "", ErrSentToGroup
+	}
+
+	entry := newJournalEntry(JournalChangeAssignment, userID)
+
 	if ir.ForeignUserID != "" {
 		// Remove reference from foreign user
 		foreignList, foreignIR, _ := l.store.GetIssueListAndReference(ir.ForeignUserID, ir.ForeignIssueID)
@@ -241,22 +501,15 @@ func (l *listManager) ChangeAssignment(issueID string, userID string, sendTo str
 			return nil, "", errors.New("reference not found")
 		}
 
-		if err := l.store.RemoveReference(ir.ForeignUserID, ir.ForeignIssueID, foreignList); err != nil {
-			return nil, "", err
-		}
-
-		_, err := l.store.GetAndRemoveIssue(ir.ForeignIssueID)
-		if err != nil {
-			l.api.LogError("cannot remove issue", "err", err.Error())
-		}
+		entry.removeReference(ir.ForeignUserID, ir.ForeignIssueID, foreignList)
+		entry.removeIssue(ir.ForeignUserID, ir.ForeignIssueID)
 	}
 
 	if userID == sendTo && list == OutListKey {
-		if err := l.store.RemoveReference(userID, issueID, OutListKey); err != nil {
-			return nil, "", err
-		}
+		entry.removeReference(userID, issueID, OutListKey)
+		entry.insertReference(userID, MyListKey, &IssueRef{IssueID: issueID}, -1)
 
-		if err := l.store.AddReference(userID, issueID, MyListKey, "", ""); err != nil {
+		if err := l.runJournaled(entry); err != nil {
 			return nil, "", err
 		}
 
@@ -264,21 +517,26 @@ func (l *listManager) ChangeAssignment(issueID string, userID string, sendTo str
 	}
 
 	if userID != sendTo {
-		if err := l.store.RemoveReference(userID, issueID, list); err != nil {
-			return nil, "", err
-		}
-	}
-
-	receiverIssue := newIssue(issue.Message, issue.PostPermalink, issue.Description, issue.PostID)
-	if err := l.store.SaveIssue(receiverIssue); err != nil {
-		return nil, "", err
+		entry.removeReference(userID, issueID, list)
 	}
 
-	if err := l.store.AddReference(userID, issueID, OutListKey, sendTo, receiverIssue.ID); err != nil {
+	receiverIssue := newForeignIssue(issue)
+	if err := l.assignShortID(sendTo, receiverIssue); err != nil {
 		return nil, "", err
 	}
-
-	if err := l.store.AddReference(sendTo, receiverIssue.ID, InListKey, userID, issue.ID); err != nil {
+	entry.saveIssue(receiverIssue)
+	entry.insertReference(userID, OutListKey, &IssueRef{
+		IssueID:        issueID,
+		ForeignIssueID: receiverIssue.ID,
+		ForeignUserID:  sendTo,
+	}, -1)
+	entry.insertReference(sendTo, InListKey, &IssueRef{
+		IssueID:        receiverIssue.ID,
+		ForeignIssueID: issue.ID,
+		ForeignUserID:  userID,
+	}, -1)
+
+	if err := l.runJournaled(entry); err != nil {
 		return nil, "", err
 	}
 
@@ -320,65 +578,191 @@ func (l *listManager) AcceptIssue(userID, issueID string) (todoMessage string, f
 }
 
 func (l *listManager) RemoveIssue(userID, issueID string) (outIssue *Issue, foreignID string, isSender bool, listToUpdate string, outErr error) {
-	issueList, ir, _ := l.store.GetIssueListAndReference(userID, issueID)
-	if ir == nil {
-		return nil, "", false, issueList, fmt.Errorf("cannot find element")
+	issue, foreignID, isSender, listToUpdate, tombstone, err := l.removeIssue(userID, issueID)
+	if err != nil {
+		return nil, "", false, listToUpdate, err
 	}
 
-	if err := l.store.RemoveReference(userID, issueID, issueList); err != nil {
-		return nil, "", false, issueList, err
+	l.saveTombstone(userID, tombstone)
+	return issue, foreignID, isSender, listToUpdate, nil
+}
+
+func (l *listManager) DiscardIssue(userID, issueID string) (issue *Issue, foreignID string, isSender bool, listToUpdate string, err error) {
+	issue, foreignID, isSender, listToUpdate, _, err = l.removeIssue(userID, issueID)
+	return issue, foreignID, isSender, listToUpdate, err
+}
+
+// removeIssue removes the todo issueID for userID as RemoveIssue does, returning the tombstone to undo it
+// instead of saving it
+func (l *listManager) removeIssue(userID, issueID string) (outIssue *Issue, foreignID string, isSender bool, listToUpdate string, tombstone *Tombstone, outErr error) {
+	issueList, ir, n := l.store.GetIssueListAndReference(userID, issueID)
+	if ir == nil {
+		return nil, "", false, issueList, nil, fmt.Errorf("cannot find element")
 	}
 
-	issue, err := l.store.GetAndRemoveIssue(issueID)
+	issue, err := l.store.GetIssue(issueID)
 	if err != nil {
-		l.api.LogError("cannot remove issue, Err=", err.Error())
+		l.api.LogError("cannot find issue to remove, Err=", err.Error())
 	}
 
-	if ir.ForeignUserID == "" {
-		return issue, "", false, issueList, nil
+	tombstone = &Tombstone{
+		Action:   TombstoneRemove,
+		Issue:    issue,
+		ListID:   issueList,
+		Position: n,
+		Ref:      ir,
 	}
 
-	list, _, _ := l.store.GetIssueListAndReference(ir.ForeignUserID, ir.ForeignIssueID)
+	entry := newJournalEntry(JournalRemove, userID)
+	entry.removeReference(userID, issueID, issueList)
+	entry.removeIssue(userID, issueID)
 
-	err = l.store.RemoveReference(ir.ForeignUserID, ir.ForeignIssueID, list)
-	if err != nil {
-		l.api.LogError("cannot clean foreigner list after remove, Err=", err.Error())
+	// Removing a todo sent to several users takes it back from all of them, while each of them
+	// only declines their own copy
+	if issue != nil && len(issue.Recipients) > 0 {
+		tombstone.Recipients = l.removeRecipientsSteps(entry, issue)
+	}
+	if ir.ForeignUserID == "" || l.sentToGroup(ir) {
+		if err = l.runJournaled(entry); err != nil {
+			return nil, "", false, issueList, nil, err
+		}
+		if ir.ForeignUserID != "" {
+			tombstone.Action = TombstoneDecline
+		}
+		return issue, ir.ForeignUserID, ir.ForeignUserID != "", issueList, tombstone, nil
 	}
 
-	issue, err = l.store.GetAndRemoveIssue(ir.ForeignIssueID)
+	list, foreignIR, foreignN := l.store.GetIssueListAndReference(ir.ForeignUserID, ir.ForeignIssueID)
+	if foreignIR != nil {
+		entry.removeReference(ir.ForeignUserID, ir.ForeignIssueID, list)
+	} else {
+		l.api.LogError("cannot find foreigner reference to remove")
+	}
+
+	foreignIssue, err := l.store.GetIssue(ir.ForeignIssueID)
 	if err != nil {
-		l.api.LogError("cannot clean foreigner issue after remove, Err=", err.Error())
+		l.api.LogError("cannot find foreigner issue to remove, Err=", err.Error())
+	}
+	entry.removeIssue(ir.ForeignUserID, ir.ForeignIssueID)
+
+	if err = l.runJournaled(entry); err != nil {
+		return nil, "", false, issueList, nil, err
+	}
+
+	if list == OutListKey {
+		tombstone.Action = TombstoneDecline
+	}
+	if foreignIR != nil {
+		tombstone.ForeignIssue = foreignIssue
+		tombstone.ForeignListID = list
+		tombstone.ForeignPosition = foreignN
+		tombstone.ForeignRef = foreignIR
 	}
 
-	return issue, ir.ForeignUserID, list == OutListKey, issueList, nil
+	if foreignIssue != nil {
+		issue = foreignIssue
+	}
+
+	return issue, ir.ForeignUserID, list == OutListKey, issueList, tombstone, nil
 }
 
 func (l *listManager) PopIssue(userID string) (issue *Issue, foreignID string, err error) {
-	ir, err := l.store.PopReference(userID, MyListKey)
+	issues, err := l.GetIssueList(userID, MyListKey)
 	if err != nil {
 		return nil, "", err
 	}
 
-	if ir == nil {
-		return nil, "", errors.New("unexpected nil for issue reference")
+	issues = awakeIssues(issues, model.GetMillis())
+	if len(issues) == 0 {
+		return nil, "", ErrIssueNotFound
 	}
 
-	issue, err = l.store.GetAndRemoveIssue(ir.IssueID)
+	return l.popListIssue(userID, issues[0].ID)
+}
+
+func (l *listManager) PopHighestPriorityIssue(userID string) (issue *Issue, foreignID string, err error) {
+	issues, err := l.GetIssueList(userID, MyListKey)
 	if err != nil {
-		l.api.LogError("cannot remove issue after pop, Err=", err.Error())
+		return nil, "", err
 	}
 
-	if ir.ForeignUserID == "" {
-		return issue, "", nil
+	issues = awakeIssues(issues, model.GetMillis())
+	if len(issues) == 0 {
+		return nil, "", ErrIssueNotFound
 	}
 
-	err = l.store.RemoveReference(ir.ForeignUserID, ir.ForeignIssueID, OutListKey)
+	sortIssuesByPriority(issues)
+
+	return l.popListIssue(userID, issues[0].ID)
+}
+
+// popListIssue removes issueID from the my list of userID as popped
+func (l *listManager) popListIssue(userID, issueID string) (issue *Issue, foreignID string, err error) {
+	ir, n, err := l.store.GetIssueReference(userID, issueID, MyListKey)
 	if err != nil {
-		l.api.LogError("cannot clean foreigner list after pop, Err=", err.Error())
+		return nil, "", err
+	}
+
+	if err = l.store.RemoveReference(userID, ir.IssueID, MyListKey); err != nil {
+		return nil, "", err
 	}
-	issue, err = l.store.GetAndRemoveIssue(ir.ForeignIssueID)
+
+	return l.removePoppedIssue(userID, ir, n)
+}
+
+// removePoppedIssue removes the issue of a reference already popped from position n, and its foreign counterpart if any
+func (l *listManager) removePoppedIssue(userID string, ir *IssueRef, n int) (issue *Issue, foreignID string, err error) {
+	issue, err = l.store.GetIssue(ir.IssueID)
 	if err != nil {
-		l.api.LogError("cannot clean foreigner issue after pop, Err=", err.Error())
+		l.api.LogError("cannot find issue after pop, Err=", err.Error())
+	}
+
+	tombstone := &Tombstone{
+		Action:   TombstonePop,
+		Issue:    issue,
+		ListID:   MyListKey,
+		Position: n,
+		Ref:      ir,
+	}
+
+	entry := newJournalEntry(JournalPop, userID)
+	entry.removeIssue(userID, ir.IssueID)
+
+	if ir.ForeignUserID == "" || l.sentToGroup(ir) {
+		if err = l.runJournaled(entry); err != nil {
+			return nil, "", err
+		}
+		l.saveTombstone(userID, tombstone)
+		return issue, ir.ForeignUserID, nil
+	}
+
+	foreignIR, foreignN, _ := l.store.GetIssueReference(ir.ForeignUserID, ir.ForeignIssueID, OutListKey)
+	if foreignIR != nil {
+		entry.removeReference(ir.ForeignUserID, ir.ForeignIssueID, OutListKey)
+	} else {
+		l.api.LogError("cannot find foreigner reference after pop")
+	}
+
+	foreignIssue, err := l.store.GetIssue(ir.ForeignIssueID)
+	if err != nil {
+		l.api.LogError("cannot find foreigner issue after pop, Err=", err.Error())
+	}
+	entry.removeIssue(ir.ForeignUserID, ir.ForeignIssueID)
+
+	if err = l.runJournaled(entry); err != nil {
+		return nil, "", err
+	}
+
+	if foreignIR != nil {
+		tombstone.ForeignIssue = foreignIssue
+		tombstone.ForeignListID = OutListKey
+		tombstone.ForeignPosition = foreignN
+		tombstone.ForeignRef = foreignIR
+	}
+	l.saveTombstone(userID, tombstone)
+
+	if foreignIssue != nil {
+		issue = foreignIssue
 	}
 
 	return issue, ir.ForeignUserID, nil
@@ -394,6 +778,10 @@ func (l *listManager) BumpIssue(userID, issueID string) (todo *Issue, receiver s
 		return nil, "", "", fmt.Errorf("cannot find sender issue")
 	}
 
+	if ir.ForeignUserID == "" {
+		return nil, "", "", ErrSentToGroup
+	}
+
 	err = l.store.BumpReference(ir.ForeignUserID, ir.ForeignIssueID, InListKey)
 	if err != nil {
 		return nil, "", "", err
@@ -408,6 +796,295 @@ func (l *listManager) BumpIssue(userID, issueID string) (todo *Issue, receiver s
 	return issue, ir.ForeignUserID, ir.ForeignIssueID, nil
 }
 
+func (l *listManager) MoveIssue(userID, issueID string, position int) (string, error) {
+	listID, ir, _ := l.store.GetIssueListAndReference(userID, issueID)
+	if ir == nil {
+		return "", ErrIssueNotFound
+	}
+
+	if err := l.store.MoveReference(userID, issueID, listID, position); err != nil {
+		return "", err
+	}
+
+	return listID, nil
+}
+
+func (l *listManager) ReorderIssues(userID, listID string, issueIDs []string) error {
+	switch {
+	case listID == MyListKey, listID == InListKey, listID == OutListKey:
+	case isCustomListKey(listID) && l.existingListID(userID, listID) == listID:
+	default:
+		return ErrListNotFound
+	}
+
+	return l.store.ReorderReferences(userID, listID, issueIDs)
+}
+
+func (l *listManager) SnoozeIssue(userID, issueID string, until int64) (*Issue, error) {
+	listID, ir, _ := l.store.GetIssueListAndReference(userID, issueID)
+	if ir == nil {
+		return nil, ErrIssueNotFound
+	}
+	if listID != MyListKey && listID != InListKey && !isCustomListKey(listID) {
+		return nil, ErrCannotSnooze
+	}
+
+	issue, err := l.store.GetIssue(issueID)
+	if err != nil {
+		return nil, err
+	}
+
+	issue.SnoozedUntil = until
+	if err = l.store.SaveIssue(issue); err != nil {
+		return nil, err
+	}
+
+	return issue, nil
+}
+
+func (l *listManager) WakeIssue(userID, issueID string) (issue *Issue, listID string, err error) {
+	listID, ir, _ := l.store.GetIssueListAndReference(userID, issueID)
+	if ir == nil {
+		return nil, "", ErrIssueNotFound
+	}
+
+	issue, err = l.store.GetIssue(issueID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if listID == MyListKey || listID == InListKey || isCustomListKey(listID) {
+		if err = l.store.BumpReference(userID, issueID, listID); err != nil {
+			return nil, "", err
+		}
+	}
+
+	issue.SnoozedUntil = 0
+	if err = l.store.SaveIssue(issue); err != nil {
+		return nil, "", err
+	}
+
+	return issue, listID, nil
+}
+
+func (l *listManager) UndoLastAction(userID string) (*Tombstone, error) {
+	tombstone, err := l.store.GetAndRemoveTombstone(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tombstone == nil || tombstone.Issue == nil || tombstone.Ref == nil {
+		return nil, ErrNothingToUndo
+	}
+
+	// Stores without expiring keys may keep the tombstone for longer
+	if model.GetMillis()-tombstone.CreateAt > UndoExpirySeconds*1000 {
+		return nil, ErrNothingToUndo
+	}
+
+	if err = l.restoreIssue(userID, tombstone.Action, tombstone.Issue, tombstone.ListID, tombstone.Ref, tombstone.Position); err != nil {
+		return nil, err
+	}
+	l.removeNextOccurrence(tombstone.NextUserID, tombstone.NextIssueID)
+
+	for _, recipient := range tombstone.Recipients {
+		err = l.restoreIssue(recipient.UserID, tombstone.Action, recipient.Issue, recipient.ListID, recipient.Ref, recipient.Position)
+		if err != nil {
+			l.api.LogError("cannot restore recipient issue after undo, Err=", err.Error())
+		}
+	}
+
+	if tombstone.ForeignIssue == nil || tombstone.ForeignRef == nil {
+		return tombstone, nil
+	}
+
+	err = l.restoreIssue(tombstone.Ref.ForeignUserID, tombstone.Action, tombstone.ForeignIssue, tombstone.ForeignListID, tombstone.ForeignRef, tombstone.ForeignPosition)
+	if err != nil {
+		l.api.LogError("cannot restore foreigner issue after undo, Err=", err.Error())
+	}
+
+	return tombstone, nil
+}
+
+func (l *listManager) SetNextOccurrence(userID string, issue *Issue, nextUserID, nextIssueID string) error {
+	// The completed issue and its counterpart are on the done lists, while popped ones are gone
+	issueIDs := []string{}
+	if ir, _, err := l.store.GetIssueReference(userID, issue.ID, DoneListKey); err == nil {
+		issueIDs = append(issueIDs, ir.IssueID)
+		if ir.ForeignIssueID != "" && !l.sentToGroup(ir) {
+			issueIDs = append(issueIDs, ir.ForeignIssueID)
+		}
+	}
+
+	for _, issueID := range issueIDs {
+		doneIssue, err := l.store.GetIssue(issueID)
+		if err != nil {
+			return err
+		}
+		doneIssue.NextIssueID = nextIssueID
+		doneIssue.NextUserID = nextUserID
+		if err = l.store.SaveIssue(doneIssue); err != nil {
+			return err
+		}
+	}
+
+	tombstone, err := l.store.GetAndRemoveTombstone(userID)
+	if err != nil || tombstone == nil {
+		return err
+	}
+
+	if tombstone.Issue != nil && tombstone.Issue.ID == issue.ID || tombstone.ForeignIssue != nil && tombstone.ForeignIssue.ID == issue.ID {
+		tombstone.NextIssueID = nextIssueID
+		tombstone.NextUserID = nextUserID
+	}
+
+	// The tombstone is put back even if it is of a later operation
+	return l.store.SaveTombstone(userID, tombstone)
+}
+
+// removeNextOccurrence removes the issue nextIssueID created on completing a repeating issue, if it is
+// still open, without saving a tombstone for it
+func (l *listManager) removeNextOccurrence(nextUserID, nextIssueID string) {
+	if nextIssueID == "" {
+		return
+	}
+
+	if _, ir, _ := l.store.GetIssueListAndReference(nextUserID, nextIssueID); ir == nil {
+		return
+	}
+
+	if _, _, _, _, _, err := l.removeIssue(nextUserID, nextIssueID); err != nil {
+		l.api.LogError("cannot remove the next occurrence of a repeating issue, Err=", err.Error())
+	}
+}
+
+// restoreIssue stores again the issue and its reference ir at position in listID for userID,
+// taking it out of the done list first if it was completed
+func (l *listManager) restoreIssue(userID, action string, issue *Issue, listID string, ir *IssueRef, position int) error {
+	if action == TombstoneComplete {
+		if err := l.store.RemoveReference(userID, issue.ID, DoneListKey); err != nil {
+			return err
+		}
+	}
+
+	if err := l.store.SaveIssue(issue); err != nil {
+		return err
+	}
+
+	if issue.ShortID != 0 {
+		if err := l.store.SaveShortID(userID, issue.ShortID, issue.ID); err != nil {
+			return err
+		}
+	}
+
+	// The custom list the issue was on may have been deleted since
+	if existingListID := l.existingListID(userID, listID); existingListID != listID {
+		listID, position = existingListID, -1
+	}
+
+	if err := l.store.InsertReference(userID, listID, ir, position); err != nil {
+		if action != TombstoneComplete {
+			if rollbackError := l.store.RemoveIssue(userID, issue.ID); rollbackError != nil {
+				l.api.LogError("cannot rollback issue after undo error, Err=", rollbackError.Error())
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (l *listManager) saveTombstone(userID string, tombstone *Tombstone) {
+	if tombstone.Issue == nil {
+		return
+	}
+
+	tombstone.CreateAt = model.GetMillis()
+	if err := l.store.SaveTombstone(userID, tombstone); err != nil {
+		l.api.LogError("cannot save undo information, Err=", err.Error())
+	}
+}
+
+func (l *listManager) ResolveIssueID(userID, ref string) (string, error) {
+	shortID, ok := parseShortID(ref)
+	if !ok {
+		return ref, nil
+	}
+
+	return l.store.GetIssueIDByShortID(userID, shortID)
+}
+
+// assignShortID allocates a short ID of userID for issue, unless it already has one
+func (l *listManager) assignShortID(userID string, issue *Issue) error {
+	if issue.ShortID != 0 {
+		return nil
+	}
+
+	shortID, err := l.store.NextShortID(userID)
+	if err != nil {
+		return err
+	}
+
+	issue.ShortID = shortID
+	return l.store.SaveShortID(userID, shortID, issue.ID)
+}
+
+func (l *listManager) BackfillShortIDs(userID string) (int, error) {
+	customLists, err := l.store.GetCustomLists(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	listIDs := []string{MyListKey, InListKey, OutListKey, DoneListKey}
+	for _, list := range customLists {
+		listIDs = append(listIDs, list.ID)
+	}
+
+	assigned := 0
+	for _, listID := range listIDs {
+		irs, err := l.store.GetList(userID, listID)
+		if err != nil {
+			return assigned, err
+		}
+
+		for _, ir := range irs {
+			issue, err := l.store.GetIssue(ir.IssueID)
+			if err != nil || issue.ShortID != 0 {
+				continue
+			}
+
+			if err = l.assignShortID(userID, issue); err != nil {
+				return assigned, err
+			}
+			if err = l.store.SaveIssue(issue); err != nil {
+				return assigned, err
+			}
+			assigned++
+		}
+	}
+
+	return assigned, nil
+}
+
+// parseShortID returns the short ID in ref, written as a number optionally prefixed by #
+func parseShortID(ref string) (int64, bool) {
+	ref = strings.TrimPrefix(ref, "#")
+	if len(ref) == 0 || len(ref) >= 26 {
+		return 0, false
+	}
+
+	shortID, err := strconv.ParseInt(ref, 10, 64)
+	if err != nil || shortID <= 0 {
+		return 0, false
+	}
+
+	return shortID, true
+}
+
+func (l *listManager) GetUserIDs() ([]string, error) {
+	return l.store.GetUserIDs()
+}
+
 func (l *listManager) GetUserName(userID string) string {
 	user, err := l.api.GetUser(userID)
 	if err != nil {
@@ -425,11 +1102,28 @@ func (l *listManager) extendIssueInfo(issue *Issue, ir *IssueRef) *ExtendedIssue
 		Issue: *issue,
 	}
 
+	if issue.CompletedBy != "" {
+		feIssue.CompletedByUser = l.GetUserName(issue.CompletedBy)
+	}
+
+	if issue.ClaimedBy != "" {
+		feIssue.ClaimedByUser = l.GetUserName(issue.ClaimedBy)
+	}
+
+	if len(issue.Recipients) > 0 {
+		feIssue.RecipientStatuses = l.recipientStatuses(issue)
+	}
+
 	if ir.ForeignUserID == "" {
 		return feIssue
 	}
 
-	list, _, n := l.store.GetIssueListAndReference(ir.ForeignUserID, ir.ForeignIssueID)
+	list, foreignIR, n := l.store.GetIssueListAndReference(ir.ForeignUserID, ir.ForeignIssueID)
+	if foreignIR == nil {
+		if doneIR, doneN, _ := l.store.GetIssueReference(ir.ForeignUserID, ir.ForeignIssueID, DoneListKey); doneIR != nil {
+			list, n = DoneListKey, doneN
+		}
+	}
 
 	var listName string
 	switch list {
@@ -439,6 +1133,8 @@ func (l *listManager) extendIssueInfo(issue *Issue, ir *IssueRef) *ExtendedIssue
 		listName = InFlag
 	case OutListKey:
 		listName = OutFlag
+	case DoneListKey:
+		listName = DoneFlag
 	}
 
 	userName := l.GetUserName(ir.ForeignUserID)
@@ -0,0 +1,626 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShortIDs(t *testing.T) {
+	senderID, receiverID := model.NewId(), model.NewId()
+	store := newMemStore()
+	l := &listManager{store: store, api: &plugintest.API{}}
+
+	own := newIssue("own", "", "", "")
+	require.NoError(t, l.AddIssue(senderID, own))
+	assert.EqualValues(t, 1, own.ShortID)
+
+	sent := newIssue("sent", "", "", "")
+	receiverIssueID, err := l.SendIssue(senderID, receiverID, sent)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, sent.ShortID)
+	assert.EqualValues(t, 1, store.issues[receiverIssueID].ShortID)
+
+	t.Run("short IDs are resolved on the lists of the user", func(t *testing.T) {
+		issueID, err := l.ResolveIssueID(senderID, "#2")
+		require.NoError(t, err)
+		assert.Equal(t, sent.ID, issueID)
+
+		issueID, err = l.ResolveIssueID(receiverID, "1")
+		require.NoError(t, err)
+		assert.Equal(t, receiverIssueID, issueID)
+
+		_, err = l.ResolveIssueID(receiverID, "#2")
+		assert.ErrorIs(t, err, ErrIssueNotFound)
+	})
+
+	t.Run("full IDs are left untouched", func(t *testing.T) {
+		issueID, err := l.ResolveIssueID(senderID, own.ID)
+		require.NoError(t, err)
+		assert.Equal(t, own.ID, issueID)
+	})
+
+	t.Run("short ID is kept when accepting", func(t *testing.T) {
+		_, _, err := l.AcceptIssue(receiverID, receiverIssueID)
+		require.NoError(t, err)
+
+		issueID, err := l.ResolveIssueID(receiverID, "#1")
+		require.NoError(t, err)
+		assert.Equal(t, receiverIssueID, issueID)
+	})
+
+	t.Run("short ID is removed with the issue", func(t *testing.T) {
+		_, _, _, _, err := l.RemoveIssue(senderID, own.ID)
+		require.NoError(t, err)
+
+		_, err = l.ResolveIssueID(senderID, "#1")
+		assert.ErrorIs(t, err, ErrIssueNotFound)
+
+		_, err = l.UndoLastAction(senderID)
+		require.NoError(t, err)
+
+		issueID, err := l.ResolveIssueID(senderID, "#1")
+		require.NoError(t, err)
+		assert.Equal(t, own.ID, issueID)
+	})
+
+	t.Run("issues without short ID are backfilled", func(t *testing.T) {
+		legacy := newIssue("legacy", "", "", "")
+		store.issues[legacy.ID] = legacy
+		store.lists[listKey(senderID, MyListKey)] = append(store.lists[listKey(senderID, MyListKey)], &IssueRef{IssueID: legacy.ID})
+
+		// Reading the lists leaves them untouched
+		issues, err := l.GetIssueList(senderID, MyListKey)
+		require.NoError(t, err)
+		require.Len(t, issues, 2)
+		assert.Zero(t, issues[1].ShortID)
+
+		assigned, err := l.BackfillShortIDs(senderID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, assigned)
+		assert.EqualValues(t, 3, store.issues[legacy.ID].ShortID)
+
+		assigned, err = l.BackfillShortIDs(senderID)
+		require.NoError(t, err)
+		assert.Zero(t, assigned)
+	})
+}
+
+func TestSnooze(t *testing.T) {
+	senderID, receiverID := model.NewId(), model.NewId()
+	store := newMemStore()
+	api := &plugintest.API{}
+	api.On("GetUser", mock.Anything).Return(&model.User{Username: "someone"}, nil)
+	l := &listManager{store: store, api: api}
+
+	first := newIssue("first", "", "", "")
+	second := newIssue("second", "", "", "")
+	third := newIssue("third", "", "", "")
+	for _, issue := range []*Issue{first, second, third} {
+		require.NoError(t, l.AddIssue(receiverID, issue))
+	}
+	sent := newIssue("sent", "", "", "")
+	_, err := l.SendIssue(senderID, receiverID, sent)
+	require.NoError(t, err)
+
+	later := model.GetMillis() + 60*60*1000
+
+	t.Run("snoozed issues are hidden", func(t *testing.T) {
+		_, err := l.SnoozeIssue(receiverID, first.ID, later)
+		require.NoError(t, err)
+
+		lists, err := l.GetAllList(receiverID)
+		require.NoError(t, err)
+		require.Len(t, lists.My, 2)
+		assert.Equal(t, second.ID, lists.My[0].ID)
+
+		issues, err := l.GetIssueList(receiverID, MyListKey)
+		require.NoError(t, err)
+		assert.Len(t, issues, 3)
+		assert.NotContains(t, issuesListToString(issues, time.UTC), "first")
+		assert.Contains(t, issuesListToString(issues, time.UTC), "* `2` #2 second")
+	})
+
+	t.Run("snoozed issues are not popped", func(t *testing.T) {
+		issue, _, err := l.PopIssue(receiverID)
+		require.NoError(t, err)
+		assert.Equal(t, second.ID, issue.ID)
+	})
+
+	t.Run("sent issues cannot be snoozed", func(t *testing.T) {
+		_, err := l.SnoozeIssue(senderID, sent.ID, later)
+		assert.ErrorIs(t, err, ErrCannotSnooze)
+	})
+
+	t.Run("woken issues move to the top of the list", func(t *testing.T) {
+		_, err := l.SnoozeIssue(receiverID, third.ID, later)
+		require.NoError(t, err)
+
+		issue, listID, err := l.WakeIssue(receiverID, third.ID)
+		require.NoError(t, err)
+		assert.Equal(t, MyListKey, listID)
+		assert.Zero(t, issue.SnoozedUntil)
+
+		issues, err := l.GetIssueList(receiverID, MyListKey)
+		require.NoError(t, err)
+		require.Len(t, issues, 2)
+		assert.Equal(t, third.ID, issues[0].ID)
+		assert.Equal(t, first.ID, issues[1].ID)
+	})
+}
+
+func TestUpdateChecklist(t *testing.T) {
+	senderID, receiverID := model.NewId(), model.NewId()
+	store := newMemStore()
+	l := &listManager{store: store, api: &plugintest.API{}}
+
+	sent := newIssue("trip", "", "", "")
+	receiverIssueID, err := l.SendIssue(senderID, receiverID, sent)
+	require.NoError(t, err)
+
+	add := newChecklistAdd("flights")
+	_, foreignUserID, list, err := l.UpdateChecklist(senderID, sent.ID, add)
+	require.NoError(t, err)
+	assert.Equal(t, receiverID, foreignUserID)
+	assert.Equal(t, OutListKey, list)
+
+	issue, foreignUserID, list, err := l.UpdateChecklist(receiverID, receiverIssueID, &ChecklistUpdate{Action: ChecklistToggle, ItemID: add.ItemID})
+	require.NoError(t, err)
+	assert.Equal(t, senderID, foreignUserID)
+	assert.Equal(t, InListKey, list)
+	assert.Equal(t, "trip (1/1)", issueSummary(issue))
+
+	senderIssue, err := store.GetIssue(sent.ID)
+	require.NoError(t, err)
+	require.Len(t, senderIssue.Checklist, 1)
+	assert.True(t, senderIssue.Checklist[0].Done)
+
+	_, _, _, err = l.UpdateChecklist(receiverID, sent.ID, add)
+	assert.ErrorIs(t, err, ErrIssueNotFound)
+}
+
+func TestCustomLists(t *testing.T) {
+	userID, receiverID := model.NewId(), model.NewId()
+	store := newMemStore()
+	l := &listManager{store: store, api: &plugintest.API{}}
+
+	work, err := l.CreateList(userID, "  Work   stuff ")
+	require.NoError(t, err)
+	assert.Equal(t, "Work stuff", work.Name)
+	assert.True(t, isCustomListKey(work.ID))
+
+	_, err = l.CreateList(userID, "work STUFF")
+	assert.ErrorIs(t, err, ErrListExists)
+	for _, name := range []string{"", "In", "42", "#work", "--tag"} {
+		_, err = l.CreateList(userID, name)
+		assert.ErrorIs(t, err, ErrInvalidListName, name)
+	}
+
+	home, err := l.CreateList(userID, "home")
+	require.NoError(t, err)
+	require.NoError(t, l.MoveList(userID, home.ID, 0))
+	lists, err := l.GetCustomLists(userID)
+	require.NoError(t, err)
+	require.Len(t, lists, 2)
+	assert.Equal(t, []string{home.ID, work.ID}, []string{lists[0].ID, lists[1].ID})
+
+	own := newIssue("report", "", "", "")
+	require.NoError(t, l.AddIssue(userID, own))
+	sent := newIssue("review", "", "", "")
+	_, err = l.SendIssue(userID, receiverID, sent)
+	require.NoError(t, err)
+
+	from, err := l.MoveIssueToList(userID, own.ID, work.ID)
+	require.NoError(t, err)
+	assert.Equal(t, MyListKey, from)
+	_, err = l.MoveIssueToList(userID, sent.ID, work.ID)
+	assert.ErrorIs(t, err, ErrCannotMove)
+
+	listID, ir, _ := store.GetIssueListAndReference(userID, own.ID)
+	require.NotNil(t, ir)
+	assert.Equal(t, work.ID, listID)
+
+	_, _, _, err = l.CompleteIssue(userID, own.ID)
+	require.NoError(t, err)
+	_, moved, err := l.DeleteList(userID, work.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, moved)
+
+	_, _, _, err = l.ReopenIssue(userID, own.ID)
+	assert.ErrorIs(t, err, ErrCannotReopen)
+
+	other := newIssue("plan", "", "", "")
+	require.NoError(t, l.AddIssue(userID, other))
+	_, err = l.MoveIssueToList(userID, other.ID, home.ID)
+	require.NoError(t, err)
+	_, moved, err = l.DeleteList(userID, home.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, moved)
+	listID, _, _ = store.GetIssueListAndReference(userID, other.ID)
+	assert.Equal(t, MyListKey, listID)
+
+	lists, err = l.GetCustomLists(userID)
+	require.NoError(t, err)
+	assert.Empty(t, lists)
+	_, _, err = l.DeleteList(userID, home.ID)
+	assert.ErrorIs(t, err, ErrListNotFound)
+}
+
+func TestReorderIssues(t *testing.T) {
+	userID := model.NewId()
+	store := newMemStore()
+	l := &listManager{store: store, api: &plugintest.API{}}
+
+	issues := map[string]*Issue{}
+	for _, message := range []string{"a", "b", "c", "d"} {
+		issues[message] = newIssue(message, "", "", "")
+		require.NoError(t, l.AddIssue(userID, issues[message]))
+	}
+	order := func() string {
+		var messages string
+		irs, err := store.GetList(userID, MyListKey)
+		require.NoError(t, err)
+		for _, ir := range irs {
+			messages += store.issues[ir.IssueID].Message
+		}
+		return messages
+	}
+
+	listID, err := l.MoveIssue(userID, issues["d"].ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, MyListKey, listID)
+	assert.Equal(t, "adbc", order())
+
+	_, err = l.MoveIssue(userID, issues["a"].ID, 10)
+	require.NoError(t, err)
+	assert.Equal(t, "dbca", order())
+
+	require.NoError(t, l.ReorderIssues(userID, MyListKey, []string{issues["c"].ID, issues["a"].ID}))
+	assert.Equal(t, "cadb", order())
+
+	err = l.ReorderIssues(userID, MyListKey, []string{issues["b"].ID, issues["b"].ID})
+	assert.ErrorIs(t, err, ErrInvalidOrder)
+	err = l.ReorderIssues(userID, InListKey, []string{issues["b"].ID})
+	assert.ErrorIs(t, err, ErrInvalidOrder)
+	err = l.ReorderIssues(userID, newCustomListKey(), []string{issues["b"].ID})
+	assert.ErrorIs(t, err, ErrListNotFound)
+	assert.Equal(t, "cadb", order())
+
+	_, err = l.MoveIssue(userID, model.NewId(), 0)
+	assert.ErrorIs(t, err, ErrIssueNotFound)
+}
+
+func TestListStoreReorderReferences(t *testing.T) {
+	userID := model.NewId()
+	a, b, c := &IssueRef{IssueID: model.NewId()}, &IssueRef{IssueID: model.NewId()}, &IssueRef{IssueID: model.NewId()}
+	jsonList, err := json.Marshal([]*IssueRef{a, b, c})
+	require.NoError(t, err)
+	newJSONList, err := json.Marshal([]*IssueRef{c, a, b})
+	require.NoError(t, err)
+
+	api := &plugintest.API{}
+	api.On("KVGet", listKey(userID, MyListKey)).Return(jsonList, nil)
+	api.On("KVCompareAndSet", listKey(userID, MyListKey), jsonList, newJSONList).Return(true, nil).Once()
+	defer api.AssertExpectations(t)
+
+	store := NewListStore(api)
+	require.NoError(t, store.ReorderReferences(userID, MyListKey, []string{c.IssueID, a.IssueID}))
+
+	err = store.ReorderReferences(userID, MyListKey, []string{c.IssueID, model.NewId()})
+	assert.ErrorIs(t, err, ErrInvalidOrder)
+}
+
+func TestUndoLastAction(t *testing.T) {
+	userID, foreignID := model.NewId(), model.NewId()
+
+	tests := []struct {
+		name string
+		// received sends the issue from foreignID to userID, who accepts it unless pending
+		received bool
+		pending  bool
+		// act does the operation to undo for userID on issueID
+		act             func(t *testing.T, l *listManager, store *memStore, issueID string)
+		wantErr         error
+		wantAction      string
+		wantList        string
+		wantForeignList string
+	}{
+		{
+			name: "complete",
+			act: func(t *testing.T, l *listManager, _ *memStore, issueID string) {
+				_, _, _, err := l.CompleteIssue(userID, issueID)
+				require.NoError(t, err)
+			},
+			wantAction: TombstoneComplete,
+			wantList:   MyListKey,
+		},
+		{
+			name:     "complete a received issue",
+			received: true,
+			act: func(t *testing.T, l *listManager, _ *memStore, issueID string) {
+				_, _, _, err := l.CompleteIssue(userID, issueID)
+				require.NoError(t, err)
+			},
+			wantAction:      TombstoneComplete,
+			wantList:        MyListKey,
+			wantForeignList: OutListKey,
+		},
+		{
+			name: "remove",
+			act: func(t *testing.T, l *listManager, _ *memStore, issueID string) {
+				_, _, _, _, err := l.RemoveIssue(userID, issueID)
+				require.NoError(t, err)
+			},
+			wantAction: TombstoneRemove,
+			wantList:   MyListKey,
+		},
+		{
+			name:     "pop",
+			received: true,
+			act: func(t *testing.T, l *listManager, _ *memStore, _ string) {
+				_, _, err := l.PopIssue(userID)
+				require.NoError(t, err)
+			},
+			wantAction:      TombstonePop,
+			wantList:        MyListKey,
+			wantForeignList: OutListKey,
+		},
+		{
+			name:     "decline",
+			received: true,
+			pending:  true,
+			act: func(t *testing.T, l *listManager, _ *memStore, issueID string) {
+				_, _, _, _, err := l.RemoveIssue(userID, issueID)
+				require.NoError(t, err)
+			},
+			wantAction:      TombstoneDecline,
+			wantList:        InListKey,
+			wantForeignList: OutListKey,
+		},
+		{
+			name: "expired",
+			act: func(t *testing.T, l *listManager, store *memStore, issueID string) {
+				_, _, _, err := l.CompleteIssue(userID, issueID)
+				require.NoError(t, err)
+				store.tombstones[userID].CreateAt -= (UndoExpirySeconds + 1) * 1000
+			},
+			wantErr:  ErrNothingToUndo,
+			wantList: DoneListKey,
+		},
+		{
+			name:     "foreign reference removed in the meantime",
+			received: true,
+			act: func(t *testing.T, l *listManager, store *memStore, issueID string) {
+				_, _, _, err := l.CompleteIssue(userID, issueID)
+				require.NoError(t, err)
+				store.lists[listKey(foreignID, DoneListKey)] = nil
+			},
+			wantAction: TombstoneComplete,
+			wantList:   MyListKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemStore()
+			api := &plugintest.API{}
+			api.On("LogError", mock.Anything, mock.Anything)
+			api.On("GetUser", mock.Anything).Return(&model.User{Username: "someone"}, nil)
+			l := &listManager{store: store, api: api}
+
+			issue := newIssue("issue", "", "", "")
+			if tt.received {
+				receivedID, err := l.SendIssue(foreignID, userID, issue)
+				require.NoError(t, err)
+				if !tt.pending {
+					_, _, err = l.AcceptIssue(userID, receivedID)
+					require.NoError(t, err)
+				}
+				issue = store.issues[receivedID]
+			} else {
+				require.NoError(t, l.AddIssue(userID, issue))
+			}
+			require.NoError(t, l.AddIssue(userID, newIssue("other", "", "", "")))
+
+			tt.act(t, l, store, issue.ID)
+
+			tombstone, err := l.UndoLastAction(userID)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantAction, tombstone.Action)
+			}
+
+			// The issue is back where it was, as it was
+			ir, n, err := store.GetIssueReference(userID, issue.ID, tt.wantList)
+			require.NoError(t, err)
+			assert.Equal(t, 0, n)
+			restored, err := store.GetIssue(issue.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantList == DoneListKey, restored.CompletedAt != 0)
+			if tt.wantList != DoneListKey {
+				assert.Empty(t, store.lists[listKey(userID, DoneListKey)])
+			}
+
+			if tt.wantForeignList == "" {
+				assert.Empty(t, store.lists[listKey(foreignID, OutListKey)])
+				assert.Empty(t, store.lists[listKey(foreignID, DoneListKey)])
+				return
+			}
+			foreignIR, _, err := store.GetIssueReference(foreignID, ir.ForeignIssueID, tt.wantForeignList)
+			require.NoError(t, err)
+			assert.Equal(t, issue.ID, foreignIR.ForeignIssueID)
+			assert.Empty(t, store.lists[listKey(foreignID, DoneListKey)])
+		})
+	}
+}
+
+func TestCompleteIssue(t *testing.T) {
+	senderID, receiverID := model.NewId(), model.NewId()
+	store := newMemStore()
+	api := &plugintest.API{}
+	api.On("GetUser", mock.Anything).Return(&model.User{Username: "someone"}, nil)
+	l := &listManager{store: store, api: api}
+
+	t.Run("completed issues are archived for both users", func(t *testing.T) {
+		sent := newIssue("sent", "", "", "")
+		receiverIssueID, err := l.SendIssue(senderID, receiverID, sent)
+		require.NoError(t, err)
+		_, _, err = l.AcceptIssue(receiverID, receiverIssueID)
+		require.NoError(t, err)
+
+		before := model.GetMillis()
+		_, foreignID, listID, err := l.CompleteIssue(receiverID, receiverIssueID)
+		require.NoError(t, err)
+		assert.Equal(t, senderID, foreignID)
+		assert.Equal(t, MyListKey, listID)
+
+		assert.Empty(t, store.lists[listKey(receiverID, MyListKey)])
+		assert.Empty(t, store.lists[listKey(senderID, OutListKey)])
+		for _, done := range []struct{ userID, issueID, previousList string }{
+			{receiverID, receiverIssueID, MyListKey},
+			{senderID, sent.ID, OutListKey},
+		} {
+			ir, _, err := store.GetIssueReference(done.userID, done.issueID, DoneListKey)
+			require.NoError(t, err)
+			assert.Equal(t, done.previousList, ir.PreviousList)
+
+			issue := store.issues[done.issueID]
+			assert.Equal(t, receiverID, issue.CompletedBy)
+			assert.GreaterOrEqual(t, issue.CompletedAt, before)
+			assert.LessOrEqual(t, issue.CompletedAt, model.GetMillis())
+		}
+	})
+
+	t.Run("done issues are paged most recent first", func(t *testing.T) {
+		userID := model.NewId()
+		issueIDs := []string{}
+		for _, message := range []string{"first", "second", "third"} {
+			issue := newIssue(message, "", "", "")
+			require.NoError(t, l.AddIssue(userID, issue))
+			_, _, _, err := l.CompleteIssue(userID, issue.ID)
+			require.NoError(t, err)
+			issueIDs = append(issueIDs, issue.ID)
+		}
+
+		issues, err := l.GetDoneIssueList(userID, 0, 2)
+		require.NoError(t, err)
+		require.Len(t, issues, 2)
+		assert.Equal(t, issueIDs[2], issues[0].ID)
+		assert.Equal(t, issueIDs[1], issues[1].ID)
+		assert.Equal(t, "someone", issues[0].CompletedByUser)
+
+		issues, err = l.GetDoneIssueList(userID, 1, 2)
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, issueIDs[0], issues[0].ID)
+
+		issues, err = l.GetDoneIssueList(userID, 2, 2)
+		require.NoError(t, err)
+		assert.Empty(t, issues)
+	})
+}
+
+func TestReopenIssue(t *testing.T) {
+	senderID, receiverID := model.NewId(), model.NewId()
+
+	tests := []struct {
+		name string
+		// sent sends the issue from senderID to receiverID, who accepts it, instead of adding it to a
+		// custom list of receiverID
+		sent bool
+		// change alters the store after receiverID completes the issue
+		change  func(t *testing.T, l *listManager, store *memStore, listID string)
+		wantErr error
+	}{
+		{
+			name: "own issue",
+		},
+		{
+			name: "sent issue",
+			sent: true,
+		},
+		{
+			name: "list deleted",
+			change: func(t *testing.T, l *listManager, _ *memStore, listID string) {
+				_, _, err := l.DeleteList(receiverID, listID)
+				require.NoError(t, err)
+			},
+			wantErr: ErrCannotReopen,
+		},
+		{
+			name: "counterpart removed",
+			sent: true,
+			change: func(_ *testing.T, _ *listManager, store *memStore, _ string) {
+				store.lists[listKey(senderID, DoneListKey)] = nil
+			},
+			wantErr: ErrCannotReopen,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemStore()
+			l := &listManager{store: store, api: &plugintest.API{}}
+
+			issue := newIssue("issue", "", "", "")
+			listID := MyListKey
+			if tt.sent {
+				receiverIssueID, err := l.SendIssue(senderID, receiverID, issue)
+				require.NoError(t, err)
+				_, _, err = l.AcceptIssue(receiverID, receiverIssueID)
+				require.NoError(t, err)
+				issue = store.issues[receiverIssueID]
+			} else {
+				list, err := l.CreateList(receiverID, "work")
+				require.NoError(t, err)
+				require.NoError(t, l.AddIssue(receiverID, issue))
+				_, err = l.MoveIssueToList(receiverID, issue.ID, list.ID)
+				require.NoError(t, err)
+				listID = list.ID
+			}
+
+			_, _, _, err := l.CompleteIssue(receiverID, issue.ID)
+			require.NoError(t, err)
+			if tt.change != nil {
+				tt.change(t, l, store, listID)
+			}
+
+			_, foreignID, list, err := l.ReopenIssue(receiverID, issue.ID)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				_, _, err = store.GetIssueReference(receiverID, issue.ID, DoneListKey)
+				assert.NoError(t, err)
+				assert.NotZero(t, store.issues[issue.ID].CompletedAt)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, listID, list)
+
+			ir, _, err := store.GetIssueReference(receiverID, issue.ID, listID)
+			require.NoError(t, err)
+			assert.Empty(t, ir.PreviousList)
+			assert.Empty(t, store.lists[listKey(receiverID, DoneListKey)])
+			assert.Zero(t, store.issues[issue.ID].CompletedAt)
+			assert.Empty(t, store.issues[issue.ID].CompletedBy)
+
+			if !tt.sent {
+				assert.Empty(t, foreignID)
+				return
+			}
+			assert.Equal(t, senderID, foreignID)
+			foreignIR, _, err := store.GetIssueReference(senderID, ir.ForeignIssueID, OutListKey)
+			require.NoError(t, err)
+			assert.Equal(t, issue.ID, foreignIR.ForeignIssueID)
+			assert.Empty(t, store.lists[listKey(senderID, DoneListKey)])
+			assert.Zero(t, store.issues[ir.ForeignIssueID].CompletedAt)
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+// memStore is an in memory ListStore implementing the functions used by the journal and the checker
+type memStore struct {
+	ListStore
+	issues        map[string]*Issue
+	lists         map[string][]*IssueRef
+	journal       []*JournalEntry
+	shortIDs      map[string]int64
+	shortIDIssues map[string]string
+	tombstones    map[string]*Tombstone
+	custom        map[string][]*CustomList
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		issues:        map[string]*Issue{},
+		lists:         map[string][]*IssueRef{},
+		shortIDs:      map[string]int64{},
+		shortIDIssues: map[string]string{},
+		tombstones:    map[string]*Tombstone{},
+		custom:        map[string][]*CustomList{},
+	}
+}
+
+func (s *memStore) GetIssue(issueID string) (*Issue, error) {
+	issue, ok := s.issues[issueID]
+	if !ok {
+		return nil, ErrIssueNotFound
+	}
+	return issue, nil
+}
+
+func (s *memStore) GetIssueIDs() ([]string, error) {
+	ids := []string{}
+	for id := range s.issues {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (s *memStore) GetUserIDs() ([]string, error) {
+	return s.getOwnerIDs(false), nil
+}
+
+func (s *memStore) GetChannelIDs() ([]string, error) {
+	return s.getOwnerIDs(true), nil
+}
+
+func (s *memStore) getOwnerIDs(channels bool) []string {
+	ids := []string{}
+	seen := map[string]bool{}
+	for key := range s.lists {
+		if ownerID, listID, ok := parseListKey(key); ok && isChannelListKey(listID) == channels && !seen[ownerID] {
+			seen[ownerID] = true
+			ids = append(ids, ownerID)
+		}
+	}
+	return ids
+}
+
+func (s *memStore) GetList(userID, listID string) ([]*IssueRef, error) {
+	return append([]*IssueRef{}, s.lists[listKey(userID, listID)]...), nil
+}
+
+func (s *memStore) GetIssueReference(userID, issueID, listID string) (*IssueRef, int, error) {
+	for i, ir := range s.lists[listKey(userID, listID)] {
+		if ir.IssueID == issueID {
+			return ir, i, nil
+		}
+	}
+	return nil, 0, ErrIssueNotFound
+}
+
+func (s *memStore) GetIssueListAndReference(userID, issueID string) (string, *IssueRef, int) {
+	listIDs := []string{MyListKey, OutListKey, InListKey}
+	for _, list := range s.custom[userID] {
+		listIDs = append(listIDs, list.ID)
+	}
+	for _, listID := range listIDs {
+		if ir, n, _ := s.GetIssueReference(userID, issueID, listID); ir != nil {
+			return listID, ir, n
+		}
+	}
+	return "", nil, 0
+}
+
+func (s *memStore) SaveIssue(issue *Issue) error {
+	s.issues[issue.ID] = issue
+	return nil
+}
+
+func (s *memStore) RemoveIssue(userID, issueID string) error {
+	if issue, ok := s.issues[issueID]; ok && s.shortIDIssues[shortIDIssueKey(userID, issue.ShortID)] == issueID {
+		delete(s.shortIDIssues, shortIDIssueKey(userID, issue.ShortID))
+	}
+	delete(s.issues, issueID)
+	return nil
+}
+
+func (s *memStore) InsertReference(userID, listID string, ir *IssueRef, position int) error {
+	list := s.lists[listKey(userID, listID)]
+	for _, r := range list {
+		if r.IssueID == ir.IssueID {
+			return ErrReferenceExists
+		}
+	}
+	if position < 0 || position > len(list) {
+		position = len(list)
+	}
+	newList := append([]*IssueRef{}, list[:position]...)
+	newList = append(newList, ir)
+	s.lists[listKey(userID, listID)] = append(newList, list[position:]...)
+	return nil
+}
+
+func (s *memStore) AddReference(userID, issueID, listID, foreignUserID, foreignIssueID string) error {
+	return s.InsertReference(userID, listID, &IssueRef{IssueID: issueID, ForeignUserID: foreignUserID, ForeignIssueID: foreignIssueID}, -1)
+}
+
+func (s *memStore) RemoveReference(userID, issueID, listID string) error {
+	list := s.lists[listKey(userID, listID)]
+	for i, r := range list {
+		if r.IssueID == issueID {
+			s.lists[listKey(userID, listID)] = append(append([]*IssueRef{}, list[:i]...), list[i+1:]...)
+			return nil
+		}
+	}
+	return ErrIssueNotFound
+}
+
+func (s *memStore) BumpReference(userID, issueID, listID string) error {
+	ir, _, err := s.GetIssueReference(userID, issueID, listID)
+	if err != nil {
+		return err
+	}
+	if err = s.RemoveReference(userID, issueID, listID); err != nil {
+		return err
+	}
+	return s.InsertReference(userID, listID, ir, 0)
+}
+
+func (s *memStore) MoveReference(userID, issueID, listID string, newPosition int) error {
+	list, _, err := moveReference(s.lists[listKey(userID, listID)], issueID, newPosition)
+	if err != nil {
+		return err
+	}
+	s.lists[listKey(userID, listID)] = list
+	return nil
+}
+
+func (s *memStore) ReorderReferences(userID, listID string, issueIDs []string) error {
+	list, err := reorderReferences(s.lists[listKey(userID, listID)], issueIDs)
+	if err != nil {
+		return err
+	}
+	s.lists[listKey(userID, listID)] = list
+	return nil
+}
+
+func (s *memStore) SaveTombstone(userID string, tombstone *Tombstone) error {
+	s.tombstones[userID] = tombstone
+	return nil
+}
+
+func (s *memStore) GetAndRemoveTombstone(userID string) (*Tombstone, error) {
+	tombstone := s.tombstones[userID]
+	delete(s.tombstones, userID)
+	return tombstone, nil
+}
+
+func (s *memStore) SaveJournalEntry(entry *JournalEntry) error {
+	for i, e := range s.journal {
+		if e.ID == entry.ID {
+			s.journal[i] = entry
+			return nil
+		}
+	}
+	s.journal = append(s.journal, entry)
+	return nil
+}
+
+func (s *memStore) RemoveJournalEntry(entryID string) error {
+	for i, e := range s.journal {
+		if e.ID == entryID {
+			s.journal = append(s.journal[:i], s.journal[i+1:]...)
+		}
+	}
+	return nil
+}
+
+func (s *memStore) GetJournalEntries() ([]*JournalEntry, error) {
+	return append([]*JournalEntry{}, s.journal...), nil
+}
+
+func (s *memStore) NextShortID(userID string) (int64, error) {
+	s.shortIDs[userID]++
+	return s.shortIDs[userID], nil
+}
+
+func (s *memStore) SaveShortID(userID string, shortID int64, issueID string) error {
+	s.shortIDIssues[shortIDIssueKey(userID, shortID)] = issueID
+	return nil
+}
+
+func (s *memStore) GetIssueIDByShortID(userID string, shortID int64) (string, error) {
+	issueID, ok := s.shortIDIssues[shortIDIssueKey(userID, shortID)]
+	if !ok {
+		return "", ErrIssueNotFound
+	}
+	return issueID, nil
+}
+
+func (s *memStore) GetCustomLists(userID string) ([]*CustomList, error) {
+	lists := []*CustomList{}
+	for _, list := range s.custom[userID] {
+		listCopy := *list
+		lists = append(lists, &listCopy)
+	}
+	return lists, nil
+}
+
+func (s *memStore) UpdateCustomLists(userID string, update func([]*CustomList) ([]*CustomList, error)) error {
+	lists, _ := s.GetCustomLists(userID)
+	newLists, err := update(lists)
+	if err != nil {
+		return err
+	}
+	s.custom[userID] = newLists
+	return nil
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/pluginapi/cluster"
+	"github.com/pkg/errors"
+)
+
+const (
+	// StoreSQLMigrationKey is the key used to store whether the KV data has already been copied to the database
+	// tables. It is never removed, since the todos created afterwards are only in the database.
+	StoreSQLMigrationKey = "sql_migration_done"
+
+	// StoreReminderUsersMigrationKey is the key used to store whether the users that get reminders have
+	// already been collected from the stored preferences
+	StoreReminderUsersMigrationKey = "reminder_users_migration_done"
+
+	// StoreShortIDMigrationKey is the key used to store whether the todos created before short IDs existed
+	// have already been given one
+	StoreShortIDMigrationKey = "short_id_migration_done"
+
+	sqlMigrationMutexKey           = "sql_migration"
+	reminderUsersMigrationMutexKey = "reminder_users_migration"
+	shortIDMigrationMutexKey       = "short_id_migration"
+	kvListPerPage                  = 1000
+)
+
+// newListStore creates the ListStore for the configured storage, running any pending migration
+func (p *Plugin) newListStore(storage string) (ListStore, error) {
+	kvStore := NewListStore(p.API)
+	if storage != StorageDatabase {
+		// The todos created while using the database would be lost
+		migrated, appErr := p.API.KVGet(StoreSQLMigrationKey)
+		if appErr != nil {
+			return nil, errors.Wrap(appErr, "failed to check the database migration")
+		}
+		if migrated != nil {
+			return nil, errors.New("the todos have been moved to the database, the storage cannot be switched back to the key-value store")
+		}
+		return kvStore, nil
+	}
+
+	db, err := p.client.Store.GetMasterDB()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get database connection")
+	}
+
+	store := newSQLListStore(db, p.client.Store.DriverName(), kvStore)
+
+	mutex, err := cluster.NewMutex(p.API, sqlMigrationMutexKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create migration mutex")
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if err := store.migrate(); err != nil {
+		return nil, errors.Wrap(err, "failed to migrate database schema")
+	}
+
+	if err := p.migrateKVToSQL(kvStore, store); err != nil {
+		return nil, errors.Wrap(err, "failed to migrate todos to the database")
+	}
+
+	return store, nil
+}
+
+// migrateKVToSQL copies the issues and lists stored in the KV store to the database tables. It only
+// runs once, and leaves the KV data untouched. Rows already copied by an interrupted run are kept.
+func (p *Plugin) migrateKVToSQL(kvStore ListStore, store *sqlStore) error {
+	done, appErr := p.API.KVGet(StoreSQLMigrationKey)
+	if appErr != nil {
+		return errors.New(appErr.Error())
+	}
+
+	if done != nil {
+		return nil
+	}
+
+	p.API.LogInfo("Copying todos from the KV store to the database")
+
+	issues, lists := 0, 0
+	for page := 0; ; page++ {
+		keys, appErr := p.API.KVList(page, kvListPerPage)
+		if appErr != nil {
+			return errors.New(appErr.Error())
+		}
+
+		for _, key := range keys {
+			if issueID, ok := parseIssueKey(key); ok {
+				issue, err := kvStore.GetIssue(issueID)
+				if err != nil {
+					p.API.LogWarn("Skipping issue that cannot be read", "key", key, "err", err.Error())
+					continue
+				}
+
+				if err = store.importIssue(issue); err != nil {
+					return errors.Wrapf(err, "failed to copy issue %s", issueID)
+				}
+				issues++
+				continue
+			}
+
+			if userID, listID, ok := parseListKey(key); ok {
+				list, err := kvStore.GetList(userID, listID)
+				if err != nil {
+					p.API.LogWarn("Skipping list that cannot be read", "key", key, "err", err.Error())
+					continue
+				}
+
+				if err = store.importList(userID, listID, list); err != nil {
+					return errors.Wrapf(err, "failed to copy list %s", key)
+				}
+				lists++
+			}
+		}
+
+		if len(keys) < kvListPerPage {
+			break
+		}
+	}
+
+	if appErr := p.API.KVSet(StoreSQLMigrationKey, []byte("true")); appErr != nil {
+		return errors.New(appErr.Error())
+	}
+
+	p.API.LogInfo("Finished copying todos from the KV store to the database", "issues", issues, "lists", lists)
+
+	return nil
+}
+
+// migrateReminderUsers adds the users with Todos that get reminders to the set read by the reminder
+// job. It only runs once, before the set is updated by anyone else.
+func (p *Plugin) migrateReminderUsers() error {
+	mutex, err := cluster.NewMutex(p.API, reminderUsersMigrationMutexKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to create migration mutex")
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return p.collectReminderUsers()
+}
+
+func (p *Plugin) collectReminderUsers() error {
+	done, appErr := p.API.KVGet(StoreReminderUsersMigrationKey)
+	if appErr != nil {
+		return errors.New(appErr.Error())
+	}
+
+	if done != nil {
+		return nil
+	}
+
+	userIDs, err := p.listManager.GetUserIDs()
+	if err != nil {
+		return errors.Wrap(err, "failed to get users with todos")
+	}
+
+	reminderUserIDs := []string{}
+	for _, userID := range userIDs {
+		if p.getReminderPreference(userID) {
+			reminderUserIDs = append(reminderUserIDs, userID)
+		}
+	}
+
+	err = p.updateReminderUsers(func(existingUserIDs []string) []string {
+		return mergeIDs(existingUserIDs, reminderUserIDs)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to store the users that get reminders")
+	}
+
+	if appErr := p.API.KVSet(StoreReminderUsersMigrationKey, []byte("true")); appErr != nil {
+		return errors.New(appErr.Error())
+	}
+
+	p.API.LogInfo("Collected the users that get reminders", "users", len(reminderUserIDs))
+
+	return nil
+}
+
+// migrateShortIDs assigns short IDs to the todos created before they existed. It only runs once.
+func (p *Plugin) migrateShortIDs() error {
+	mutex, err := cluster.NewMutex(p.API, shortIDMigrationMutexKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to create migration mutex")
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return p.backfillShortIDs()
+}
+
+func (p *Plugin) backfillShortIDs() error {
+	done, appErr := p.API.KVGet(StoreShortIDMigrationKey)
+	if appErr != nil {
+		return errors.New(appErr.Error())
+	}
+
+	if done != nil {
+		return nil
+	}
+
+	userIDs, err := p.listManager.GetUserIDs()
+	if err != nil {
+		return errors.Wrap(err, "failed to get users with todos")
+	}
+
+	assigned := 0
+	for _, userID := range userIDs {
+		n, err := p.listManager.BackfillShortIDs(userID)
+		assigned += n
+		if err != nil {
+			return errors.Wrapf(err, "failed to assign short IDs to the todos of user %s", userID)
+		}
+	}
+
+	if appErr := p.API.KVSet(StoreShortIDMigrationKey, []byte("true")); appErr != nil {
+		return errors.New(appErr.Error())
+	}
+
+	p.API.LogInfo("Assigned short IDs to the existing todos", "todos", assigned)
+
+	return nil
+}
+
+// mergeIDs returns ids followed by the newIDs that are not in ids
+func mergeIDs(ids, newIDs []string) []string {
+	merged := append([]string{}, ids...)
+	seen := map[string]bool{}
+	for _, id := range ids {
+		seen[id] = true
+	}
+	for _, id := range newIDs {
+		if !seen[id] {
+			seen[id] = true
+			merged = append(merged, id)
+		}
+	}
+	return merged
+}
+
+// parseIssueKey returns the issue ID of a KV key created by issueKey
+func parseIssueKey(key string) (string, bool) {
+	issueID := strings.TrimPrefix(key, StoreIssueKey+"_")
+	if issueID == key || !model.IsValidId(issueID) {
+		return "", false
+	}
+
+	return issueID, true
+}
+
+// parseJournalKey returns the entry ID of a KV key created by journalKey
+func parseJournalKey(key string) (string, bool) {
+	entryID := strings.TrimPrefix(key, StoreJournalKey+"_")
+	if entryID == key || !model.IsValidId(entryID) {
+		return "", false
+	}
+
+	return entryID, true
+}
+
+// parseListKey returns the user ID and list ID of a KV key created by listKey
+func parseListKey(key string) (string, string, bool) {
+	rest := strings.TrimPrefix(key, StoreListKey+"_")
+	if rest == key || len(rest) < 26 {
+		return "", "", false
+	}
+
+	userID, listID := rest[:26], rest[26:]
+	if !model.IsValidId(userID) {
+		return "", "", false
+	}
+
+	switch listID {
+	case MyListKey, InListKey, OutListKey, DoneListKey, ChannelListKey, ChannelDoneListKey:
+		return userID, listID, true
+	}
+
+	if isCustomListKey(listID) {
+		return userID, listID, true
+	}
+
+	return "", "", false
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseListKey(t *testing.T) {
+	userID := "8zjpgcpi5fy1mkqd4tpfmxrbjh"
+
+	tests := []struct {
+		name       string
+		key        string
+		wantUserID string
+		wantListID string
+		wantOK     bool
+	}{
+		{name: "My list", key: listKey(userID, MyListKey), wantUserID: userID, wantListID: MyListKey, wantOK: true},
+		{name: "In list", key: listKey(userID, InListKey), wantUserID: userID, wantListID: InListKey, wantOK: true},
+		{name: "Out list", key: listKey(userID, OutListKey), wantUserID: userID, wantListID: OutListKey, wantOK: true},
+		{name: "Done list", key: listKey(userID, DoneListKey), wantUserID: userID, wantListID: DoneListKey, wantOK: true},
+		{name: "Channel list", key: listKey(userID, ChannelListKey), wantUserID: userID, wantListID: ChannelListKey, wantOK: true},
+		{name: "Unknown list", key: listKey(userID, "_other"), wantOK: false},
+		{name: "Issue key", key: issueKey(userID), wantOK: false},
+		{name: "Short key", key: "order_abc", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotUserID, gotListID, ok := parseListKey(tt.key)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantUserID, gotUserID)
+			assert.Equal(t, tt.wantListID, gotListID)
+		})
+	}
+}
+
+func TestRebind(t *testing.T) {
+	query := "SELECT id FROM todo_issues WHERE id = ? AND create_at > ?"
+
+	postgres := &sqlStore{driverName: "postgres"}
+	assert.Equal(t, "SELECT id FROM todo_issues WHERE id = $1 AND create_at > $2", postgres.rebind(query))
+
+	mysql := &sqlStore{driverName: "mysql"}
+	assert.Equal(t, query, mysql.rebind(query))
+}
+
+func TestNewListStoreKV(t *testing.T) {
+	t.Run("key-value store", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", StoreSQLMigrationKey).Return(nil, nil)
+		defer api.AssertExpectations(t)
+		p := &Plugin{}
+		p.SetAPI(api)
+
+		store, err := p.newListStore(StorageKV)
+		require.NoError(t, err)
+		assert.IsType(t, &listStore{}, store)
+	})
+
+	t.Run("switching back after using the database", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", StoreSQLMigrationKey).Return([]byte("true"), nil)
+		defer api.AssertExpectations(t)
+		p := &Plugin{}
+		p.SetAPI(api)
+
+		_, err := p.newListStore(StorageKV)
+		assert.Error(t, err)
+	})
+}
+
+func TestBackfillShortIDs(t *testing.T) {
+	userID := model.NewId()
+	store := newMemStore()
+	legacy, done := newIssue("legacy", "", "", ""), newIssue("done", "", "", "")
+	store.issues[legacy.ID] = legacy
+	store.issues[done.ID] = done
+	store.lists[listKey(userID, MyListKey)] = []*IssueRef{{IssueID: legacy.ID}}
+	store.lists[listKey(userID, DoneListKey)] = []*IssueRef{{IssueID: done.ID}}
+
+	api := &plugintest.API{}
+	api.On("KVGet", StoreShortIDMigrationKey).Return(nil, nil).Once()
+	api.On("KVSet", StoreShortIDMigrationKey, []byte("true")).Return(nil).Once()
+	api.On("LogInfo", mock.Anything, "todos", 2).Once()
+	api.On("KVGet", StoreShortIDMigrationKey).Return([]byte("true"), nil).Once()
+	defer api.AssertExpectations(t)
+	p := &Plugin{listManager: &listManager{store: store, api: api}}
+	p.SetAPI(api)
+
+	require.NoError(t, p.backfillShortIDs())
+	assert.EqualValues(t, 1, legacy.ShortID)
+	assert.EqualValues(t, 2, done.ShortID)
+
+	// Once done, it is not run again
+	require.NoError(t, p.backfillShortIDs())
+}
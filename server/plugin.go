@@ -13,6 +13,7 @@ import (
 	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/mattermost/mattermost/server/public/plugin"
 	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/mattermost/mattermost/server/public/pluginapi/cluster"
 	"github.com/mattermost/mattermost/server/public/pluginapi/experimental/telemetry"
 	"github.com/pkg/errors"
 )
@@ -25,32 +26,102 @@ const (
 	WSEventConfigUpdate = "config_update"
 
 	ErrorMsgAddIssue = "Unable to add issue"
+
+	// DefaultDonePerPage is the default page size when listing completed todos
+	DefaultDonePerPage = 20
+	// MaxDonePerPage is the maximum page size when listing completed todos
+	MaxDonePerPage = 200
 )
 
 // ListManager represents the logic on the lists
 type ListManager interface {
-	// AddIssue adds a todo to userID's myList with the message
-	AddIssue(userID, message, postPermalink, description, postID string) (*Issue, error)
-	// SendIssue sends the todo with the message from senderID to receiverID and returns the receiver's issueID
-	SendIssue(senderID, receiverID, message, postPermalink, description, postID string) (string, error)
+	// AddIssue stores the issue and adds it to userID's myList
+	AddIssue(userID string, issue *Issue) error
+	// SendIssue sends the issue from senderID to receiverID and returns the receiver's issueID
+	SendIssue(senderID, receiverID string, issue *Issue) (string, error)
+	// SendIssueToUsers sends the issue from senderID to each of receiverIDs, keeping a single issue on the
+	// out list of senderID that tracks all of them, and returns the issueID of each receiver
+	SendIssueToUsers(senderID string, receiverIDs []string, issue *Issue) ([]string, error)
+	// GetIssue gets the todo issueID, if it is on any of the open lists of userID
+	GetIssue(userID, issueID string) (*Issue, error)
 	// GetIssueList gets the todos on listID for userID
 	GetIssueList(userID, listID string) ([]*ExtendedIssue, error)
 	// GetAllList get all issues
 	GetAllList(userID string) (*ListsIssue, error)
-	// CompleteIssue completes the todo issueID for userID, and returns the issue and the foreign ID if any
+	// GetDoneIssueList gets a page of the completed todos for userID, most recently completed first
+	GetDoneIssueList(userID string, page, perPage int) ([]*ExtendedIssue, error)
+	// CompleteIssue completes the todo issueID for userID moving it to the done list, and returns the issue and the foreign ID if any
 	CompleteIssue(userID, issueID string) (issue *Issue, foreignID string, listToUpdate string, err error)
+	// ReopenIssue moves the completed todo issueID for userID back to its previous list, and returns the issue, the foreign ID if any and the list it was restored to
+	ReopenIssue(userID, issueID string) (issue *Issue, foreignID string, listToUpdate string, err error)
 	// AcceptIssue moves one the todo issueID of userID from inbox to myList, and returns the message and the foreignUserID if any
 	AcceptIssue(userID, issueID string) (todoMessage string, foreignUserID string, err error)
 	// RemoveIssue removes the todo issueID for userID and returns the issue, the foreign ID if any and whether the user sent the todo to someone else
 	RemoveIssue(userID, issueID string) (issue *Issue, foreignID string, isSender bool, listToUpdate string, err error)
+	// DiscardIssue removes the todo issueID for userID as RemoveIssue does, keeping the last action to undo
+	DiscardIssue(userID, issueID string) (issue *Issue, foreignID string, isSender bool, listToUpdate string, err error)
 	// PopIssue the first element of myList for userID and returns the issue and the foreign ID if any
 	PopIssue(userID string) (issue *Issue, foreignID string, err error)
+	// PopHighestPriorityIssue removes the first element with the highest priority of myList for userID and returns the issue and the foreign ID if any
+	PopHighestPriorityIssue(userID string) (issue *Issue, foreignID string, err error)
+	// SnoozeIssue hides issueID on the my or in list of userID until the given time
+	SnoozeIssue(userID, issueID string, until int64) (*Issue, error)
+	// WakeIssue ends the snooze of issueID for userID, moving it to the top of its list
+	WakeIssue(userID, issueID string) (issue *Issue, listID string, err error)
+	// MoveIssue moves issueID of userID to the given zero based position on its list, returning the list
+	MoveIssue(userID, issueID string, position int) (listID string, err error)
+	// ReorderIssues sorts the list listID of userID with issueIDs first, in that order, followed by
+	// the issues not given in their current order
+	ReorderIssues(userID, listID string, issueIDs []string) error
 	// BumpIssue moves a issueID sent by userID to the top of its receiver inbox list
 	BumpIssue(userID string, issueID string) (todo *Issue, receiver string, foreignIssueID string, err error)
-	// EditIssue updates the message on an issue
-	EditIssue(userID string, issueID string, newMessage string, newDescription string) (foreignUserID string, list string, oldMessage string, err error)
+	// EditIssue updates the message and the rest of editable fields on an issue
+	EditIssue(userID string, issueID string, update *IssueUpdate) (foreignUserID string, list string, oldMessage string, err error)
+	// UpdateChecklist applies update to the checklist of issueID for userID, and of its foreign counterpart if any
+	UpdateChecklist(userID, issueID string, update *ChecklistUpdate) (issue *Issue, foreignUserID string, list string, err error)
+	// GetCustomLists returns the lists created by userID, in order
+	GetCustomLists(userID string) ([]*CustomList, error)
+	// GetCustomList returns the list of userID with the given ID or name
+	GetCustomList(userID, ref string) (*CustomList, error)
+	// CreateList adds a new list named name at the end of the lists of userID
+	CreateList(userID, name string) (*CustomList, error)
+	// RenameList changes the name of the list listID of userID
+	RenameList(userID, listID, name string) (*CustomList, error)
+	// MoveList moves the list listID of userID to the given zero based position among the rest of lists
+	MoveList(userID, listID string, position int) error
+	// DeleteList removes the list listID of userID, moving its issues to the end of the my list. It
+	// returns the deleted list and the number of issues moved.
+	DeleteList(userID, listID string) (*CustomList, int, error)
+	// MoveIssueToList moves issueID of userID from the my list or a custom list to the end of listID, which
+	// is the my list or a custom list. It returns the list the issue was on.
+	MoveIssueToList(userID, issueID, listID string) (string, error)
+	// AddChannelIssue stores the issue and adds it to the list of channelID
+	AddChannelIssue(channelID string, issue *Issue) error
+	// ClaimChannelIssue marks the todo issueID of the list of channelID as taken by userID
+	ClaimChannelIssue(channelID, userID, issueID string) (*Issue, error)
+	// CompleteChannelIssue completes the todo issueID of the list of channelID on behalf of userID
+	CompleteChannelIssue(channelID, userID, issueID string) (*Issue, error)
+	// RemoveChannelIssue removes the todo issueID, open or completed, from the lists of channelID
+	RemoveChannelIssue(channelID, issueID string) (*Issue, error)
 	// ChangeAssignment updates an issue to assign a different person
 	ChangeAssignment(issueID string, userID string, sendTo string) (issue *Issue, oldOwner string, err error)
+	// SetNextOccurrence records nextIssueID of nextUserID as the next occurrence of the repeating issue completed
+	// by userID, to remove it if the completion is undone or the issue reopened
+	SetNextOccurrence(userID string, issue *Issue, nextUserID, nextIssueID string) error
+	// UndoLastAction restores the issue affected by the last complete, remove or pop operation of userID, if it has not expired
+	UndoLastAction(userID string) (*Tombstone, error)
+	// RecoverOperations completes the interrupted operations of all users
+	RecoverOperations() error
+	// Fsck checks the lists of userID, or of all users if userID is empty, for inconsistencies, fixing them if fix is set
+	Fsck(userID string, fix bool) (*FsckReport, error)
+	// ResolveIssueID returns the ID of the issue of userID referred to by ref, which may be either an
+	// issue ID or a short ID such as #42
+	ResolveIssueID(userID, ref string) (string, error)
+	// BackfillShortIDs assigns a short ID to the issues of userID created before short IDs existed, and
+	// returns how many were assigned
+	BackfillShortIDs(userID string) (int, error)
+	// GetUserIDs returns the users that have any list stored
+	GetUserIDs() ([]string, error)
 	// GetUserName returns the readable username from userID
 	GetUserName(userID string) string
 }
@@ -73,8 +144,15 @@ type Plugin struct {
 
 	listManager ListManager
 
+	// reminderUsersChecked holds the users already checked by ensureReminderUser
+	reminderUsersChecked sync.Map
+
 	telemetryClient telemetry.Client
 	tracker         telemetry.Tracker
+
+	journalRecoveryJob *cluster.Job
+	reminderJob        *cluster.Job
+	snoozeJob          *cluster.Job
 }
 
 func (p *Plugin) OnActivate() error {
@@ -97,7 +175,34 @@ func (p *Plugin) OnActivate() error {
 	}
 	p.BotUserID = botID
 
-	p.listManager = NewListManager(p.API)
+	store, err := p.newListStore(config.StorageType)
+	if err != nil {
+		return errors.Wrap(err, "failed to initialize the todo store")
+	}
+	p.listManager = NewListManager(p.API, store)
+
+	if err = p.migrateReminderUsers(); err != nil {
+		return errors.Wrap(err, "failed to migrate the users that get reminders")
+	}
+
+	if err = p.migrateShortIDs(); err != nil {
+		return errors.Wrap(err, "failed to assign short IDs to the existing todos")
+	}
+
+	p.journalRecoveryJob, err = cluster.Schedule(p.API, journalRecoveryJobKey, cluster.MakeWaitForInterval(JournalRecoveryInterval), p.recoverOperations)
+	if err != nil {
+		return errors.Wrap(err, "failed to schedule the journal recovery job")
+	}
+
+	p.reminderJob, err = cluster.Schedule(p.API, reminderJobKey, cluster.MakeWaitForInterval(ReminderJobInterval), p.sendScheduledReminders)
+	if err != nil {
+		return errors.Wrap(err, "failed to schedule the reminder job")
+	}
+
+	p.snoozeJob, err = cluster.Schedule(p.API, snoozeJobKey, cluster.MakeWaitForInterval(SnoozeJobInterval), p.wakeSnoozedIssues)
+	if err != nil {
+		return errors.Wrap(err, "failed to schedule the snooze job")
+	}
 
 	p.initializeAPI()
 
@@ -110,6 +215,24 @@ func (p *Plugin) OnActivate() error {
 }
 
 func (p *Plugin) OnDeactivate() error {
+	if p.journalRecoveryJob != nil {
+		if err := p.journalRecoveryJob.Close(); err != nil {
+			p.API.LogWarn("OnDeactivate: failed to close the journal recovery job", "error", err.Error())
+		}
+	}
+
+	if p.reminderJob != nil {
+		if err := p.reminderJob.Close(); err != nil {
+			p.API.LogWarn("OnDeactivate: failed to close the reminder job", "error", err.Error())
+		}
+	}
+
+	if p.snoozeJob != nil {
+		if err := p.snoozeJob.Close(); err != nil {
+			p.API.LogWarn("OnDeactivate: failed to close the snooze job", "error", err.Error())
+		}
+	}
+
 	if p.telemetryClient != nil {
 		err := p.telemetryClient.Close()
 		if err != nil {
@@ -128,12 +251,33 @@ func (p *Plugin) initializeAPI() {
 	p.router.HandleFunc("/lists", p.checkAuth(p.handleLists)).Methods(http.MethodGet)
 	p.router.HandleFunc("/remove", p.checkAuth(p.handleRemove)).Methods(http.MethodPost)
 	p.router.HandleFunc("/complete", p.checkAuth(p.handleComplete)).Methods(http.MethodPost)
+	p.router.HandleFunc("/done", p.checkAuth(p.handleDone)).Methods(http.MethodGet)
+	p.router.HandleFunc("/reopen", p.checkAuth(p.handleReopen)).Methods(http.MethodPost)
 	p.router.HandleFunc("/accept", p.checkAuth(p.handleAccept)).Methods(http.MethodPost)
 	p.router.HandleFunc("/bump", p.checkAuth(p.handleBump)).Methods(http.MethodPost)
+	p.router.HandleFunc("/checklist", p.checkAuth(p.handleChecklist)).Methods(http.MethodPost)
+	p.router.HandleFunc("/snooze", p.checkAuth(p.handleSnooze)).Methods(http.MethodPost)
+	p.router.HandleFunc("/unsnooze", p.checkAuth(p.handleUnsnooze)).Methods(http.MethodPost)
+	p.router.HandleFunc("/undo", p.checkAuth(p.handleUndo)).Methods(http.MethodPost)
+	p.router.HandleFunc("/list/create", p.checkAuth(p.handleCreateList)).Methods(http.MethodPost)
+	p.router.HandleFunc("/list/rename", p.checkAuth(p.handleRenameList)).Methods(http.MethodPost)
+	p.router.HandleFunc("/list/delete", p.checkAuth(p.handleDeleteList)).Methods(http.MethodPost)
+	p.router.HandleFunc("/list/move", p.checkAuth(p.handleMoveList)).Methods(http.MethodPost)
+	p.router.HandleFunc("/move", p.checkAuth(p.handleMoveIssue)).Methods(http.MethodPost)
+	p.router.HandleFunc("/reorder", p.checkAuth(p.handleReorder)).Methods(http.MethodPost)
+	p.router.HandleFunc("/channel/{channel_id}", p.checkAuth(p.checkChannelAccess(p.handleChannelList))).Methods(http.MethodGet)
+	p.router.HandleFunc("/channel/{channel_id}/add", p.checkAuth(p.checkChannelAccess(p.handleChannelAdd))).Methods(http.MethodPost)
+	p.router.HandleFunc("/channel/{channel_id}/{action}", p.checkAuth(p.checkChannelAccess(p.handleChannelIssueAction))).Methods(http.MethodPost)
 	p.router.HandleFunc("/telemetry", p.checkAuth(p.handleTelemetry)).Methods(http.MethodPost)
 	p.router.HandleFunc("/config", p.checkAuth(p.handleConfig)).Methods(http.MethodGet)
+	p.router.HandleFunc("/settings", p.checkAuth(p.handleGetSettings)).Methods(http.MethodGet)
+	p.router.HandleFunc("/settings", p.checkAuth(p.handleUpdateSettings)).Methods(http.MethodPut)
 	p.router.HandleFunc("/edit", p.checkAuth(p.handleEdit)).Methods(http.MethodPut)
 	p.router.HandleFunc("/change_assignment", p.checkAuth(p.handleChangeAssignment)).Methods(http.MethodPost)
+	p.router.HandleFunc("/autocomplete/issues/{list}", p.checkAuth(p.handleAutocompleteIssues)).Methods(http.MethodGet)
+	p.router.HandleFunc("/action/{action}", p.checkAuth(p.handlePostAction)).Methods(http.MethodPost)
+	p.router.HandleFunc("/capture/{action}", p.checkAuth(p.handleCaptureAction)).Methods(http.MethodPost)
+	p.router.HandleFunc("/admin/fsck", p.checkAuth(p.checkSystemAdmin(p.handleFsck))).Methods(http.MethodGet, http.MethodPost)
 
 	// 404 handler
 	p.router.Handle("{anything:.*}", http.NotFoundHandler())
@@ -143,6 +287,10 @@ func (p *Plugin) initializeAPI() {
 func (p *Plugin) ServeHTTP(_ *plugin.Context, w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	if userID := r.Header.Get("Mattermost-User-ID"); userID != "" {
+		p.ensureReminderUser(userID)
+	}
+
 	p.router.ServeHTTP(w, r)
 }
 
@@ -173,6 +321,18 @@ func (p *Plugin) checkAuth(handler http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+func (p *Plugin) checkSystemAdmin(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Header.Get("Mattermost-User-ID")
+		if !p.API.HasPermissionTo(userID, model.PermissionManageSystem) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
 func (p *Plugin) handleTelemetry(w http.ResponseWriter, r *http.Request) {
 	userID := r.Header.Get("Mattermost-User-ID")
 
@@ -212,34 +372,48 @@ func (p *Plugin) handleAdd(w http.ResponseWriter, r *http.Request) {
 
 	senderName := p.listManager.GetUserName(userID)
 
-	if addRequest.SendTo == "" {
-		_, err = p.listManager.AddIssue(userID, addRequest.Message, addRequest.PostPermalink, addRequest.Description, addRequest.PostID)
+	issue := newIssue(addRequest.Message, addRequest.PostPermalink, addRequest.Description, addRequest.PostID)
+	issue.DueAt = addRequest.DueAt
+	issue.Priority = addRequest.Priority
+	if addRequest.Due != "" {
+		issue.DueAt, err = parseDueDate(addRequest.Due, p.getUserLocation(userID))
 		if err != nil {
-			p.API.LogError(ErrorMsgAddIssue, "err", err.Error())
-			p.handleErrorWithCode(w, http.StatusInternalServerError, ErrorMsgAddIssue, err)
+			p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to parse the due date.", err)
 			return
 		}
-
-		p.trackAddIssue(userID, sourceWebapp, addRequest.PostID != "")
-
-		p.sendRefreshEvent(userID, []string{MyListKey})
-
-		replyMessage := fmt.Sprintf("@%s attached a todo to this thread", senderName)
-		p.postReplyIfNeeded(addRequest.PostID, replyMessage, addRequest.Message, addRequest.PostPermalink)
-
+	}
+	if issue.Recurrence, err = normalizeRecurrence(addRequest.Recurrence, issue.DueAt, p.getUserLocation(userID)); err != nil {
+		p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to parse the recurrence.", err)
 		return
 	}
-
-	receiver, appErr := p.API.GetUserByUsername(addRequest.SendTo)
-	if appErr != nil {
-		msg := "Unable to find user"
-		p.API.LogError(msg, "err", appErr.Error())
-		p.handleErrorWithCode(w, http.StatusInternalServerError, msg, appErr)
+	labels, err := normalizeLabels(addRequest.Labels)
+	if err != nil {
+		p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to parse the tags.", err)
 		return
 	}
+	issue.Labels = mergeLabels(messageLabels(issue.Message), labels)
+
+	var receivers []*model.User
+	if addRequest.SendTo != "" {
+		var includesSender bool
+		receivers, includesSender, err = p.resolveRecipients(userID, addRequest.TeamID, parseRecipients(addRequest.SendTo))
+		if err == nil && len(receivers) == 0 && !includesSender {
+			err = fmt.Errorf("%w: there is nobody else to send the todo to", ErrInvalidRecipients)
+		}
+		if err != nil {
+			code := http.StatusInternalServerError
+			if errors.Is(err, ErrInvalidRecipients) {
+				code = http.StatusBadRequest
+			}
+			msg := "Unable to find user"
+			p.API.LogError(msg, "err", err.Error())
+			p.handleErrorWithCode(w, code, msg, err)
+			return
+		}
+	}
 
-	if receiver.Id == userID {
-		_, err = p.listManager.AddIssue(userID, addRequest.Message, addRequest.Description, addRequest.PostID, addRequest.PostPermalink)
+	if len(receivers) == 0 {
+		err = p.listManager.AddIssue(userID, issue)
 		if err != nil {
 			p.API.LogError(ErrorMsgAddIssue, "err", err.Error())
 			p.handleErrorWithCode(w, http.StatusInternalServerError, ErrorMsgAddIssue, err)
@@ -252,21 +426,12 @@ func (p *Plugin) handleAdd(w http.ResponseWriter, r *http.Request) {
 
 		replyMessage := fmt.Sprintf("@%s attached a todo to this thread", senderName)
 		p.postReplyIfNeeded(addRequest.PostID, replyMessage, addRequest.Message, addRequest.PostPermalink)
-		return
-	}
 
-	receiverAllowIncomingTaskRequestsPreference, err := p.getAllowIncomingTaskRequestsPreference(receiver.Id)
-	if err != nil {
-		p.API.LogError("Error when getting allow incoming task request preference, err=", err)
-		receiverAllowIncomingTaskRequestsPreference = true
-	}
-	if !receiverAllowIncomingTaskRequestsPreference {
-		replyMessage := fmt.Sprintf("@%s has blocked Todo requests", receiver.Username)
-		p.PostBotDM(userID, replyMessage)
 		return
 	}
 
-	issueID, err := p.listManager.SendIssue(userID, receiver.Id, addRequest.Message, addRequest.PostPermalink, addRequest.Description, addRequest.PostID)
+	receiverMessage := fmt.Sprintf("You have received a new Todo from @%s", senderName)
+	sent, blocked, err := p.sendIssueToReceivers(userID, issue, receivers, receiverMessage)
 	if err != nil {
 		msg := "Unable to send issue"
 		p.API.LogError(msg, "err", err.Error())
@@ -274,15 +439,16 @@ func (p *Plugin) handleAdd(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	p.trackSendIssue(userID, sourceWebapp, addRequest.PostID != "")
-
-	p.sendRefreshEvent(userID, []string{OutListKey})
-	p.sendRefreshEvent(receiver.Id, []string{InListKey})
+	if len(blocked) > 0 {
+		p.PostBotDM(userID, blockedText(blocked))
+	}
+	if len(sent) == 0 {
+		return
+	}
 
-	receiverMessage := fmt.Sprintf("You have received a new Todo from @%s", senderName)
-	p.PostBotCustomDM(receiver.Id, receiverMessage, addRequest.Message, addRequest.PostPermalink, issueID)
+	p.trackSendIssue(userID, sourceWebapp, addRequest.PostID != "")
 
-	replyMessage := fmt.Sprintf("@%s sent @%s a todo attached to this thread", senderName, addRequest.SendTo)
+	replyMessage := fmt.Sprintf("@%s sent %s a todo attached to this thread", senderName, usernamesText(sent))
 	p.postReplyIfNeeded(addRequest.PostID, replyMessage, addRequest.Message, addRequest.PostPermalink)
 }
 
@@ -306,31 +472,26 @@ func (p *Plugin) handleLists(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if allListIssue != nil && len(allListIssue.My) > 0 && r.URL.Query().Get("reminder") == "true" && p.getReminderPreference(userID) {
-		var lastReminderAt int64
-		lastReminderAt, err = p.getLastReminderTimeForUser(userID)
+	if tag := r.URL.Query().Get("tag"); tag != "" {
+		label, err := normalizeLabel(tag)
 		if err != nil {
-			msg := "Unable to send reminder"
-			p.API.LogError(msg, "err", err.Error())
-			p.handleErrorWithCode(w, http.StatusInternalServerError, msg, err)
+			p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to parse the tag.", err)
 			return
 		}
+		allListIssue.In = filterIssuesByLabel(allListIssue.In, label)
+		allListIssue.My = filterIssuesByLabel(allListIssue.My, label)
+		allListIssue.Out = filterIssuesByLabel(allListIssue.Out, label)
+		for _, list := range allListIssue.Lists {
+			list.Issues = filterIssuesByLabel(list.Issues, label)
+		}
+	}
 
-		var timezone *time.Location
-		offset, _ := strconv.Atoi(r.Header.Get("X-Timezone-Offset"))
-		timezone = time.FixedZone("local", -60*offset)
-
-		// Post reminder message if it's the next day and been more than an hour since the last post
-		now := model.GetMillis()
-		nt := time.Unix(now/1000, 0).In(timezone)
-		lt := time.Unix(lastReminderAt/1000, 0).In(timezone)
-		if nt.Sub(lt).Hours() >= 1 && (nt.Day() != lt.Day() || nt.Month() != lt.Month() || nt.Year() != lt.Year()) {
-			p.PostBotDM(userID, "Daily Reminder:\n\n"+issuesListToString(allListIssue.My))
-			p.trackDailySummary(userID)
-			err = p.saveLastReminderTimeForUser(userID)
-			if err != nil {
-				p.API.LogError("Unable to save last reminder for user err=" + err.Error())
-			}
+	if r.URL.Query().Get("sort") == sortByPriority {
+		sortIssuesByPriority(allListIssue.In)
+		sortIssuesByPriority(allListIssue.My)
+		sortIssuesByPriority(allListIssue.Out)
+		for _, list := range allListIssue.Lists {
+			sortIssuesByPriority(list.Issues)
 		}
 	}
 
@@ -364,7 +525,47 @@ func (p *Plugin) handleEdit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	foreignUserID, list, oldMessage, err := p.listManager.EditIssue(userID, editRequest.ID, editRequest.Message, editRequest.Description)
+	if editRequest.ID, err = p.listManager.ResolveIssueID(userID, editRequest.ID); err != nil {
+		p.handleIssueNotResolved(w, err)
+		return
+	}
+
+	update := &IssueUpdate{
+		Message:     editRequest.Message,
+		Description: editRequest.Description,
+		DueAt:       editRequest.DueAt,
+		Priority:    editRequest.Priority,
+	}
+	if editRequest.Recurrence != nil {
+		var dueAt int64
+		if editRequest.DueAt != nil {
+			dueAt = *editRequest.DueAt
+		} else {
+			// Monthly rules are anchored to the day the todo is already due
+			issue, err := p.listManager.GetIssue(userID, editRequest.ID)
+			if err != nil {
+				p.handleIssueNotResolved(w, err)
+				return
+			}
+			dueAt = issue.DueAt
+		}
+		recurrence, err := normalizeRecurrence(*editRequest.Recurrence, dueAt, p.getUserLocation(userID))
+		if err != nil {
+			p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to parse the recurrence.", err)
+			return
+		}
+		update.Recurrence = &recurrence
+	}
+	if editRequest.Labels != nil {
+		labels, err := normalizeLabels(*editRequest.Labels)
+		if err != nil {
+			p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to parse the tags.", err)
+			return
+		}
+		update.Labels = &labels
+	}
+
+	foreignUserID, list, oldMessage, err := p.listManager.EditIssue(userID, editRequest.ID, update)
 	if err != nil {
 		msg := "Unable to edit message"
 		p.API.LogError(msg, "err", err.Error())
@@ -373,21 +574,8 @@ func (p *Plugin) handleEdit(w http.ResponseWriter, r *http.Request) {
 	}
 
 	p.trackEditIssue(userID)
-	p.sendRefreshEvent(userID, []string{list})
-
-	if foreignUserID != "" {
-		var lists []string
-		if list == OutListKey {
-			lists = []string{MyListKey, InListKey}
-		} else {
-			lists = []string{OutListKey}
-		}
-		p.sendRefreshEvent(foreignUserID, lists)
 
-		userName := p.listManager.GetUserName(userID)
-		message := fmt.Sprintf("@%s modified a Todo from:\n%s\nTo:\n%s", userName, oldMessage, editRequest.Message)
-		p.PostBotDM(foreignUserID, message)
-	}
+	p.notifyEdit(userID, foreignUserID, list, oldMessage, editRequest.Message)
 }
 
 func (p *Plugin) handleChangeAssignment(w http.ResponseWriter, r *http.Request) {
@@ -406,6 +594,11 @@ func (p *Plugin) handleChangeAssignment(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if changeRequest.ID, err = p.listManager.ResolveIssueID(userID, changeRequest.ID); err != nil {
+		p.handleIssueNotResolved(w, err)
+		return
+	}
+
 	receiver, appErr := p.API.GetUserByUsername(changeRequest.SendTo)
 	if appErr != nil {
 		msg := "username not valid"
@@ -416,27 +609,19 @@ func (p *Plugin) handleChangeAssignment(w http.ResponseWriter, r *http.Request)
 
 	issue, oldOwner, err := p.listManager.ChangeAssignment(changeRequest.ID, userID, receiver.Id)
 	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, ErrSentToGroup) {
+			code = http.StatusBadRequest
+		}
 		msg := "Unable to change the assignment of an issue"
 		p.API.LogError(msg, "err", err.Error())
-		p.handleErrorWithCode(w, http.StatusInternalServerError, msg, err)
+		p.handleErrorWithCode(w, code, msg, err)
 		return
 	}
 
 	p.trackChangeAssignment(userID)
 
-	p.sendRefreshEvent(userID, []string{MyListKey, OutListKey})
-
-	userName := p.listManager.GetUserName(userID)
-	if receiver.Id != userID {
-		p.sendRefreshEvent(receiver.Id, []string{InListKey})
-		receiverMessage := fmt.Sprintf("You have received a new Todo from @%s", userName)
-		p.PostBotCustomDM(receiver.Id, receiverMessage, issue.Message, issue.PostPermalink, changeRequest.ID)
-	}
-	if oldOwner != "" {
-		p.sendRefreshEvent(oldOwner, []string{InListKey, MyListKey})
-		oldOwnerMessage := fmt.Sprintf("@%s removed you from Todo:\n%s", userName, issue.Message)
-		p.PostBotDM(oldOwner, oldOwnerMessage)
-	}
+	p.notifyChangeAssignment(userID, receiver.Id, issue, changeRequest.ID, oldOwner)
 }
 
 func (p *Plugin) handleAccept(w http.ResponseWriter, r *http.Request) {
@@ -455,6 +640,11 @@ func (p *Plugin) handleAccept(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if acceptRequest.ID, err = p.listManager.ResolveIssueID(userID, acceptRequest.ID); err != nil {
+		p.handleIssueNotResolved(w, err)
+		return
+	}
+
 	todoMessage, sender, err := p.listManager.AcceptIssue(userID, acceptRequest.ID)
 	if err != nil {
 		msg := "Unable to accept issue"
@@ -465,12 +655,7 @@ func (p *Plugin) handleAccept(w http.ResponseWriter, r *http.Request) {
 
 	p.trackAcceptIssue(userID)
 
-	p.sendRefreshEvent(userID, []string{MyListKey, InListKey})
-	p.sendRefreshEvent(sender, []string{OutListKey})
-
-	userName := p.listManager.GetUserName(userID)
-	message := fmt.Sprintf("@%s accepted a Todo you sent: %s", userName, todoMessage)
-	p.PostBotDM(sender, message)
+	p.notifyAccept(userID, todoMessage, sender)
 }
 
 func (p *Plugin) handleComplete(w http.ResponseWriter, r *http.Request) {
@@ -489,6 +674,11 @@ func (p *Plugin) handleComplete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if completeRequest.ID, err = p.listManager.ResolveIssueID(userID, completeRequest.ID); err != nil {
+		p.handleIssueNotResolved(w, err)
+		return
+	}
+
 	issue, foreignID, listToUpdate, err := p.listManager.CompleteIssue(userID, completeRequest.ID)
 	if err != nil {
 		msg := "Unable to complete issue"
@@ -497,78 +687,165 @@ func (p *Plugin) handleComplete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	p.sendRefreshEvent(userID, []string{listToUpdate})
-
 	p.trackCompleteIssue(userID)
 
-	userName := p.listManager.GetUserName(userID)
-	replyMessage := fmt.Sprintf("@%s completed a todo attached to this thread", userName)
-	p.postReplyIfNeeded(issue.PostID, replyMessage, issue.Message, issue.PostPermalink)
+	p.notifyComplete(userID, issue, foreignID, listToUpdate)
+	p.repeatIssue(userID, issue, listToUpdate, foreignID)
+}
 
-	if foreignID == "" {
+func (p *Plugin) handleDone(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	query := r.URL.Query()
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 0 {
+		page = 0
+	}
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil || perPage <= 0 || perPage > MaxDonePerPage {
+		perPage = DefaultDonePerPage
+	}
+
+	issues, err := p.listManager.GetDoneIssueList(userID, page, perPage)
+	if err != nil {
+		msg := "Unable to get completed issues for user"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusInternalServerError, msg, err)
+		return
+	}
+
+	issuesJSON, err := json.Marshal(issues)
+	if err != nil {
+		msg := "Unable marhsal completed issues to json"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusInternalServerError, msg, err)
 		return
 	}
 
-	p.sendRefreshEvent(foreignID, []string{OutListKey})
+	_, err = w.Write(issuesJSON)
+	if err != nil {
+		p.API.LogError("Unable to write json response while listing completed issues err=" + err.Error())
+	}
+}
 
-	if issue.PostPermalink != "" {
-		issue.Message = fmt.Sprintf("%s\n[Permalink](%s)", issue.Message, issue.PostPermalink)
+// handleFsck reports the inconsistencies of the lists of the user_id query parameter, or of all users if
+// missing. POST requests also fix them.
+func (p *Plugin) handleFsck(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID != "" && !model.IsValidId(userID) {
+		p.handleErrorWithCode(w, http.StatusBadRequest, "Invalid user_id", errors.New("user_id must be a valid ID"))
+		return
 	}
 
-	message := fmt.Sprintf("@%s completed a Todo you sent: %s", userName, issue.Message)
-	p.PostBotDM(foreignID, message)
+	report, err := p.listManager.Fsck(userID, r.Method == http.MethodPost)
+	if err != nil {
+		msg := "Unable to check the todo lists"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusInternalServerError, msg, err)
+		return
+	}
+
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		msg := "Unable to marshal fsck report to json"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusInternalServerError, msg, err)
+		return
+	}
+
+	_, err = w.Write(reportJSON)
+	if err != nil {
+		p.API.LogError("Unable to write json response while checking lists err=" + err.Error())
+	}
 }
 
-func (p *Plugin) handleRemove(w http.ResponseWriter, r *http.Request) {
+func (p *Plugin) handleReopen(w http.ResponseWriter, r *http.Request) {
 	userID := r.Header.Get("Mattermost-User-ID")
 
-	removeRequest, err := GetRemoveIssuePayloadFromJSON(r.Body)
+	reopenRequest, err := GetReopenIssuePayloadFromJSON(r.Body)
 	if err != nil {
-		msg := "Unable to get remove issue request payload from JSON"
+		msg := "Unable to get reopen issue request payload from JSON"
 		p.API.LogError(msg, "err", err.Error())
 		p.handleErrorWithCode(w, http.StatusBadRequest, msg, err)
 		return
 	}
 
-	if err = removeRequest.IsValid(); err != nil {
-		p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to validate remove issue request payload.", err)
+	if err = reopenRequest.IsValid(); err != nil {
+		p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to validate reopen issue request payload.", err)
 		return
 	}
 
-	issue, foreignID, isSender, listToUpdate, err := p.listManager.RemoveIssue(userID, removeRequest.ID)
+	if reopenRequest.ID, err = p.listManager.ResolveIssueID(userID, reopenRequest.ID); err != nil {
+		p.handleIssueNotResolved(w, err)
+		return
+	}
+
+	issue, foreignID, listToUpdate, err := p.listManager.ReopenIssue(userID, reopenRequest.ID)
 	if err != nil {
-		msg := "Unable to remove issue"
+		code := http.StatusInternalServerError
+		if errors.Is(err, ErrCannotReopen) {
+			code = http.StatusBadRequest
+		}
+		msg := "Unable to reopen issue"
 		p.API.LogError(msg, "err", err.Error())
-		p.handleErrorWithCode(w, http.StatusInternalServerError, msg, err)
+		p.handleErrorWithCode(w, code, msg, err)
 		return
 	}
-	p.sendRefreshEvent(userID, []string{listToUpdate})
 
-	p.trackRemoveIssue(userID)
+	p.sendRefreshEvent(userID, []string{listToUpdate, DoneListKey})
+	if issue.Recurrence != "" {
+		// The next occurrence may have been removed
+		p.sendRefreshEvent(userID, []string{MyListKey, InListKey, OutListKey})
+	}
 
-	userName := p.listManager.GetUserName(userID)
-	replyMessage := fmt.Sprintf("@%s removed a todo attached to this thread", userName)
-	p.postReplyIfNeeded(issue.PostID, replyMessage, issue.Message, issue.PostPermalink)
+	p.trackReopenIssue(userID)
 
 	if foreignID == "" {
 		return
 	}
 
-	list := InListKey
-
-	message := fmt.Sprintf("@%s removed a Todo you received: %s", userName, issue.Message)
-	if isSender {
-		message = fmt.Sprintf("@%s declined a Todo you sent: %s", userName, issue.Message)
-		list = OutListKey
-	}
+	p.sendRefreshEvent(foreignID, []string{MyListKey, InListKey, OutListKey, DoneListKey})
 
+	userName := p.listManager.GetUserName(userID)
+	message := fmt.Sprintf("@%s reopened a Todo: %s", userName, issue.Message)
 	if issue.PostPermalink != "" {
 		message = fmt.Sprintf("%s\n[Permalink](%s)", message, issue.PostPermalink)
 	}
+	p.PostBotDM(foreignID, message)
+}
 
-	p.sendRefreshEvent(foreignID, []string{list})
+func (p *Plugin) handleRemove(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
 
-	p.PostBotDM(foreignID, message)
+	removeRequest, err := GetRemoveIssuePayloadFromJSON(r.Body)
+	if err != nil {
+		msg := "Unable to get remove issue request payload from JSON"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusBadRequest, msg, err)
+		return
+	}
+
+	if err = removeRequest.IsValid(); err != nil {
+		p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to validate remove issue request payload.", err)
+		return
+	}
+
+	if removeRequest.ID, err = p.listManager.ResolveIssueID(userID, removeRequest.ID); err != nil {
+		p.handleIssueNotResolved(w, err)
+		return
+	}
+
+	issue, foreignID, isSender, listToUpdate, err := p.listManager.RemoveIssue(userID, removeRequest.ID)
+	if err != nil {
+		msg := "Unable to remove issue"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusInternalServerError, msg, err)
+		return
+	}
+
+	p.trackRemoveIssue(userID)
+
+	p.notifyRemove(userID, issue, foreignID, isSender, listToUpdate)
 }
 
 func (p *Plugin) handleBump(w http.ResponseWriter, r *http.Request) {
@@ -587,40 +864,626 @@ func (p *Plugin) handleBump(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if bumpRequest.ID, err = p.listManager.ResolveIssueID(userID, bumpRequest.ID); err != nil {
+		p.handleIssueNotResolved(w, err)
+		return
+	}
+
 	todo, foreignUser, foreignIssueID, err := p.listManager.BumpIssue(userID, bumpRequest.ID)
 	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, ErrSentToGroup) {
+			code = http.StatusBadRequest
+		}
 		msg := "Unable to bump issue"
 		p.API.LogError(msg, "err", err.Error())
-		p.handleErrorWithCode(w, http.StatusInternalServerError, msg, err)
+		p.handleErrorWithCode(w, code, msg, err)
 		return
 	}
 
 	p.trackBumpIssue(userID)
 
-	if foreignUser == "" {
+	p.notifyBump(userID, todo, foreignUser, foreignIssueID)
+}
+
+func (p *Plugin) handleChecklist(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	checklistRequest, err := GetChecklistPayloadFromJSON(r.Body)
+	if err != nil {
+		msg := "Unable to get checklist request payload from JSON"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusBadRequest, msg, err)
 		return
 	}
 
-	p.sendRefreshEvent(foreignUser, []string{InListKey})
-
-	userName := p.listManager.GetUserName(userID)
-	message := fmt.Sprintf("@%s bumped a Todo you received.", userName)
-	p.PostBotCustomDM(foreignUser, message, todo.Message, todo.PostPermalink, foreignIssueID)
-}
+	if err = checklistRequest.IsValid(); err != nil {
+		p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to validate checklist request payload.", err)
+		return
+	}
 
-// API endpoint to retrieve plugin configurations
-func (p *Plugin) handleConfig(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+	if checklistRequest.ID, err = p.listManager.ResolveIssueID(userID, checklistRequest.ID); err != nil {
+		p.handleIssueNotResolved(w, err)
 		return
 	}
 
-	if p.configuration != nil {
-		// retrieve client only configurations
-		clientConfig := struct {
-			HideTeamSidebar bool `json:"hide_team_sidebar"`
-		}{
-			HideTeamSidebar: p.configuration.HideTeamSidebar,
+	update := checklistRequest.update()
+	issue, foreignUserID, list, err := p.listManager.UpdateChecklist(userID, checklistRequest.ID, update)
+	if err != nil {
+		code := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, ErrIssueNotFound), errors.Is(err, ErrChecklistItemNotFound):
+			code = http.StatusNotFound
+		case errors.Is(err, ErrChecklistFull):
+			code = http.StatusBadRequest
+		}
+		msg := "Unable to update checklist"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, code, msg, err)
+		return
+	}
+
+	p.notifyChecklist(userID, foreignUserID, list, issue, update)
+
+	issueJSON, err := json.Marshal(issue)
+	if err != nil {
+		p.API.LogError("Unable to marshal issue to json err=" + err.Error())
+		return
+	}
+
+	if _, err = w.Write(issueJSON); err != nil {
+		p.API.LogError("Unable to write json response while updating checklist err=" + err.Error())
+	}
+}
+
+func (p *Plugin) handleSnooze(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	snoozeRequest, err := GetSnoozeIssuePayloadFromJSON(r.Body)
+	if err != nil {
+		msg := "Unable to get snooze issue request payload from JSON"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusBadRequest, msg, err)
+		return
+	}
+
+	if err = snoozeRequest.IsValid(); err != nil {
+		p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to validate snooze request payload.", err)
+		return
+	}
+
+	if snoozeRequest.When != "" {
+		snoozeRequest.Until, err = parseSnoozeTime(snoozeRequest.When, p.getUserLocation(userID))
+		if err != nil {
+			p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to parse the snooze time.", err)
+			return
+		}
+	}
+
+	if snoozeRequest.ID, err = p.listManager.ResolveIssueID(userID, snoozeRequest.ID); err != nil {
+		p.handleIssueNotResolved(w, err)
+		return
+	}
+
+	if _, err = p.listManager.SnoozeIssue(userID, snoozeRequest.ID, snoozeRequest.Until); err != nil {
+		code := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, ErrIssueNotFound):
+			code = http.StatusNotFound
+		case errors.Is(err, ErrCannotSnooze):
+			code = http.StatusBadRequest
+		}
+		msg := "Unable to snooze issue"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, code, msg, err)
+		return
+	}
+
+	p.trackSnoozeIssue(userID)
+
+	p.sendRefreshEvent(userID, []string{MyListKey, InListKey})
+}
+
+func (p *Plugin) handleUnsnooze(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	unsnoozeRequest, err := GetUnsnoozeIssuePayloadFromJSON(r.Body)
+	if err != nil {
+		msg := "Unable to get unsnooze issue request payload from JSON"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusBadRequest, msg, err)
+		return
+	}
+
+	if err = unsnoozeRequest.IsValid(); err != nil {
+		p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to validate unsnooze request payload.", err)
+		return
+	}
+
+	if unsnoozeRequest.ID, err = p.listManager.ResolveIssueID(userID, unsnoozeRequest.ID); err != nil {
+		p.handleIssueNotResolved(w, err)
+		return
+	}
+
+	_, listID, err := p.listManager.WakeIssue(userID, unsnoozeRequest.ID)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, ErrIssueNotFound) {
+			code = http.StatusNotFound
+		}
+		msg := "Unable to unsnooze issue"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, code, msg, err)
+		return
+	}
+
+	p.sendRefreshEvent(userID, []string{listID})
+}
+
+func (p *Plugin) handleUndo(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	tombstone, err := p.listManager.UndoLastAction(userID)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, ErrNothingToUndo) {
+			code = http.StatusNotFound
+		}
+		msg := "Unable to undo last action"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, code, msg, err)
+		return
+	}
+
+	p.trackUndo(userID, tombstone.Action)
+
+	p.notifyUndo(userID, tombstone)
+
+	issueJSON, err := json.Marshal(tombstone.Issue)
+	if err != nil {
+		p.API.LogError("Unable marhsal restored issue to json err=" + err.Error())
+		return
+	}
+
+	_, err = w.Write(issueJSON)
+	if err != nil {
+		p.API.LogError("Unable to write json response while undoing err=" + err.Error())
+	}
+}
+
+func (p *Plugin) handleCreateList(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	createRequest, err := GetCreateListPayloadFromJSON(r.Body)
+	if err != nil {
+		msg := "Unable to get create list request payload from JSON"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusBadRequest, msg, err)
+		return
+	}
+
+	if err = createRequest.IsValid(); err != nil {
+		p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to validate create list request payload.", err)
+		return
+	}
+
+	list, err := p.listManager.CreateList(userID, createRequest.Name)
+	if err != nil {
+		p.handleCustomListError(w, "Unable to create list", err)
+		return
+	}
+
+	p.trackCustomList(userID, "create")
+
+	p.sendRefreshEvent(userID, []string{list.ID})
+
+	p.writeCustomList(w, list)
+}
+
+func (p *Plugin) handleRenameList(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	renameRequest, err := GetRenameListPayloadFromJSON(r.Body)
+	if err != nil {
+		msg := "Unable to get rename list request payload from JSON"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusBadRequest, msg, err)
+		return
+	}
+
+	if err = renameRequest.IsValid(); err != nil {
+		p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to validate rename list request payload.", err)
+		return
+	}
+
+	list, err := p.listManager.RenameList(userID, renameRequest.ID, renameRequest.Name)
+	if err != nil {
+		p.handleCustomListError(w, "Unable to rename list", err)
+		return
+	}
+
+	p.trackCustomList(userID, "rename")
+
+	p.sendRefreshEvent(userID, []string{list.ID})
+
+	p.writeCustomList(w, list)
+}
+
+func (p *Plugin) handleDeleteList(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	deleteRequest, err := GetDeleteListPayloadFromJSON(r.Body)
+	if err != nil {
+		msg := "Unable to get delete list request payload from JSON"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusBadRequest, msg, err)
+		return
+	}
+
+	if err = deleteRequest.IsValid(); err != nil {
+		p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to validate delete list request payload.", err)
+		return
+	}
+
+	list, _, err := p.listManager.DeleteList(userID, deleteRequest.ID)
+	if err != nil {
+		p.handleCustomListError(w, "Unable to delete list", err)
+		return
+	}
+
+	p.trackCustomList(userID, "delete")
+
+	p.sendRefreshEvent(userID, []string{MyListKey, list.ID})
+}
+
+func (p *Plugin) handleMoveList(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	moveRequest, err := GetMoveListPayloadFromJSON(r.Body)
+	if err != nil {
+		msg := "Unable to get move list request payload from JSON"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusBadRequest, msg, err)
+		return
+	}
+
+	if err = moveRequest.IsValid(); err != nil {
+		p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to validate move list request payload.", err)
+		return
+	}
+
+	if err = p.listManager.MoveList(userID, moveRequest.ID, moveRequest.Position); err != nil {
+		p.handleCustomListError(w, "Unable to move list", err)
+		return
+	}
+
+	p.trackCustomList(userID, "move")
+
+	p.sendRefreshEvent(userID, []string{moveRequest.ID})
+}
+
+func (p *Plugin) handleMoveIssue(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	moveRequest, err := GetMoveIssuePayloadFromJSON(r.Body)
+	if err != nil {
+		msg := "Unable to get move issue request payload from JSON"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusBadRequest, msg, err)
+		return
+	}
+
+	if err = moveRequest.IsValid(); err != nil {
+		p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to validate move issue request payload.", err)
+		return
+	}
+
+	if moveRequest.ID, err = p.listManager.ResolveIssueID(userID, moveRequest.ID); err != nil {
+		p.handleIssueNotResolved(w, err)
+		return
+	}
+
+	listID := moveRequest.List
+	if listID == MyFlag {
+		listID = MyListKey
+	}
+
+	fromListID, err := p.listManager.MoveIssueToList(userID, moveRequest.ID, listID)
+	if err != nil {
+		if errors.Is(err, ErrIssueNotFound) {
+			p.handleIssueNotResolved(w, err)
+			return
+		}
+		p.handleCustomListError(w, "Unable to move issue", err)
+		return
+	}
+
+	p.trackMoveIssue(userID)
+
+	p.sendRefreshEvent(userID, []string{fromListID, listID})
+}
+
+func (p *Plugin) handleReorder(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	reorderRequest, err := GetReorderPayloadFromJSON(r.Body)
+	if err != nil {
+		msg := "Unable to get reorder request payload from JSON"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusBadRequest, msg, err)
+		return
+	}
+
+	if err = reorderRequest.IsValid(); err != nil {
+		p.handleErrorWithCode(w, http.StatusBadRequest, "Unable to validate reorder request payload.", err)
+		return
+	}
+
+	listID, ok := listIDFromFlag(reorderRequest.List)
+	if !ok {
+		listID = reorderRequest.List
+	}
+
+	issueIDs := make([]string, len(reorderRequest.Order))
+	for i, ref := range reorderRequest.Order {
+		if issueIDs[i], err = p.listManager.ResolveIssueID(userID, ref); err != nil {
+			p.handleIssueNotResolved(w, err)
+			return
+		}
+	}
+
+	if err = p.listManager.ReorderIssues(userID, listID, issueIDs); err != nil {
+		code := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, ErrListNotFound), errors.Is(err, ErrIssueNotFound):
+			code = http.StatusNotFound
+		case errors.Is(err, ErrInvalidOrder):
+			code = http.StatusBadRequest
+		}
+		msg := "Unable to reorder list"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, code, msg, err)
+		return
+	}
+
+	p.trackReorder(userID, len(issueIDs))
+
+	p.sendRefreshEvent(userID, []string{listID})
+}
+
+// handleCustomListError writes the error of a request on the custom lists of a user
+func (p *Plugin) handleCustomListError(w http.ResponseWriter, msg string, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrListNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, ErrCannotMove) || isCustomListUserError(err):
+		code = http.StatusBadRequest
+	default:
+		p.API.LogError(msg, "err", err.Error())
+	}
+	p.handleErrorWithCode(w, code, msg, err)
+}
+
+func (p *Plugin) writeCustomList(w http.ResponseWriter, list *CustomList) {
+	listJSON, err := json.Marshal(list)
+	if err != nil {
+		p.API.LogError("Unable marhsal list to json err=" + err.Error())
+		return
+	}
+
+	if _, err = w.Write(listJSON); err != nil {
+		p.API.LogError("Unable to write json response of list err=" + err.Error())
+	}
+}
+
+// handleIssueNotResolved writes the error of a request whose issue cannot be resolved
+func (p *Plugin) handleIssueNotResolved(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	if errors.Is(err, ErrIssueNotFound) {
+		code = http.StatusNotFound
+	}
+	msg := "Unable to find issue"
+	p.API.LogError(msg, "err", err.Error())
+	p.handleErrorWithCode(w, code, msg, err)
+}
+
+// notifyEdit refreshes the lists of both sides of an edited todo, and lets the foreign user know
+func (p *Plugin) notifyEdit(userID, foreignUserID, list, oldMessage, newMessage string) {
+	p.sendRefreshEvent(userID, []string{list})
+
+	if foreignUserID == "" {
+		return
+	}
+
+	lists := []string{OutListKey}
+	if list == OutListKey {
+		lists = []string{MyListKey, InListKey}
+	}
+	p.sendRefreshEvent(foreignUserID, lists)
+
+	userName := p.listManager.GetUserName(userID)
+	message := fmt.Sprintf("@%s modified a Todo from:\n%s\nTo:\n%s", userName, oldMessage, newMessage)
+	p.PostBotDM(foreignUserID, message)
+}
+
+// notifyChecklist refreshes the lists showing the checklist of issue, changed by userID from list, and
+// lets the foreign user know when an item is checked
+func (p *Plugin) notifyChecklist(userID, foreignUserID, list string, issue *Issue, update *ChecklistUpdate) {
+	p.sendRefreshEvent(userID, []string{list})
+
+	if foreignUserID == "" {
+		return
+	}
+
+	lists := []string{OutListKey}
+	if list == OutListKey {
+		lists = []string{MyListKey, InListKey}
+	}
+	p.sendRefreshEvent(foreignUserID, lists)
+
+	if update.Action != ChecklistToggle {
+		return
+	}
+	n := checklistItemIndex(issue.Checklist, update.ItemID)
+	if n < 0 || !issue.Checklist[n].Done {
+		return
+	}
+
+	userName := p.listManager.GetUserName(userID)
+	message := fmt.Sprintf("@%s checked %s on the Todo: %s", userName, issue.Checklist[n].Text, issueSummary(issue))
+	p.PostBotDM(foreignUserID, message)
+}
+
+// notifyChangeAssignment refreshes the lists of the users involved in a reassigned todo, and lets
+// the new and the old assignee know
+func (p *Plugin) notifyChangeAssignment(userID, receiverID string, issue *Issue, issueID, oldOwner string) {
+	p.sendRefreshEvent(userID, []string{MyListKey, OutListKey})
+
+	userName := p.listManager.GetUserName(userID)
+	if receiverID != userID {
+		p.sendRefreshEvent(receiverID, []string{InListKey})
+		receiverMessage := fmt.Sprintf("You have received a new Todo from @%s", userName)
+		p.PostBotCustomDM(receiverID, receiverMessage, issueSummary(issue), issue.PostPermalink, issueID)
+	}
+	if oldOwner != "" {
+		p.sendRefreshEvent(oldOwner, []string{InListKey, MyListKey})
+		oldOwnerMessage := fmt.Sprintf("@%s removed you from Todo:\n%s", userName, issue.Message)
+		p.PostBotDM(oldOwner, oldOwnerMessage)
+	}
+}
+
+// notifyBump refreshes the inbox of the receiver of a bumped todo, and lets them know
+func (p *Plugin) notifyBump(userID string, todo *Issue, receiverID, receiverIssueID string) {
+	if receiverID == "" {
+		return
+	}
+
+	p.sendRefreshEvent(receiverID, []string{InListKey})
+
+	userName := p.listManager.GetUserName(userID)
+	message := fmt.Sprintf("@%s bumped a Todo you received.", userName)
+	p.PostBotCustomDM(receiverID, message, todo.Message, todo.PostPermalink, receiverIssueID)
+}
+
+// notifyAccept refreshes the lists of both sides of an accepted todo, and lets the sender know
+func (p *Plugin) notifyAccept(userID, todoMessage, sender string) {
+	p.sendRefreshEvent(userID, []string{MyListKey, InListKey})
+	p.sendRefreshEvent(sender, []string{OutListKey})
+
+	userName := p.listManager.GetUserName(userID)
+	message := fmt.Sprintf("@%s accepted a Todo you sent: %s", userName, todoMessage)
+	p.PostBotDM(sender, message)
+}
+
+// notifyComplete refreshes the lists of both sides of a completed todo, and lets the foreign user
+// and the thread the todo was created from know
+func (p *Plugin) notifyComplete(userID string, issue *Issue, foreignID, listToUpdate string) {
+	p.sendRefreshEvent(userID, []string{listToUpdate, DoneListKey})
+
+	userName := p.listManager.GetUserName(userID)
+	replyMessage := fmt.Sprintf("@%s completed a todo attached to this thread", userName)
+	p.postReplyIfNeeded(issue.PostID, replyMessage, issue.Message, issue.PostPermalink)
+
+	if foreignID == "" {
+		return
+	}
+
+	p.sendRefreshEvent(foreignID, []string{OutListKey, DoneListKey})
+
+	message := issue.Message
+	if issue.PostPermalink != "" {
+		message = fmt.Sprintf("%s\n[Permalink](%s)", message, issue.PostPermalink)
+	}
+
+	p.PostBotDM(foreignID, fmt.Sprintf("@%s completed a Todo you sent: %s", userName, message))
+}
+
+// notifyRemove refreshes the lists of both sides of a removed or declined todo, and lets the foreign
+// user and the thread the todo was created from know
+func (p *Plugin) notifyRemove(userID string, issue *Issue, foreignID string, isSender bool, listToUpdate string) {
+	p.sendRefreshEvent(userID, []string{listToUpdate})
+
+	userName := p.listManager.GetUserName(userID)
+	replyMessage := fmt.Sprintf("@%s removed a todo attached to this thread", userName)
+	p.postReplyIfNeeded(issue.PostID, replyMessage, issue.Message, issue.PostPermalink)
+
+	for _, recipient := range issue.Recipients {
+		p.sendRefreshEvent(recipient.UserID, []string{InListKey, MyListKey, DoneListKey})
+		p.PostBotDM(recipient.UserID, fmt.Sprintf("@%s removed a Todo you received: %s", userName, issue.Message))
+	}
+
+	if foreignID == "" {
+		return
+	}
+
+	list := InListKey
+
+	message := fmt.Sprintf("@%s removed a Todo you received: %s", userName, issue.Message)
+	if isSender {
+		message = fmt.Sprintf("@%s declined a Todo you sent: %s", userName, issue.Message)
+		list = OutListKey
+	}
+
+	if issue.PostPermalink != "" {
+		message = fmt.Sprintf("%s\n[Permalink](%s)", message, issue.PostPermalink)
+	}
+
+	p.sendRefreshEvent(foreignID, []string{list})
+
+	p.PostBotDM(foreignID, message)
+}
+
+// notifyUndo refreshes the lists of both sides of an undone operation, and lets the foreign user know
+func (p *Plugin) notifyUndo(userID string, tombstone *Tombstone) {
+	p.sendRefreshEvent(userID, []string{tombstone.ListID, DoneListKey})
+
+	// The next occurrence of a repeating todo is removed from the lists of its sender and receiver
+	if tombstone.NextIssueID != "" {
+		for _, nextUserID := range []string{userID, tombstone.Ref.ForeignUserID} {
+			if nextUserID != "" {
+				p.sendRefreshEvent(nextUserID, []string{MyListKey, InListKey, OutListKey})
+			}
+		}
+	}
+
+	if tombstone.ForeignRef == nil && len(tombstone.Recipients) == 0 {
+		return
+	}
+
+	userName := p.listManager.GetUserName(userID)
+	message := fmt.Sprintf("@%s restored a Todo: %s", userName, tombstone.Issue.Message)
+	if tombstone.Issue.PostPermalink != "" {
+		message = fmt.Sprintf("%s\n[Permalink](%s)", message, tombstone.Issue.PostPermalink)
+	}
+
+	for _, recipient := range tombstone.Recipients {
+		p.sendRefreshEvent(recipient.UserID, []string{recipient.ListID, DoneListKey})
+		p.PostBotDM(recipient.UserID, message)
+	}
+
+	if tombstone.ForeignRef == nil {
+		return
+	}
+
+	foreignID := tombstone.Ref.ForeignUserID
+	p.sendRefreshEvent(foreignID, []string{tombstone.ForeignListID, DoneListKey})
+	p.PostBotDM(foreignID, message)
+}
+
+// API endpoint to retrieve plugin configurations
+func (p *Plugin) handleConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if p.configuration != nil {
+		// retrieve client only configurations
+		clientConfig := struct {
+			HideTeamSidebar bool `json:"hide_team_sidebar"`
+		}{
+			HideTeamSidebar: p.configuration.HideTeamSidebar,
 		}
 
 		configJSON, err := json.Marshal(clientConfig)
@@ -638,6 +1501,115 @@ func (p *Plugin) handleConfig(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (p *Plugin) handleGetSettings(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	p.writeSettings(w, userID)
+}
+
+func (p *Plugin) handleUpdateSettings(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	settingsRequest, err := GetSettingsPayloadFromJSON(r.Body)
+	if err != nil {
+		msg := "Unable to get settings request payload from JSON"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusBadRequest, msg, err)
+		return
+	}
+
+	if err = settingsRequest.IsValid(); err != nil {
+		msg := "Settings request is not valid"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusBadRequest, msg, err)
+		return
+	}
+
+	if settingsRequest.Summary != nil {
+		err = p.saveReminderPreference(userID, *settingsRequest.Summary)
+	}
+	if err == nil && settingsRequest.ReminderSchedule != nil {
+		err = p.saveReminderSchedule(userID, settingsRequest.ReminderSchedule)
+	}
+	if err == nil && settingsRequest.AllowIncomingTaskRequests != nil {
+		err = p.saveAllowIncomingTaskRequestsPreference(userID, *settingsRequest.AllowIncomingTaskRequests)
+	}
+	if err == nil && settingsRequest.Timezone != nil {
+		err = p.saveTimezonePreference(userID, *settingsRequest.Timezone)
+	}
+	if err != nil {
+		msg := "Unable to save settings"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusInternalServerError, msg, err)
+		return
+	}
+
+	p.writeSettings(w, userID)
+}
+
+func (p *Plugin) writeSettings(w http.ResponseWriter, userID string) {
+	schedule, err := p.getReminderSchedule(userID)
+	if err != nil {
+		msg := "Unable to get reminder schedule"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusInternalServerError, msg, err)
+		return
+	}
+
+	allowIncomingTaskRequests, err := p.getAllowIncomingTaskRequestsPreference(userID)
+	if err != nil {
+		p.API.LogError("Error when getting allow incoming task request preference, err=", err.Error())
+	}
+
+	timezone, err := p.getTimezonePreference(userID)
+	if err != nil {
+		msg := "Unable to get timezone preference"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusInternalServerError, msg, err)
+		return
+	}
+
+	settingsJSON, err := json.Marshal(&SettingsAPIResponse{
+		Summary:                   p.getReminderPreference(userID),
+		ReminderSchedule:          schedule,
+		AllowIncomingTaskRequests: allowIncomingTaskRequests,
+		Timezone:                  timezone,
+	})
+	if err != nil {
+		msg := "Unable to marshal settings to json"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusInternalServerError, msg, err)
+		return
+	}
+
+	_, err = w.Write(settingsJSON)
+	if err != nil {
+		p.API.LogError("Unable to write json response while getting settings err=" + err.Error())
+	}
+}
+
+// getUserLocation returns the timezone stored with the Todo settings of the user, or else the one
+// configured on the user profile, defaulting to UTC
+func (p *Plugin) getUserLocation(userID string) *time.Location {
+	timezone, err := p.getTimezonePreference(userID)
+	if err != nil {
+		p.API.LogWarn("Unable to get timezone preference", "err", err.Error())
+	}
+	if timezone != "" {
+		if loc, err := time.LoadLocation(timezone); err == nil {
+			return loc
+		}
+		p.API.LogWarn("Invalid timezone preference, ignoring it", "timezone", timezone)
+	}
+
+	user, appErr := p.API.GetUser(userID)
+	if appErr != nil {
+		p.API.LogWarn("Unable to get user timezone, defaulting to UTC", "err", appErr.Error())
+		return time.UTC
+	}
+
+	return user.GetTimezoneLocation()
+}
+
 func (p *Plugin) sendRefreshEvent(userID string, lists []string) {
 	p.API.PublishWebSocketEvent(
 		WSEventRefresh,
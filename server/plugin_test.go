@@ -1,11 +1,85 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi/experimental/telemetry"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestServeHTTP(t *testing.T) {
 	assert.True(t, true)
 }
+
+func TestHandleLists(t *testing.T) {
+	userID := model.NewId()
+	store := newMemStore()
+	api := &plugintest.API{}
+	l := &listManager{store: store, api: api}
+	p := &Plugin{listManager: l}
+	p.SetAPI(api)
+
+	work, err := l.CreateList(userID, "work")
+	require.NoError(t, err)
+	for _, issue := range []*Issue{
+		{ID: model.NewId(), Message: "chores", Priority: PriorityLow, Labels: []string{"home"}},
+		{ID: model.NewId(), Message: "deploy", Priority: PriorityLow, Labels: []string{"ops"}},
+		{ID: model.NewId(), Message: "outage", Priority: PriorityHigh, Labels: []string{"ops"}},
+	} {
+		require.NoError(t, store.SaveIssue(issue))
+		require.NoError(t, store.AddReference(userID, issue.ID, work.ID, "", ""))
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/lists?tag=ops&sort=priority", nil)
+	r.Header.Set("Mattermost-User-ID", userID)
+	w := httptest.NewRecorder()
+	p.handleLists(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	lists := &ListsIssue{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(lists))
+	require.Len(t, lists.Lists, 1)
+	messages := []string{}
+	for _, issue := range lists.Lists[0].Issues {
+		messages = append(messages, issue.Message)
+	}
+	assert.Equal(t, []string{"outage", "deploy"}, messages)
+}
+
+func TestHandleEditRecurrence(t *testing.T) {
+	userID := model.NewId()
+	store := newMemStore()
+	api := &plugintest.API{}
+	api.On("KVGet", timezoneKey(userID)).Return([]byte("UTC"), nil)
+	api.On("PublishWebSocketEvent", WSEventRefresh, mock.Anything, mock.Anything).Return()
+	l := &listManager{store: store, api: api}
+	p := &Plugin{
+		listManager: l,
+		tracker:     telemetry.NewTracker(nil, "", "", "", "", "", telemetry.TrackerConfig{}, nil),
+	}
+	p.SetAPI(api)
+
+	issue := newIssue("pay rent", "", "", "")
+	issue.DueAt = time.Date(2025, time.March, 28, 23, 59, 0, 0, time.UTC).UnixMilli()
+	require.NoError(t, l.AddIssue(userID, issue))
+
+	recurrence := "monthly"
+	body, err := json.Marshal(&EditAPIRequest{ID: issue.ID, Message: issue.Message, Recurrence: &recurrence})
+	require.NoError(t, err)
+	r := httptest.NewRequest(http.MethodPost, "/edit", bytes.NewReader(body))
+	r.Header.Set("Mattermost-User-ID", userID)
+	w := httptest.NewRecorder()
+	p.handleEdit(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, "FREQ=MONTHLY;BYMONTHDAY=28", store.issues[issue.ID].Recurrence)
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+const (
+	// PostActionAccept accepts a received todo
+	PostActionAccept = "accept"
+	// PostActionDecline declines a received todo
+	PostActionDecline = "decline"
+	// PostActionComplete completes a todo
+	PostActionComplete = "complete"
+	// PostActionSnooze snoozes a todo for the time chosen on a menu
+	PostActionSnooze = "snooze"
+
+	postActionIssueIDKey = "issue_id"
+	// postActionSelectedOptionKey is the context key where the server sets the value chosen on select actions
+	postActionSelectedOptionKey = "selected_option"
+	maxActionOptionLength       = 80
+)
+
+func postActionURL(action string) string {
+	return fmt.Sprintf("/plugins/%s/action/%s", manifest.Id, action)
+}
+
+// issueActions returns the buttons to accept, decline, complete and snooze a received todo
+func issueActions(issueID string) []*model.PostAction {
+	return []*model.PostAction{
+		issueButton(issueID, "Accept", PostActionAccept, "primary"),
+		issueButton(issueID, "Decline", PostActionDecline, "danger"),
+		issueButton(issueID, "Complete", PostActionComplete, "success"),
+		snoozeSelectAction(issueID),
+	}
+}
+
+// ownIssueActions returns the buttons to complete and snooze a todo of the user's own list
+func ownIssueActions(issueID string) []*model.PostAction {
+	return []*model.PostAction{
+		issueButton(issueID, "Complete", PostActionComplete, "success"),
+		snoozeSelectAction(issueID),
+	}
+}
+
+func issueButton(issueID, name, action, style string) *model.PostAction {
+	return &model.PostAction{
+		Id:    action,
+		Type:  model.PostActionTypeButton,
+		Name:  name,
+		Style: style,
+		Integration: &model.PostActionIntegration{
+			URL:     postActionURL(action),
+			Context: map[string]interface{}{postActionIssueIDKey: issueID},
+		},
+	}
+}
+
+// snoozeSelectAction returns a menu to snooze issueID, whose options are snooze times as accepted by
+// parseSnoozeTime
+func snoozeSelectAction(issueID string) *model.PostAction {
+	return &model.PostAction{
+		Id:   PostActionSnooze,
+		Type: model.PostActionTypeSelect,
+		Name: "Snooze...",
+		Options: []*model.PostActionOptions{
+			{Text: "1 hour", Value: "in 1 hour"},
+			{Text: "Tomorrow", Value: "tomorrow"},
+			{Text: "Next week", Value: "next week"},
+		},
+		Integration: &model.PostActionIntegration{
+			URL:     postActionURL(PostActionSnooze),
+			Context: map[string]interface{}{postActionIssueIDKey: issueID},
+		},
+	}
+}
+
+// completeSelectAction returns a menu to complete any of issues
+func completeSelectAction(issues []*ExtendedIssue) *model.PostAction {
+	options := []*model.PostActionOptions{}
+	for _, issue := range issues {
+		options = append(options, &model.PostActionOptions{Text: truncateText(issue.Message, maxActionOptionLength), Value: issue.ID})
+	}
+
+	return &model.PostAction{
+		Id:      PostActionComplete,
+		Type:    model.PostActionTypeSelect,
+		Name:    "Complete a Todo...",
+		Options: options,
+		Integration: &model.PostActionIntegration{
+			URL: postActionURL(PostActionComplete),
+		},
+	}
+}
+
+// handlePostAction runs the action of a button or menu on a bot post, and updates the post with the outcome
+func (p *Plugin) handlePostAction(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	action := mux.Vars(r)["action"]
+
+	request := &model.PostActionIntegrationRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		msg := "Unable to get post action request payload from JSON"
+		p.API.LogError(msg, "err", err.Error())
+		p.handleErrorWithCode(w, http.StatusBadRequest, msg, err)
+		return
+	}
+
+	// The selected option is the issue on menus listing issues, and the snooze time on the snooze menu
+	issueID, _ := request.Context[postActionIssueIDKey].(string)
+	selected, _ := request.Context[postActionSelectedOptionKey].(string)
+	if selected != "" && action != PostActionSnooze {
+		issueID = selected
+	}
+	if issueID == "" {
+		p.writePostActionResponse(w, &model.PostActionIntegrationResponse{EphemeralText: "Missing Todo to update."})
+		return
+	}
+
+	var outcome string
+	var err error
+	switch action {
+	case PostActionAccept:
+		var todoMessage, sender string
+		todoMessage, sender, err = p.listManager.AcceptIssue(userID, issueID)
+		if err == nil {
+			p.trackAcceptIssue(userID)
+			p.notifyAccept(userID, todoMessage, sender)
+			outcome = "You accepted this Todo."
+		}
+	case PostActionDecline:
+		var issue *Issue
+		var foreignID, listToUpdate string
+		var isSender bool
+		issue, foreignID, isSender, listToUpdate, err = p.listManager.RemoveIssue(userID, issueID)
+		if err == nil {
+			p.trackRemoveIssue(userID)
+			p.notifyRemove(userID, issue, foreignID, isSender, listToUpdate)
+			outcome = "You declined this Todo."
+		}
+	case PostActionComplete:
+		var issue *Issue
+		var foreignID, listToUpdate string
+		issue, foreignID, listToUpdate, err = p.listManager.CompleteIssue(userID, issueID)
+		if err == nil {
+			p.trackCompleteIssue(userID)
+			p.notifyComplete(userID, issue, foreignID, listToUpdate)
+			p.repeatIssue(userID, issue, listToUpdate, foreignID)
+			outcome = fmt.Sprintf("You completed: %s", issue.Message)
+		}
+	case PostActionSnooze:
+		loc := p.getUserLocation(userID)
+		var until int64
+		until, err = parseSnoozeTime(selected, loc)
+		if err != nil {
+			p.writePostActionResponse(w, &model.PostActionIntegrationResponse{EphemeralText: err.Error()})
+			return
+		}
+		if _, err = p.listManager.SnoozeIssue(userID, issueID, until); err == nil {
+			p.trackSnoozeIssue(userID)
+			p.sendRefreshEvent(userID, []string{MyListKey, InListKey})
+			outcome = fmt.Sprintf("You snoozed this Todo until %s.", formatSnoozeTime(until, loc))
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	if err != nil {
+		p.API.LogWarn("Unable to run post action", "action", action, "err", err.Error())
+		p.writePostActionResponse(w, &model.PostActionIntegrationResponse{
+			EphemeralText: fmt.Sprintf("Unable to %s the Todo, it may have already been updated.", action),
+		})
+		return
+	}
+
+	response := &model.PostActionIntegrationResponse{}
+	post, appErr := p.API.GetPost(request.PostId)
+	if appErr != nil {
+		p.API.LogWarn("Unable to get post to update after action", "err", appErr.Error())
+		response.EphemeralText = outcome
+	} else {
+		removePostActions(post, issueID, outcome)
+		response.Update = post
+	}
+
+	p.writePostActionResponse(w, response)
+}
+
+// removePostActions removes from the attachments of post the actions on issueID, adding outcome
+// to the attachments that had them
+func removePostActions(post *model.Post, issueID, outcome string) {
+	attachments := post.Attachments()
+	for _, attachment := range attachments {
+		found := false
+		actions := []*model.PostAction{}
+		for _, action := range attachment.Actions {
+			if action.Integration != nil && action.Integration.Context[postActionIssueIDKey] == issueID {
+				found = true
+				continue
+			}
+
+			if action.Type == model.PostActionTypeSelect {
+				options := []*model.PostActionOptions{}
+				for _, option := range action.Options {
+					if option.Value == issueID {
+						found = true
+						continue
+					}
+					options = append(options, option)
+				}
+				if len(options) == 0 {
+					continue
+				}
+				action.Options = options
+			}
+
+			actions = append(actions, action)
+		}
+
+		if found {
+			attachment.Actions = actions
+			attachment.Fields = append(attachment.Fields, &model.SlackAttachmentField{Value: outcome})
+		}
+	}
+
+	model.ParseSlackAttachment(post, attachments)
+}
+
+func (p *Plugin) writePostActionResponse(w http.ResponseWriter, response *model.PostActionIntegrationResponse) {
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		p.API.LogError("Unable to marshal post action response to json err=" + err.Error())
+		return
+	}
+
+	if _, err = w.Write(responseJSON); err != nil {
+		p.API.LogError("Unable to write json response while running post action err=" + err.Error())
+	}
+}
+
+// truncateText shortens text to at most maxLength characters, ending it with an ellipsis if needed
+func truncateText(text string, maxLength int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLength {
+		return text
+	}
+	return string(append(runes[:maxLength-1], '…'))
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemovePostActions(t *testing.T) {
+	t.Run("buttons of a received todo", func(t *testing.T) {
+		post := &model.Post{}
+		model.ParseSlackAttachment(post, []*model.SlackAttachment{{Actions: issueActions("issue1")}})
+
+		removePostActions(post, "issue1", "You accepted this Todo.")
+
+		attachments := post.Attachments()
+		require.Len(t, attachments, 1)
+		assert.Empty(t, attachments[0].Actions)
+		require.Len(t, attachments[0].Fields, 1)
+		assert.Equal(t, "You accepted this Todo.", attachments[0].Fields[0].Value)
+	})
+
+	t.Run("option of a reminder", func(t *testing.T) {
+		issues := []*ExtendedIssue{
+			{Issue: Issue{ID: "issue1", Message: "first"}},
+			{Issue: Issue{ID: "issue2", Message: "second"}},
+		}
+		post := &model.Post{}
+		model.ParseSlackAttachment(post, []*model.SlackAttachment{{Actions: []*model.PostAction{completeSelectAction(issues)}}})
+
+		removePostActions(post, "issue1", "You completed: first")
+
+		attachments := post.Attachments()
+		require.Len(t, attachments, 1)
+		require.Len(t, attachments[0].Actions, 1)
+		require.Len(t, attachments[0].Actions[0].Options, 1)
+		assert.Equal(t, "issue2", attachments[0].Actions[0].Options[0].Value)
+
+		removePostActions(post, "issue2", "You completed: second")
+
+		attachments = post.Attachments()
+		assert.Empty(t, attachments[0].Actions)
+		assert.Len(t, attachments[0].Fields, 2)
+	})
+
+	t.Run("other todo", func(t *testing.T) {
+		post := &model.Post{}
+		model.ParseSlackAttachment(post, []*model.SlackAttachment{{Actions: issueActions("issue1")}})
+
+		removePostActions(post, "issue2", "You accepted this Todo.")
+
+		attachments := post.Attachments()
+		assert.Len(t, attachments[0].Actions, 4)
+		assert.Empty(t, attachments[0].Fields)
+	})
+}
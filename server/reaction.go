@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/pkg/errors"
+)
+
+// ReactionHasBeenAdded adds the post reacted to with the configured emoji to the list of the user
+// that reacted, as if it was added from the post menu
+func (p *Plugin) ReactionHasBeenAdded(_ *plugin.Context, reaction *model.Reaction) {
+	if !p.isTodoReaction(reaction) {
+		return
+	}
+
+	// Reacting again to a post whose Todo is still open does not add it twice
+	if p.findPostIssue(reaction.UserId, reaction.PostId) != nil {
+		return
+	}
+
+	post, appErr := p.API.GetPost(reaction.PostId)
+	if appErr != nil {
+		p.API.LogError("Unable to get post reacted to", "err", appErr.Error())
+		return
+	}
+	if strings.TrimSpace(post.Message) == "" {
+		return
+	}
+
+	issue := newIssue(post.Message, p.getPostPermalink(post.Id), "", post.Id)
+	issue.Labels = messageLabels(issue.Message)
+	if err := p.listManager.AddIssue(reaction.UserId, issue); err != nil {
+		p.API.LogError(ErrorMsgAddIssue, "err", err.Error())
+		return
+	}
+
+	// Only this Todo is removed along with the reaction
+	if err := p.saveReactionIssue(reaction.UserId, post.Id, issue.ID); err != nil {
+		p.API.LogError("Unable to store issue of post reaction", "err", err.Error())
+	}
+
+	p.ensureReminderUser(reaction.UserId)
+	p.trackAddIssue(reaction.UserId, sourceReaction, true)
+
+	p.sendRefreshEvent(reaction.UserId, []string{MyListKey})
+
+	replyMessage := fmt.Sprintf("@%s attached a todo to this thread", p.listManager.GetUserName(reaction.UserId))
+	p.postReplyIfNeeded(post.Id, replyMessage, issue.Message, issue.PostPermalink)
+}
+
+// ReactionHasBeenRemoved removes the Todo added by ReactionHasBeenAdded from the list of the user that
+// removed the configured emoji, if it is still open. Todos of the post added otherwise are kept.
+func (p *Plugin) ReactionHasBeenRemoved(_ *plugin.Context, reaction *model.Reaction) {
+	if !p.isTodoReaction(reaction) {
+		return
+	}
+
+	issueID, err := p.getReactionIssue(reaction.UserId, reaction.PostId)
+	if err != nil {
+		p.API.LogError("Unable to get issue of post reaction", "err", err.Error())
+		return
+	}
+	if issueID == "" {
+		return
+	}
+
+	if err = p.saveReactionIssue(reaction.UserId, reaction.PostId, ""); err != nil {
+		p.API.LogError("Unable to remove issue of post reaction", "err", err.Error())
+	}
+
+	// Completed or removed since
+	if _, err = p.listManager.GetIssue(reaction.UserId, issueID); err != nil {
+		if !errors.Is(err, ErrIssueNotFound) {
+			p.API.LogError("Unable to get issue of post reaction", "err", err.Error())
+		}
+		return
+	}
+
+	// Taking the reaction back is not an action to undo, the previous one is kept
+	_, _, _, listToUpdate, err := p.listManager.DiscardIssue(reaction.UserId, issueID)
+	if err != nil {
+		p.API.LogError("Unable to remove issue of post reaction", "err", err.Error())
+		return
+	}
+
+	p.trackRemoveIssue(reaction.UserId)
+
+	p.sendRefreshEvent(reaction.UserId, []string{listToUpdate})
+}
+
+// isTodoReaction returns whether reaction is made by a user with the emoji configured to add Todos
+func (p *Plugin) isTodoReaction(reaction *model.Reaction) bool {
+	emojiName := p.getConfiguration().reactionEmojiName()
+	if emojiName == "" || reaction.EmojiName != emojiName || reaction.UserId == p.BotUserID {
+		return false
+	}
+
+	user, appErr := p.API.GetUser(reaction.UserId)
+	return appErr == nil && !user.IsBot
+}
+
+// findPostIssue returns the open Todo of the own or custom lists of userID attached to postID, if any.
+// Todos received from someone else are left out.
+func (p *Plugin) findPostIssue(userID, postID string) *ExtendedIssue {
+	listIDs := []string{MyListKey}
+	customLists, err := p.listManager.GetCustomLists(userID)
+	if err != nil {
+		p.API.LogError("Unable to get lists of user", "err", err.Error())
+	}
+	for _, list := range customLists {
+		listIDs = append(listIDs, list.ID)
+	}
+
+	for _, listID := range listIDs {
+		issues, err := p.listManager.GetIssueList(userID, listID)
+		if err != nil {
+			p.API.LogError("Unable to get issues for user", "err", err.Error())
+			continue
+		}
+		for _, issue := range issues {
+			if issue.PostID == postID && issue.ForeignUser == "" {
+				return issue
+			}
+		}
+	}
+
+	return nil
+}
+
+// getPostPermalink returns a link to postID that works on any team
+func (p *Plugin) getPostPermalink(postID string) string {
+	siteURL := ""
+	if config := p.API.GetConfig(); config != nil && config.ServiceSettings.SiteURL != nil {
+		siteURL = strings.TrimSuffix(*config.ServiceSettings.SiteURL, "/")
+	}
+	return fmt.Sprintf("%s/_redirect/pl/%s", siteURL, postID)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi/experimental/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReactions(t *testing.T) {
+	user := &model.User{Id: model.NewId(), Username: "alice"}
+	bot := &model.User{Id: model.NewId(), Username: "helper", IsBot: true}
+	post := &model.Post{Id: model.NewId(), ChannelId: model.NewId(), Message: "Review the #release notes"}
+	siteURL := "https://chat.example.com/"
+
+	api := &plugintest.API{}
+	api.On("GetUser", user.Id).Return(user, nil)
+	api.On("GetUser", bot.Id).Return(bot, nil)
+	api.On("GetPost", post.Id).Return(post, nil)
+	api.On("GetConfig").Return(&model.Config{ServiceSettings: model.ServiceSettings{SiteURL: &siteURL}})
+	api.On("PublishWebSocketEvent", WSEventRefresh, mock.Anything, mock.Anything).Return()
+	api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(&model.Post{}, nil)
+	api.On("LogInfo", mock.Anything)
+
+	kv := map[string][]byte{}
+	api.On("KVSet", mock.AnythingOfType("string"), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		kv[args.String(0)] = args.Get(1).([]byte)
+	})
+	api.On("KVGet", mock.AnythingOfType("string")).Return(func(key string) []byte { return kv[key] }, nil)
+	api.On("KVCompareAndSet", StoreReminderUsersKey, mock.Anything, mock.Anything).Return(true, nil).Run(func(args mock.Arguments) {
+		kv[args.String(0)] = args.Get(2).([]byte)
+	})
+	api.On("KVDelete", mock.AnythingOfType("string")).Return(nil).Run(func(args mock.Arguments) {
+		delete(kv, args.String(0))
+	})
+
+	store := newMemStore()
+	p := &Plugin{
+		listManager: &listManager{store: store, api: api},
+		tracker:     telemetry.NewTracker(nil, "", "", "", "", "", telemetry.TrackerConfig{}, nil),
+	}
+	p.SetAPI(api)
+	p.setConfiguration(&configuration{ReactionEmoji: ":ballot_box_with_check:"})
+
+	reaction := &model.Reaction{UserId: user.Id, PostId: post.Id, EmojiName: "ballot_box_with_check"}
+	p.ReactionHasBeenAdded(nil, reaction)
+	p.ReactionHasBeenAdded(nil, reaction)
+	p.ReactionHasBeenAdded(nil, &model.Reaction{UserId: user.Id, PostId: post.Id, EmojiName: "smile"})
+	p.ReactionHasBeenAdded(nil, &model.Reaction{UserId: bot.Id, PostId: post.Id, EmojiName: "ballot_box_with_check"})
+
+	issues, err := p.listManager.GetIssueList(user.Id, MyListKey)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, post.Message, issues[0].Message)
+	assert.Equal(t, post.Id, issues[0].PostID)
+	assert.Equal(t, "https://chat.example.com/_redirect/pl/"+post.Id, issues[0].PostPermalink)
+	assert.Equal(t, []string{"release"}, issues[0].Labels)
+	api.AssertNumberOfCalls(t, "CreatePost", 1)
+	bots, err := store.GetList(bot.Id, MyListKey)
+	require.NoError(t, err)
+	assert.Empty(t, bots)
+
+	assert.Equal(t, []byte(issues[0].ID), kv[reactionIssueKey(user.Id, post.Id)])
+
+	// Removing the reaction keeps the last action to undo
+	other := newIssue("other", "", "", "")
+	require.NoError(t, p.listManager.AddIssue(user.Id, other))
+	_, _, _, _, err = p.listManager.RemoveIssue(user.Id, other.ID)
+	require.NoError(t, err)
+
+	p.ReactionHasBeenRemoved(nil, reaction)
+	issues, err = p.listManager.GetIssueList(user.Id, MyListKey)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+	assert.NotContains(t, kv, reactionIssueKey(user.Id, post.Id))
+	require.Contains(t, store.tombstones, user.Id)
+	assert.Equal(t, other.ID, store.tombstones[user.Id].Issue.ID)
+
+	// A Todo of the post added from the post menu is kept when removing the reaction
+	menuIssue := newIssue(post.Message, "", "", post.Id)
+	require.NoError(t, p.listManager.AddIssue(user.Id, menuIssue))
+	p.ReactionHasBeenAdded(nil, reaction)
+	p.ReactionHasBeenRemoved(nil, reaction)
+	issues, err = p.listManager.GetIssueList(user.Id, MyListKey)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, menuIssue.ID, issues[0].ID)
+
+	// So is the Todo added by reacting once completed
+	_, _, _, _, err = p.listManager.RemoveIssue(user.Id, menuIssue.ID)
+	require.NoError(t, err)
+	p.ReactionHasBeenAdded(nil, reaction)
+	issues, err = p.listManager.GetIssueList(user.Id, MyListKey)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	_, _, _, err = p.listManager.CompleteIssue(user.Id, issues[0].ID)
+	require.NoError(t, err)
+	p.ReactionHasBeenRemoved(nil, reaction)
+	done, err := p.listManager.GetIssueList(user.Id, DoneListKey)
+	require.NoError(t, err)
+	require.Len(t, done, 1)
+	assert.Equal(t, issues[0].ID, done[0].ID)
+}
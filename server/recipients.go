@@ -0,0 +1,351 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+const (
+	// RecipientPending is the status of recipients that have not accepted the todo yet
+	RecipientPending = "pending"
+	// RecipientAccepted is the status of recipients that accepted the todo
+	RecipientAccepted = "accepted"
+	// RecipientDone is the status of recipients that completed the todo
+	RecipientDone = "done"
+	// RecipientDeclined is the status of recipients that removed the todo
+	RecipientDeclined = "declined"
+
+	maxRecipients         = 100
+	recipientsPerPage     = 200
+	recipientChannelMark  = "~"
+	recipientUsernameMark = "@"
+)
+
+var (
+	// ErrSentToGroup is returned for the operations that cannot be done on a todo sent to several users
+	ErrSentToGroup = errors.New("the todo was sent to several users")
+	// ErrInvalidRecipients is returned when the users a todo is sent to cannot be found
+	ErrInvalidRecipients = errors.New("invalid recipients")
+)
+
+// Recipient is one of the users a todo was sent to along with others, and the ID of their copy of it
+type Recipient struct {
+	UserID  string `json:"user_id"`
+	IssueID string `json:"issue_id"`
+}
+
+// RecipientStatus tells how far a recipient got with their copy of a todo sent to several users
+type RecipientStatus struct {
+	UserID string `json:"user_id"`
+	Status string `json:"status"`
+}
+
+// RecipientTombstone keeps the copy of a recipient of a removed todo sent to several users, so it
+// can be restored along with the todo of the sender
+type RecipientTombstone struct {
+	UserID   string    `json:"user_id"`
+	Issue    *Issue    `json:"issue"`
+	ListID   string    `json:"list_id"`
+	Position int       `json:"position"`
+	Ref      *IssueRef `json:"ref"`
+}
+
+func (l *listManager) SendIssueToUsers(senderID string, receiverIDs []string, senderIssue *Issue) ([]string, error) {
+	if err := l.assignShortID(senderID, senderIssue); err != nil {
+		return nil, err
+	}
+
+	entry := newJournalEntry(JournalSend, senderID)
+	senderIssue.Recipients = nil
+	receiverIssueIDs := []string{}
+	for _, receiverID := range receiverIDs {
+		// The sender repeats the todo for everyone, see repeatIssue
+		receiverIssue := newForeignIssue(senderIssue)
+		receiverIssue.Recurrence = ""
+		if err := l.assignShortID(receiverID, receiverIssue); err != nil {
+			return nil, err
+		}
+
+		entry.saveIssue(receiverIssue)
+		entry.insertReference(receiverID, InListKey, &IssueRef{
+			IssueID:        receiverIssue.ID,
+			ForeignIssueID: senderIssue.ID,
+			ForeignUserID:  senderID,
+		}, -1)
+
+		senderIssue.Recipients = append(senderIssue.Recipients, Recipient{UserID: receiverID, IssueID: receiverIssue.ID})
+		receiverIssueIDs = append(receiverIssueIDs, receiverIssue.ID)
+	}
+
+	// The sender keeps a single reference with no foreign issue, the recipients are on the issue
+	entry.saveIssue(senderIssue)
+	entry.insertReference(senderID, OutListKey, &IssueRef{IssueID: senderIssue.ID}, -1)
+
+	if err := l.runJournaled(entry); err != nil {
+		return nil, err
+	}
+
+	return receiverIssueIDs, nil
+}
+
+// sentToGroup returns whether ir refers to the copy of a recipient of a todo sent to several users.
+// Those copies are independent from the issue of the sender, which is not changed along with them.
+func (l *listManager) sentToGroup(ir *IssueRef) bool {
+	if ir.ForeignIssueID == "" {
+		return false
+	}
+
+	foreignIssue, err := l.store.GetIssue(ir.ForeignIssueID)
+	return err == nil && len(foreignIssue.Recipients) > 0
+}
+
+// recipientStatuses returns the status of each recipient of issue, which was sent to several users
+func (l *listManager) recipientStatuses(issue *Issue) []*RecipientStatus {
+	statuses := []*RecipientStatus{}
+	for _, recipient := range issue.Recipients {
+		status := &RecipientStatus{UserID: recipient.UserID, Status: RecipientAccepted}
+		recipientIssue, err := l.store.GetIssue(recipient.IssueID)
+		switch {
+		case err != nil:
+			status.Status = RecipientDeclined
+		case recipientIssue.CompletedAt != 0:
+			status.Status = RecipientDone
+		default:
+			if ir, _, _ := l.store.GetIssueReference(recipient.UserID, recipient.IssueID, InListKey); ir != nil {
+				status.Status = RecipientPending
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// removeRecipientsSteps adds to entry the steps to remove the copies of the recipients of issue, and
+// returns what is needed to restore them
+func (l *listManager) removeRecipientsSteps(entry *JournalEntry, issue *Issue) []*RecipientTombstone {
+	tombstones := []*RecipientTombstone{}
+	for _, recipient := range issue.Recipients {
+		recipientIssue, err := l.store.GetIssue(recipient.IssueID)
+		if err != nil {
+			continue
+		}
+
+		listID, ir, n := l.store.GetIssueListAndReference(recipient.UserID, recipient.IssueID)
+		if ir == nil {
+			listID = DoneListKey
+			ir, n, _ = l.store.GetIssueReference(recipient.UserID, recipient.IssueID, DoneListKey)
+		}
+
+		entry.removeIssue(recipient.UserID, recipient.IssueID)
+		if ir == nil {
+			continue
+		}
+		entry.removeReference(recipient.UserID, recipient.IssueID, listID)
+
+		tombstones = append(tombstones, &RecipientTombstone{
+			UserID:   recipient.UserID,
+			Issue:    recipientIssue,
+			ListID:   listID,
+			Position: n,
+			Ref:      ir,
+		})
+	}
+	return tombstones
+}
+
+// recipientsProgress summarizes statuses, like 4/7 done
+func recipientsProgress(statuses []*RecipientStatus) string {
+	done, declined := 0, 0
+	for _, status := range statuses {
+		switch status.Status {
+		case RecipientDone:
+			done++
+		case RecipientDeclined:
+			declined++
+		}
+	}
+
+	progress := fmt.Sprintf("%d/%d done", done, len(statuses))
+	if declined > 0 {
+		progress += fmt.Sprintf(", %d declined", declined)
+	}
+	return progress
+}
+
+// parseRecipients splits the users a todo is sent to, separated by commas or spaces
+func parseRecipients(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
+
+// isRecipientArg returns whether arg names users to send a todo to, as @username, @group or ~channel
+func isRecipientArg(arg string) bool {
+	return len(arg) > 1 && (strings.HasPrefix(arg, recipientUsernameMark) || strings.HasPrefix(arg, recipientChannelMark))
+}
+
+// resolveRecipients returns the users that targets refer to, other than senderID, and whether
+// senderID was named among them. Targets are usernames, user groups or, starting with ~, channels of
+// teamID that senderID is a member of.
+func (p *Plugin) resolveRecipients(senderID, teamID string, targets []string) ([]*model.User, bool, error) {
+	receivers := []*model.User{}
+	seen := map[string]bool{}
+	includesSender := false
+	add := func(users []*model.User, expanded bool) error {
+		for _, user := range users {
+			// Bots and deactivated users only get todos sent to them by name
+			if user.Id == senderID {
+				// Senders are on the groups and channels they send to, but only get a todo if named
+				includesSender = includesSender || !expanded
+				continue
+			}
+			if seen[user.Id] || (expanded && (user.IsBot || user.DeleteAt != 0)) {
+				continue
+			}
+			seen[user.Id] = true
+			if len(receivers) == maxRecipients {
+				return fmt.Errorf("%w: todos can be sent to at most %d users", ErrInvalidRecipients, maxRecipients)
+			}
+			receivers = append(receivers, user)
+		}
+		return nil
+	}
+
+	for _, target := range targets {
+		var users []*model.User
+		var err error
+		expanded := true
+		if strings.HasPrefix(target, recipientChannelMark) {
+			users, err = p.getChannelRecipients(senderID, teamID, strings.TrimPrefix(target, recipientChannelMark))
+		} else {
+			name := strings.TrimPrefix(target, recipientUsernameMark)
+			if user, appErr := p.API.GetUserByUsername(name); appErr == nil {
+				users, expanded = []*model.User{user}, false
+			} else {
+				users, err = p.getGroupRecipients(name)
+			}
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		if err = add(users, expanded); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return receivers, includesSender, nil
+}
+
+func (p *Plugin) getGroupRecipients(name string) ([]*model.User, error) {
+	group, appErr := p.API.GetGroupByName(name)
+	if appErr != nil || !group.AllowReference {
+		return nil, fmt.Errorf("%w: there is no user or group `%s`", ErrInvalidRecipients, name)
+	}
+
+	users := []*model.User{}
+	for page := 0; ; page++ {
+		members, appErr := p.API.GetGroupMemberUsers(group.Id, page, recipientsPerPage)
+		if appErr != nil {
+			return nil, appErr
+		}
+		users = append(users, members...)
+		if len(members) < recipientsPerPage {
+			return users, nil
+		}
+	}
+}
+
+func (p *Plugin) getChannelRecipients(senderID, teamID, name string) ([]*model.User, error) {
+	if teamID == "" {
+		return nil, fmt.Errorf("%w: the team of the channel `%s` is required", ErrInvalidRecipients, name)
+	}
+
+	channel, appErr := p.API.GetChannelByName(teamID, name, false)
+	if appErr != nil {
+		return nil, fmt.Errorf("%w: there is no channel `%s`", ErrInvalidRecipients, name)
+	}
+
+	// Only members can send todos to everyone on a channel
+	if _, appErr = p.API.GetChannelMember(channel.Id, senderID); appErr != nil {
+		return nil, fmt.Errorf("%w: you are not a member of the channel `%s`", ErrInvalidRecipients, name)
+	}
+
+	users := []*model.User{}
+	for page := 0; ; page++ {
+		members, appErr := p.API.GetUsersInChannel(channel.Id, model.ChannelSortByUsername, page, recipientsPerPage)
+		if appErr != nil {
+			return nil, appErr
+		}
+		users = append(users, members...)
+		if len(members) < recipientsPerPage {
+			return users, nil
+		}
+	}
+}
+
+// sendIssueToReceivers sends issue from senderID to receivers, skipping those who block todo requests.
+// Sending to a single user keeps the issue linked to theirs, while sending to several users keeps
+// a single issue on the out list of the sender that tracks all of them. It refreshes the lists and
+// lets the receivers know. It returns the receivers the issue was sent to and those who blocked it.
+func (p *Plugin) sendIssueToReceivers(senderID string, issue *Issue, receivers []*model.User, receiverMessage string) (sent, blocked []*model.User, err error) {
+	for _, receiver := range receivers {
+		allowIncomingTaskRequests, err := p.getAllowIncomingTaskRequestsPreference(receiver.Id)
+		if err != nil {
+			p.API.LogError("Error when getting allow incoming task request preference, err=", err)
+			allowIncomingTaskRequests = true
+		}
+		if !allowIncomingTaskRequests {
+			blocked = append(blocked, receiver)
+			continue
+		}
+		sent = append(sent, receiver)
+	}
+
+	var receiverIssueIDs []string
+	switch len(sent) {
+	case 0:
+		return nil, blocked, nil
+	case 1:
+		var receiverIssueID string
+		receiverIssueID, err = p.listManager.SendIssue(senderID, sent[0].Id, issue)
+		receiverIssueIDs = []string{receiverIssueID}
+	default:
+		receiverIDs := []string{}
+		for _, receiver := range sent {
+			receiverIDs = append(receiverIDs, receiver.Id)
+		}
+		receiverIssueIDs, err = p.listManager.SendIssueToUsers(senderID, receiverIDs, issue)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p.sendRefreshEvent(senderID, []string{OutListKey})
+	for i, receiver := range sent {
+		p.sendRefreshEvent(receiver.Id, []string{InListKey})
+		p.PostBotCustomDM(receiver.Id, receiverMessage, issue.Message, issue.PostPermalink, receiverIssueIDs[i])
+	}
+
+	return sent, blocked, nil
+}
+
+// usernamesText formats users as a list of mentions
+func usernamesText(users []*model.User) string {
+	names := []string{}
+	for _, user := range users {
+		names = append(names, recipientUsernameMark+user.Username)
+	}
+	return strings.Join(names, ", ")
+}
+
+// blockedText tells that users blocked todo requests
+func blockedText(users []*model.User) string {
+	if len(users) == 1 {
+		return fmt.Sprintf("%s has blocked Todo requests", usernamesText(users))
+	}
+	return fmt.Sprintf("%s have blocked Todo requests", usernamesText(users))
+}
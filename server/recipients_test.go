@@ -0,0 +1,132 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendIssueToUsers(t *testing.T) {
+	senderID := model.NewId()
+	receiverIDs := []string{model.NewId(), model.NewId(), model.NewId()}
+	store := newMemStore()
+	l := &listManager{store: store, api: &plugintest.API{}}
+
+	sent := newIssue("retro notes", "", "", "")
+	sent.Recurrence = "FREQ=WEEKLY"
+	receiverIssueIDs, err := l.SendIssueToUsers(senderID, receiverIDs, sent)
+	require.NoError(t, err)
+	require.Len(t, receiverIssueIDs, 3)
+
+	out, err := store.GetList(senderID, OutListKey)
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	assert.Equal(t, &IssueRef{IssueID: sent.ID}, out[0])
+	for i, receiverID := range receiverIDs {
+		listID, ir, _ := store.GetIssueListAndReference(receiverID, receiverIssueIDs[i])
+		require.NotNil(t, ir)
+		assert.Equal(t, InListKey, listID)
+		assert.Equal(t, senderID, ir.ForeignUserID)
+		assert.Empty(t, store.issues[receiverIssueIDs[i]].Recurrence)
+	}
+
+	_, _, err = l.AcceptIssue(receiverIDs[0], receiverIssueIDs[0])
+	require.NoError(t, err)
+	_, foreignID, _, err := l.CompleteIssue(receiverIDs[0], receiverIssueIDs[0])
+	require.NoError(t, err)
+	assert.Equal(t, senderID, foreignID)
+	_, foreignID, isSender, _, err := l.RemoveIssue(receiverIDs[1], receiverIssueIDs[1])
+	require.NoError(t, err)
+	assert.Equal(t, senderID, foreignID)
+	assert.True(t, isSender)
+
+	// The todo of the sender stays on the out list, tracking everyone
+	issues, err := l.GetIssueList(senderID, OutListKey)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, []*RecipientStatus{
+		{UserID: receiverIDs[0], Status: RecipientDone},
+		{UserID: receiverIDs[1], Status: RecipientDeclined},
+		{UserID: receiverIDs[2], Status: RecipientPending},
+	}, issues[0].RecipientStatuses)
+	assert.Equal(t, "1/3 done, 1 declined", recipientsProgress(issues[0].RecipientStatuses))
+
+	report, err := l.Fsck("", false)
+	require.NoError(t, err)
+	assert.Empty(t, report.Problems)
+
+	_, _, _, err = l.BumpIssue(senderID, sent.ID)
+	assert.ErrorIs(t, err, ErrSentToGroup)
+
+	// Removing it takes it back from everyone, until undone
+	_, _, _, _, err = l.RemoveIssue(senderID, sent.ID)
+	require.NoError(t, err)
+	assert.NotContains(t, store.issues, receiverIssueIDs[0])
+	assert.NotContains(t, store.issues, receiverIssueIDs[2])
+	done, err := store.GetList(receiverIDs[0], DoneListKey)
+	require.NoError(t, err)
+	assert.Empty(t, done)
+
+	_, err = l.UndoLastAction(senderID)
+	require.NoError(t, err)
+	listID, ir, _ := store.GetIssueListAndReference(receiverIDs[2], receiverIssueIDs[2])
+	require.NotNil(t, ir)
+	assert.Equal(t, InListKey, listID)
+	done, err = store.GetList(receiverIDs[0], DoneListKey)
+	require.NoError(t, err)
+	require.Len(t, done, 1)
+	assert.Equal(t, receiverIssueIDs[0], done[0].IssueID)
+}
+
+func TestResolveRecipients(t *testing.T) {
+	sender := &model.User{Id: model.NewId(), Username: "lead"}
+	alice := &model.User{Id: model.NewId(), Username: "alice"}
+	bob := &model.User{Id: model.NewId(), Username: "bob"}
+	carol := &model.User{Id: model.NewId(), Username: "carol"}
+	bot := &model.User{Id: model.NewId(), Username: "bot", IsBot: true}
+	teamID := model.NewId()
+	group := &model.Group{Id: model.NewId(), AllowReference: true}
+	channel := &model.Channel{Id: model.NewId()}
+	secret := &model.Channel{Id: model.NewId()}
+	notFound := model.NewAppError("", "", nil, "", 404)
+
+	api := &plugintest.API{}
+	for _, user := range []*model.User{sender, alice, bob, carol, bot} {
+		api.On("GetUserByUsername", user.Username).Return(user, nil)
+	}
+	api.On("GetUserByUsername", "backend").Return(nil, notFound)
+	api.On("GetUserByUsername", "nobody").Return(nil, notFound)
+	api.On("GetGroupByName", "backend").Return(group, nil)
+	api.On("GetGroupByName", "nobody").Return(nil, notFound)
+	api.On("GetGroupMemberUsers", group.Id, 0, recipientsPerPage).Return([]*model.User{sender, alice, bob, bot}, nil)
+	api.On("GetChannelByName", teamID, "town", false).Return(channel, nil)
+	api.On("GetChannelByName", teamID, "secret", false).Return(secret, nil)
+	api.On("GetChannelByName", teamID, "missing", false).Return(nil, notFound)
+	api.On("GetChannelMember", channel.Id, sender.Id).Return(&model.ChannelMember{}, nil)
+	api.On("GetChannelMember", secret.Id, sender.Id).Return(nil, notFound)
+	api.On("GetUsersInChannel", channel.Id, model.ChannelSortByUsername, 0, recipientsPerPage).Return([]*model.User{carol, sender, alice}, nil)
+
+	p := &Plugin{}
+	p.SetAPI(api)
+
+	receivers, includesSender, err := p.resolveRecipients(sender.Id, teamID, parseRecipients("@backend, ~town"))
+	require.NoError(t, err)
+	assert.Equal(t, []*model.User{alice, bob, carol}, receivers)
+	assert.False(t, includesSender)
+
+	receivers, includesSender, err = p.resolveRecipients(sender.Id, teamID, []string{"lead", "@bot"})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.User{bot}, receivers)
+	assert.True(t, includesSender)
+
+	for _, targets := range [][]string{{"nobody"}, {"~secret"}, {"alice", "~town", "~missing"}} {
+		_, _, err = p.resolveRecipients(sender.Id, teamID, targets)
+		assert.ErrorIs(t, err, ErrInvalidRecipients, targets)
+	}
+
+	_, _, err = p.resolveRecipients(sender.Id, "", []string{"~town"})
+	assert.ErrorIs(t, err, ErrInvalidRecipients)
+}
@@ -0,0 +1,448 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+const (
+	// RecurrenceDaily repeats a todo every Interval days
+	RecurrenceDaily = "DAILY"
+	// RecurrenceWeekly repeats a todo every Interval weeks, on Weekdays if given
+	RecurrenceWeekly = "WEEKLY"
+	// RecurrenceMonthly repeats a todo every Interval months, on MonthDay if given
+	RecurrenceMonthly = "MONTHLY"
+
+	recurrenceNone        = "none"
+	maxRecurrenceInterval = 999
+	// maxRecurrenceSkips bounds the occurrences skipped to find the next one after a long overdue todo
+	maxRecurrenceSkips = 1000
+)
+
+// rruleWeekdays are the names of the days of the week on RRULE BYDAY, indexed by time.Weekday
+var rruleWeekdays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Recurrence is the rule a repeating todo follows, stored on issues as a subset of the iCalendar RRULE
+// format with the FREQ, INTERVAL, BYDAY and BYMONTHDAY parts.
+type Recurrence struct {
+	Frequency string
+	Interval  int
+	Weekdays  []time.Weekday
+	MonthDay  int
+}
+
+// parseRecurrence parses a rule written like "daily", "weekdays", "weekly on mon,fri", "every 2 weeks",
+// "monthly on the 15th" or as an RRULE like "FREQ=WEEKLY;BYDAY=MO,FR"
+func parseRecurrence(value string) (*Recurrence, error) {
+	value = strings.TrimSpace(value)
+	upper := strings.ToUpper(value)
+	if strings.HasPrefix(upper, "RRULE:") || strings.HasPrefix(upper, "FREQ=") {
+		return parseRRule(strings.TrimPrefix(upper, "RRULE:"))
+	}
+
+	invalid := fmt.Errorf("invalid repetition `%s`, use a rule like daily, weekdays, weekly on mon,fri, every 2 weeks or monthly on the 15th", value)
+
+	words := strings.Fields(strings.ToLower(value))
+	r := &Recurrence{Interval: 1}
+	if len(words) > 0 && words[0] == "every" {
+		words = words[1:]
+		if len(words) > 0 {
+			if interval, err := strconv.Atoi(words[0]); err == nil {
+				r.Interval = interval
+				words = words[1:]
+			}
+		}
+	}
+	if len(words) == 0 {
+		return nil, invalid
+	}
+
+	switch words[0] {
+	case "daily", "day", "days":
+		r.Frequency = RecurrenceDaily
+	case "weekly", "week", "weeks":
+		r.Frequency = RecurrenceWeekly
+	case "monthly", "month", "months":
+		r.Frequency = RecurrenceMonthly
+	case "weekday", "weekdays":
+		r.Frequency = RecurrenceWeekly
+		r.Weekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+		if len(words) > 1 {
+			return nil, invalid
+		}
+	default:
+		return nil, invalid
+	}
+
+	rest := words[1:]
+	if len(rest) > 0 && rest[0] == "on" {
+		rest = rest[1:]
+		if len(rest) == 0 {
+			return nil, invalid
+		}
+	}
+	if len(rest) > 0 {
+		switch r.Frequency {
+		case RecurrenceWeekly:
+			days, err := parseWeekdays(strings.Join(rest, ""))
+			if err != nil {
+				return nil, invalid
+			}
+			for _, day := range days {
+				r.Weekdays = append(r.Weekdays, parseWeekday(day))
+			}
+		case RecurrenceMonthly:
+			if rest[0] == "the" || rest[0] == "day" {
+				rest = rest[1:]
+			}
+			if len(rest) != 1 {
+				return nil, invalid
+			}
+			day, err := strconv.Atoi(strings.TrimRight(rest[0], "stndrh"))
+			if err != nil {
+				return nil, invalid
+			}
+			r.MonthDay = day
+		default:
+			return nil, invalid
+		}
+	}
+
+	if err := r.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid repetition `%s`, %s", value, err.Error())
+	}
+	return r, nil
+}
+
+// parseRRule parses the supported subset of an RRULE, without the RRULE: prefix
+func parseRRule(rule string) (*Recurrence, error) {
+	r := &Recurrence{Interval: 1}
+	for _, part := range strings.Split(strings.TrimSuffix(rule, ";"), ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid RRULE part `%s`", part)
+		}
+
+		switch name {
+		case "FREQ":
+			r.Frequency = value
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid RRULE interval `%s`", value)
+			}
+			r.Interval = interval
+		case "BYDAY":
+			for _, name := range strings.Split(value, ",") {
+				day := rruleWeekday(name)
+				if day < 0 {
+					return nil, fmt.Errorf("invalid RRULE day `%s`", name)
+				}
+				r.Weekdays = append(r.Weekdays, day)
+			}
+		case "BYMONTHDAY":
+			day, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid RRULE month day `%s`", value)
+			}
+			r.MonthDay = day
+		default:
+			return nil, fmt.Errorf("unsupported RRULE part `%s`, supported parts are FREQ, INTERVAL, BYDAY and BYMONTHDAY", name)
+		}
+	}
+
+	if err := r.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid RRULE `%s`, %s", rule, err.Error())
+	}
+	return r, nil
+}
+
+// rruleWeekday returns the weekday of an RRULE BYDAY name, or -1 if it is not valid
+func rruleWeekday(name string) time.Weekday {
+	for i, rruleName := range rruleWeekdays {
+		if rruleName == name {
+			return time.Weekday(i)
+		}
+	}
+	return -1
+}
+
+// IsValid checks that the rule can be used to repeat todos
+func (r *Recurrence) IsValid() error {
+	switch r.Frequency {
+	case RecurrenceDaily, RecurrenceWeekly, RecurrenceMonthly:
+	default:
+		return fmt.Errorf("frequency must be %s, %s or %s", RecurrenceDaily, RecurrenceWeekly, RecurrenceMonthly)
+	}
+
+	if r.Interval < 1 || r.Interval > maxRecurrenceInterval {
+		return fmt.Errorf("interval must be between 1 and %d", maxRecurrenceInterval)
+	}
+
+	if len(r.Weekdays) > 0 && r.Frequency != RecurrenceWeekly {
+		return fmt.Errorf("days of the week are only supported on weekly rules")
+	}
+
+	if r.MonthDay != 0 && (r.Frequency != RecurrenceMonthly || r.MonthDay < 1 || r.MonthDay > 31) {
+		return fmt.Errorf("day of the month must be between 1 and 31, on monthly rules")
+	}
+
+	return nil
+}
+
+// String returns the rule in the RRULE format stored on issues
+func (r *Recurrence) String() string {
+	parts := []string{"FREQ=" + r.Frequency}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if len(r.Weekdays) > 0 {
+		days := []string{}
+		for _, day := range r.Weekdays {
+			days = append(days, rruleWeekdays[day])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.MonthDay != 0 {
+		parts = append(parts, fmt.Sprintf("BYMONTHDAY=%d", r.MonthDay))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Describe returns the rule in words, to be shown to users
+func (r *Recurrence) Describe() string {
+	units := map[string]string{RecurrenceDaily: "day", RecurrenceWeekly: "week", RecurrenceMonthly: "month"}
+
+	description := "every " + units[r.Frequency]
+	if r.Interval > 1 {
+		description = fmt.Sprintf("every %d %ss", r.Interval, units[r.Frequency])
+	}
+	if len(r.Weekdays) > 0 {
+		days := []string{}
+		for _, day := range r.Weekdays {
+			days = append(days, weekdayNames[day])
+		}
+		description += " on " + strings.Join(days, ", ")
+	}
+	if r.MonthDay != 0 {
+		description += fmt.Sprintf(" on day %d", r.MonthDay)
+	}
+	return description
+}
+
+// next returns the first occurrence of the rule after t, at the same time of the day
+func (r *Recurrence) next(t time.Time) time.Time {
+	switch r.Frequency {
+	case RecurrenceDaily:
+		return t.AddDate(0, 0, r.Interval)
+	case RecurrenceMonthly:
+		day := r.MonthDay
+		if day == 0 {
+			day = t.Day()
+		}
+		month := t.Month() + time.Month(r.Interval)
+		if lastDay := time.Date(t.Year(), month+1, 0, 0, 0, 0, 0, t.Location()).Day(); day > lastDay {
+			day = lastDay
+		}
+		return time.Date(t.Year(), month, day, t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+	}
+
+	if len(r.Weekdays) == 0 {
+		return t.AddDate(0, 0, 7*r.Interval)
+	}
+
+	// Days on the week of t are always valid, later weeks only every Interval weeks
+	for i := 1; i <= 7*(r.Interval+1); i++ {
+		candidate := t.AddDate(0, 0, i)
+		if !r.includes(candidate.Weekday()) {
+			continue
+		}
+		if weeks := weekNumber(candidate) - weekNumber(t); weeks%r.Interval == 0 {
+			return candidate
+		}
+	}
+	return t.AddDate(0, 0, 7*r.Interval)
+}
+
+func (r *Recurrence) includes(day time.Weekday) bool {
+	for _, weekday := range r.Weekdays {
+		if weekday == day {
+			return true
+		}
+	}
+	return false
+}
+
+// weekNumber returns the number of weeks, starting on Monday, from an arbitrary fixed date to the date of t
+func weekNumber(t time.Time) int {
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	// January 5, 1970 was a Monday
+	return int(date.Sub(time.Date(1970, 1, 5, 0, 0, 0, 0, time.UTC)).Hours()/24) / 7
+}
+
+// nextOccurrence returns the due date of the occurrence of the rule following a todo due at dueAt,
+// or due today if dueAt is zero. It is always later than now, skipping the occurrences already passed.
+func (r *Recurrence) nextOccurrence(dueAt int64, now time.Time) time.Time {
+	base := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 0, 0, now.Location())
+	if dueAt != 0 {
+		base = time.UnixMilli(dueAt).In(now.Location())
+	}
+
+	next := r.next(base)
+	for i := 0; i < maxRecurrenceSkips && !next.After(now); i++ {
+		next = r.next(next)
+	}
+	return next
+}
+
+// normalizeRecurrence parses value as a repetition rule for a todo due at dueAt, and returns it in
+// the stored format, or empty to remove it. Monthly rules without day repeat on the day of the
+// due date, or of today.
+func normalizeRecurrence(value string, dueAt int64, loc *time.Location) (string, error) {
+	if value == "" || strings.EqualFold(value, recurrenceNone) {
+		return "", nil
+	}
+
+	r, err := parseRecurrence(value)
+	if err != nil {
+		return "", err
+	}
+
+	if r.Frequency == RecurrenceMonthly && r.MonthDay == 0 {
+		start := time.Now().In(loc)
+		if dueAt != 0 {
+			start = time.UnixMilli(dueAt).In(loc)
+		}
+		r.MonthDay = start.Day()
+	}
+
+	return r.String(), nil
+}
+
+// repeatIssue creates the next occurrence of issue, just finished by userID from listID, if it repeats.
+// Sent todos are sent again by their sender to the same receivers. It returns the new issue, if any,
+// recorded on the finished one so that undoing or reopening it removes the new one.
+func (p *Plugin) repeatIssue(userID string, issue *Issue, listID, foreignID string) *Issue {
+	next, senderID := p.createNextOccurrence(userID, issue, listID, foreignID)
+	if next == nil {
+		return nil
+	}
+
+	if err := p.listManager.SetNextOccurrence(userID, issue, senderID, next.ID); err != nil {
+		p.API.LogWarn("Unable to record the next occurrence of a repeating issue", "issue_id", issue.ID, "err", err.Error())
+	}
+
+	return next
+}
+
+// createNextOccurrence creates the next occurrence of issue as repeatIssue describes, and returns it
+// with the user that owns it
+func (p *Plugin) createNextOccurrence(userID string, issue *Issue, listID, foreignID string) (*Issue, string) {
+	if issue == nil || issue.Recurrence == "" {
+		return nil, ""
+	}
+
+	r, err := parseRecurrence(issue.Recurrence)
+	if err != nil {
+		p.API.LogWarn("Unable to parse the recurrence of a finished issue", "issue_id", issue.ID, "err", err.Error())
+		return nil, ""
+	}
+
+	senderID, receiverID := userID, ""
+	if foreignID != "" {
+		if listID == OutListKey {
+			receiverID = foreignID
+		} else {
+			senderID, receiverID = foreignID, userID
+		}
+	}
+
+	next := newIssue(issue.Message, issue.PostPermalink, issue.Description, issue.PostID)
+	next.Priority = issue.Priority
+	next.Recurrence = issue.Recurrence
+	next.Labels = append([]string(nil), issue.Labels...)
+	for _, item := range issue.Checklist {
+		next.Checklist = append(next.Checklist, ChecklistItem{ID: model.NewId(), Text: item.Text})
+	}
+	next.DueAt = r.nextOccurrence(issue.DueAt, time.Now().In(p.getUserLocation(senderID))).UnixMilli()
+
+	if len(issue.Recipients) > 0 {
+		return p.repeatIssueToRecipients(senderID, issue, next), senderID
+	}
+
+	if receiverID == "" {
+		if err = p.listManager.AddIssue(senderID, next); err != nil {
+			p.API.LogError("Unable to add the next occurrence of a repeating issue", "err", err.Error())
+			return nil, ""
+		}
+
+		p.sendRefreshEvent(senderID, []string{MyListKey})
+		return next, senderID
+	}
+
+	allowIncomingTaskRequests, err := p.getAllowIncomingTaskRequestsPreference(receiverID)
+	if err != nil {
+		p.API.LogError("Error when getting allow incoming task request preference, err=", err)
+		allowIncomingTaskRequests = true
+	}
+	if !allowIncomingTaskRequests {
+		receiverName := p.listManager.GetUserName(receiverID)
+		p.PostBotDM(senderID, fmt.Sprintf("The next occurrence of a repeating Todo was not sent, @%s has blocked Todo requests: %s", receiverName, next.Message))
+		return nil, ""
+	}
+
+	receiverIssueID, err := p.listManager.SendIssue(senderID, receiverID, next)
+	if err != nil {
+		p.API.LogError("Unable to send the next occurrence of a repeating issue", "err", err.Error())
+		return nil, ""
+	}
+
+	p.sendRefreshEvent(senderID, []string{OutListKey})
+	p.sendRefreshEvent(receiverID, []string{InListKey})
+
+	senderName := p.listManager.GetUserName(senderID)
+	receiverMessage := fmt.Sprintf("You have received the next occurrence of a repeating Todo from @%s", senderName)
+	p.PostBotCustomDM(receiverID, receiverMessage, issueSummary(next), next.PostPermalink, receiverIssueID)
+
+	return next, senderID
+}
+
+// repeatIssueToRecipients sends next, the next occurrence of issue, to the users issue was sent to
+func (p *Plugin) repeatIssueToRecipients(senderID string, issue, next *Issue) *Issue {
+	receivers := []*model.User{}
+	for _, recipient := range issue.Recipients {
+		receiver, appErr := p.API.GetUser(recipient.UserID)
+		if appErr != nil {
+			p.API.LogWarn("Unable to get a recipient of a repeating issue", "user_id", recipient.UserID, "err", appErr.Error())
+			continue
+		}
+		receivers = append(receivers, receiver)
+	}
+
+	senderName := p.listManager.GetUserName(senderID)
+	receiverMessage := fmt.Sprintf("You have received the next occurrence of a repeating Todo from @%s", senderName)
+	sent, blocked, err := p.sendIssueToReceivers(senderID, next, receivers, receiverMessage)
+	if err != nil {
+		p.API.LogError("Unable to send the next occurrence of a repeating issue", "err", err.Error())
+		return nil
+	}
+
+	if len(blocked) > 0 {
+		p.PostBotDM(senderID, fmt.Sprintf("The next occurrence of a repeating Todo was not sent to everyone, %s: %s", blockedText(blocked), next.Message))
+	}
+	if len(sent) == 0 {
+		return nil
+	}
+
+	return next
+}
+
+// nextOccurrenceText returns the sentence telling userID when the next occurrence of a repeating todo is due
+func (p *Plugin) nextOccurrenceText(userID string, next *Issue) string {
+	dueAt := time.UnixMilli(next.DueAt).In(p.getUserLocation(userID))
+	return fmt.Sprintf("The next one is due %s.", dueAt.Format("January 2, 2006 at 15:04"))
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "daily", want: "FREQ=DAILY"},
+		{value: "every day", want: "FREQ=DAILY"},
+		{value: "every 3 days", want: "FREQ=DAILY;INTERVAL=3"},
+		{value: "weekdays", want: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"},
+		{value: "Weekly", want: "FREQ=WEEKLY"},
+		{value: "weekly on mon,fri", want: "FREQ=WEEKLY;BYDAY=MO,FR"},
+		{value: "every 2 weeks on mon, thu", want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH"},
+		{value: "monthly", want: "FREQ=MONTHLY"},
+		{value: "monthly on the 15th", want: "FREQ=MONTHLY;BYMONTHDAY=15"},
+		{value: "every 3 months on 1", want: "FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=1"},
+		{value: "RRULE:FREQ=WEEKLY;BYDAY=MO,FR", want: "FREQ=WEEKLY;BYDAY=MO,FR"},
+		{value: "freq=monthly;interval=2;bymonthday=31", want: "FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=31"},
+		{value: "", wantErr: true},
+		{value: "sometimes", wantErr: true},
+		{value: "weekly on someday", wantErr: true},
+		{value: "daily on mon", wantErr: true},
+		{value: "monthly on the 32nd", wantErr: true},
+		{value: "every 0 days", wantErr: true},
+		{value: "FREQ=YEARLY", wantErr: true},
+		{value: "FREQ=DAILY;COUNT=3", wantErr: true},
+		{value: "FREQ=DAILY;BYDAY=MO", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			r, err := parseRecurrence(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, r.String())
+		})
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Madrid")
+	require.NoError(t, err)
+
+	// Wednesday
+	now := time.Date(2025, 1, 15, 10, 30, 0, 0, loc)
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2025, month, day, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name  string
+		rule  string
+		dueAt time.Time
+		want  time.Time
+	}{
+		{name: "daily without due date", rule: "daily", want: at(time.January, 16, 23, 59)},
+		{name: "daily keeps the time", rule: "daily", dueAt: at(time.January, 15, 9, 0), want: at(time.January, 16, 9, 0)},
+		{name: "overdue occurrences are skipped", rule: "daily", dueAt: at(time.January, 10, 17, 0), want: at(time.January, 15, 17, 0)},
+		{name: "weekly", rule: "weekly", dueAt: at(time.January, 17, 17, 0), want: at(time.January, 24, 17, 0)},
+		{name: "weekly on days", rule: "weekly on mon,fri", dueAt: at(time.January, 17, 17, 0), want: at(time.January, 20, 17, 0)},
+		{name: "weekdays on friday", rule: "weekdays", dueAt: at(time.January, 17, 9, 0), want: at(time.January, 20, 9, 0)},
+		{name: "every 2 weeks on days", rule: "every 2 weeks on mon,wed", dueAt: at(time.January, 13, 12, 0), want: at(time.January, 15, 12, 0)},
+		{name: "every 2 weeks skips a week", rule: "every 2 weeks on mon,wed", dueAt: at(time.January, 15, 12, 0), want: at(time.January, 27, 12, 0)},
+		{name: "monthly", rule: "monthly on the 15th", dueAt: at(time.January, 15, 12, 0), want: at(time.February, 15, 12, 0)},
+		{name: "monthly on a day missing from the month", rule: "monthly on the 31st", dueAt: at(time.January, 31, 12, 0), want: at(time.February, 28, 12, 0)},
+		{name: "monthly goes back to the day", rule: "monthly on the 31st", dueAt: at(time.February, 28, 12, 0), want: at(time.March, 31, 12, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := parseRecurrence(tt.rule)
+			require.NoError(t, err)
+
+			dueAt := int64(0)
+			if !tt.dueAt.IsZero() {
+				dueAt = tt.dueAt.UnixMilli()
+			}
+
+			assert.Equal(t, tt.want, r.nextOccurrence(dueAt, now))
+		})
+	}
+}
+
+func TestNormalizeRecurrence(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Madrid")
+	require.NoError(t, err)
+
+	recurrence, err := normalizeRecurrence("monthly", time.Date(2025, 1, 20, 9, 0, 0, 0, loc).UnixMilli(), loc)
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=MONTHLY;BYMONTHDAY=20", recurrence)
+
+	recurrence, err = normalizeRecurrence("none", 0, loc)
+	require.NoError(t, err)
+	assert.Empty(t, recurrence)
+}
+
+func TestRepeatIssue(t *testing.T) {
+	userID := model.NewId()
+
+	setup := func(t *testing.T) (*Plugin, *memStore, *Issue) {
+		store := newMemStore()
+		api := &plugintest.API{}
+		api.On("KVGet", timezoneKey(userID)).Return(nil, nil)
+		api.On("GetUser", userID).Return(&model.User{Id: userID, Username: "someone"}, nil)
+		api.On("PublishWebSocketEvent", mock.Anything, mock.Anything, mock.Anything)
+		p := &Plugin{listManager: &listManager{store: store, api: api}}
+		p.SetAPI(api)
+
+		issue := newIssue("water the plants", "", "", "")
+		issue.Recurrence = "FREQ=DAILY"
+		require.NoError(t, p.listManager.AddIssue(userID, issue))
+
+		issue, _, listID, err := p.listManager.CompleteIssue(userID, issue.ID)
+		require.NoError(t, err)
+		next := p.repeatIssue(userID, issue, listID, "")
+		require.NotNil(t, next)
+		require.Equal(t, []*IssueRef{{IssueID: next.ID}}, store.lists[listKey(userID, MyListKey)])
+		assert.Equal(t, next.ID, store.issues[issue.ID].NextIssueID)
+
+		return p, store, issue
+	}
+
+	t.Run("undoing the completion removes the next occurrence", func(t *testing.T) {
+		p, store, issue := setup(t)
+
+		_, err := p.listManager.UndoLastAction(userID)
+		require.NoError(t, err)
+
+		assert.Equal(t, []*IssueRef{{IssueID: issue.ID}}, store.lists[listKey(userID, MyListKey)])
+		assert.Len(t, store.issues, 1)
+		assert.Empty(t, store.issues[issue.ID].NextIssueID)
+	})
+
+	t.Run("reopening removes the next occurrence", func(t *testing.T) {
+		p, store, issue := setup(t)
+
+		_, _, _, err := p.listManager.ReopenIssue(userID, issue.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, []*IssueRef{{IssueID: issue.ID}}, store.lists[listKey(userID, MyListKey)])
+		assert.Len(t, store.issues, 1)
+		assert.Empty(t, store.issues[issue.ID].NextIssueID)
+	})
+
+	t.Run("next occurrences already completed are kept", func(t *testing.T) {
+		p, store, issue := setup(t)
+		next := store.lists[listKey(userID, MyListKey)][0].IssueID
+		_, _, _, err := p.listManager.CompleteIssue(userID, next)
+		require.NoError(t, err)
+
+		_, _, _, err = p.listManager.ReopenIssue(userID, issue.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, []*IssueRef{{IssueID: issue.ID}}, store.lists[listKey(userID, MyListKey)])
+		assert.Contains(t, store.issues, next)
+	})
+}
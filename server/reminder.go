@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-todo/server/dateparse"
+)
+
+const (
+	// ReminderJobInterval is how often the job checks which users must receive their reminder
+	ReminderJobInterval = 15 * time.Minute
+	// DefaultReminderHour is the local hour of the day after which the reminder is sent
+	DefaultReminderHour = 9
+
+	// ReminderDaily sends the reminder once a day
+	ReminderDaily = "daily"
+	// ReminderWeekly sends the reminder at most once every seven days
+	ReminderWeekly = "weekly"
+
+	reminderJobKey = "daily_reminders"
+)
+
+// weekdayNames are the names used for the days of the week on the reminder schedule, indexed by time.Weekday
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// ReminderSchedule is when a user wants to receive the summary of their Todo list
+type ReminderSchedule struct {
+	// Hour is the local hour of the day after which the reminder is sent
+	Hour int `json:"hour"`
+	// Weekdays are the days of the week the reminder can be sent on, as in weekdayNames
+	Weekdays []string `json:"weekdays"`
+	// Frequency is either ReminderDaily or ReminderWeekly
+	Frequency string `json:"frequency"`
+}
+
+func newDefaultReminderSchedule() *ReminderSchedule {
+	return &ReminderSchedule{
+		Hour:      DefaultReminderHour,
+		Weekdays:  append([]string{}, weekdayNames...),
+		Frequency: ReminderDaily,
+	}
+}
+
+// IsValid checks that the schedule can be used to send reminders
+func (s *ReminderSchedule) IsValid() error {
+	if s.Hour < 0 || s.Hour > 23 {
+		return errors.New("hour must be between 0 and 23")
+	}
+
+	if len(s.Weekdays) == 0 {
+		return errors.New("at least one day of the week is required")
+	}
+
+	for _, day := range s.Weekdays {
+		if parseWeekday(day) < 0 {
+			return errors.Errorf("invalid day of the week %q", day)
+		}
+	}
+
+	if s.Frequency != ReminderDaily && s.Frequency != ReminderWeekly {
+		return errors.Errorf("invalid frequency %q, allowed values are %s and %s", s.Frequency, ReminderDaily, ReminderWeekly)
+	}
+
+	return nil
+}
+
+func (s *ReminderSchedule) includes(day time.Weekday) bool {
+	for _, name := range s.Weekdays {
+		if parseWeekday(name) == day {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ReminderSchedule) String() string {
+	days := strings.Join(s.Weekdays, ", ")
+	if len(s.Weekdays) == len(weekdayNames) {
+		days = "every day"
+	}
+
+	return fmt.Sprintf("%s after %02d:00 (%s)", s.Frequency, s.Hour, days)
+}
+
+// parseWeekday returns the weekday of a name in weekdayNames, or -1 if it is not valid
+func parseWeekday(name string) time.Weekday {
+	for i, weekdayName := range weekdayNames {
+		if weekdayName == name {
+			return time.Weekday(i)
+		}
+	}
+	return -1
+}
+
+// parseWeekdays parses a comma separated list of weekdayNames, or the "all" and "weekdays" shortcuts
+func parseWeekdays(value string) ([]string, error) {
+	switch value {
+	case "all":
+		return append([]string{}, weekdayNames...), nil
+	case "weekdays":
+		return append([]string{}, weekdayNames[1:6]...), nil
+	}
+
+	days := []string{}
+	seen := map[string]bool{}
+	for _, day := range strings.Split(strings.ToLower(value), ",") {
+		day = strings.TrimSpace(day)
+		if parseWeekday(day) < 0 {
+			return nil, fmt.Errorf("invalid day of the week `%s`, use %s", day, strings.Join(weekdayNames, ", "))
+		}
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+
+	return days, nil
+}
+
+// parseReminderHour parses an hour of the day, as a number between 0 and 23 or a time like 9am
+func parseReminderHour(value string) (int, error) {
+	hour, err := strconv.Atoi(value)
+	if err == nil && hour >= 0 && hour <= 23 {
+		return hour, nil
+	}
+
+	clock, err := dateparse.ParseClock(value)
+	if err != nil || clock.Minute != 0 {
+		return 0, fmt.Errorf("invalid hour `%s`, use a number between 0 and 23 or a time like 9am", value)
+	}
+	return clock.Hour, nil
+}
+
+// sendScheduledReminders sends the reminder to the users that turned it on and whose schedule is due.
+// It is run periodically by the reminder job, on a single server of the cluster.
+func (p *Plugin) sendScheduledReminders() {
+	userIDs, _, err := p.getReminderUsers()
+	if err != nil {
+		p.API.LogError("Unable to get users to remind", "err", err.Error())
+		return
+	}
+
+	now := time.Now()
+	for _, userID := range userIDs {
+		if err := p.sendReminderIfDue(userID, now); err != nil {
+			p.API.LogWarn("Unable to send daily reminder", "user_id", userID, "err", err.Error())
+		}
+	}
+}
+
+func (p *Plugin) sendReminderIfDue(userID string, now time.Time) error {
+	if !p.getReminderPreference(userID) {
+		return nil
+	}
+
+	lastReminderAt, err := p.getLastReminderTimeForUser(userID)
+	if err != nil {
+		return err
+	}
+
+	schedule, err := p.getReminderSchedule(userID)
+	if err != nil {
+		return err
+	}
+
+	loc := p.getUserLocation(userID)
+	if !isReminderDue(now.In(loc), time.UnixMilli(lastReminderAt).In(loc), schedule) {
+		return nil
+	}
+
+	issues, err := p.listManager.GetIssueList(userID, MyListKey)
+	if err != nil {
+		return err
+	}
+
+	if len(awakeIssues(issues, now.UnixMilli())) == 0 {
+		return nil
+	}
+
+	title := "Daily Reminder"
+	if schedule.Frequency == ReminderWeekly {
+		title = "Weekly Reminder"
+	}
+
+	p.PostBotReminderDM(userID, title, issues)
+	p.trackDailySummary(userID)
+
+	return p.saveLastReminderTimeForUser(userID)
+}
+
+// isReminderDue returns whether the reminder must be sent at now following schedule, given the last one
+// was sent at last. Both times must be in the user location.
+func isReminderDue(now, last time.Time, schedule *ReminderSchedule) bool {
+	if !schedule.includes(now.Weekday()) || now.Hour() < schedule.Hour {
+		return false
+	}
+
+	days := daysBetween(last, now)
+	if schedule.Frequency == ReminderWeekly {
+		return days >= 7
+	}
+	return days >= 1
+}
+
+// daysBetween returns the number of calendar days from the date of from to the date of to
+func daysBetween(from, to time.Time) int {
+	fromDate := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDate.Sub(fromDate).Hours() / 24)
+}
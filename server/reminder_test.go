@@ -0,0 +1,188 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsReminderDue(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone database not available")
+	}
+
+	daily := newDefaultReminderSchedule()
+	sundayToThursday := &ReminderSchedule{Hour: 8, Weekdays: []string{"sun", "mon", "tue", "wed", "thu"}, Frequency: ReminderDaily}
+	weekly := &ReminderSchedule{Hour: 9, Weekdays: []string{"mon"}, Frequency: ReminderWeekly}
+
+	tests := []struct {
+		name     string
+		schedule *ReminderSchedule
+		now      time.Time
+		last     time.Time
+		want     bool
+	}{
+		{
+			name:     "never reminded, after the hour",
+			schedule: daily,
+			now:      time.Date(2025, 3, 10, 9, 30, 0, 0, loc),
+			last:     time.UnixMilli(0).In(loc),
+			want:     true,
+		},
+		{
+			name:     "never reminded, before the hour",
+			schedule: daily,
+			now:      time.Date(2025, 3, 10, 8, 59, 0, 0, loc),
+			last:     time.UnixMilli(0).In(loc),
+			want:     false,
+		},
+		{
+			name:     "already reminded today",
+			schedule: daily,
+			now:      time.Date(2025, 3, 10, 18, 0, 0, 0, loc),
+			last:     time.Date(2025, 3, 10, 9, 0, 0, 0, loc),
+			want:     false,
+		},
+		{
+			name:     "reminded yesterday",
+			schedule: daily,
+			now:      time.Date(2025, 3, 10, 9, 0, 0, 0, loc),
+			last:     time.Date(2025, 3, 9, 9, 0, 0, 0, loc),
+			want:     true,
+		},
+		{
+			name:     "reminded on the same day of the previous month",
+			schedule: daily,
+			now:      time.Date(2025, 3, 10, 9, 0, 0, 0, loc),
+			last:     time.Date(2025, 2, 10, 9, 0, 0, 0, loc),
+			want:     true,
+		},
+		{
+			name:     "working day of a Sunday to Thursday week",
+			schedule: sundayToThursday,
+			now:      time.Date(2025, 3, 9, 8, 0, 0, 0, loc),
+			last:     time.Date(2025, 3, 6, 8, 0, 0, 0, loc),
+			want:     true,
+		},
+		{
+			name:     "weekend of a Sunday to Thursday week",
+			schedule: sundayToThursday,
+			now:      time.Date(2025, 3, 7, 8, 0, 0, 0, loc),
+			last:     time.Date(2025, 3, 6, 8, 0, 0, 0, loc),
+			want:     false,
+		},
+		{
+			name:     "weekly, a week after the last one",
+			schedule: weekly,
+			now:      time.Date(2025, 3, 10, 9, 0, 0, 0, loc),
+			last:     time.Date(2025, 3, 3, 9, 30, 0, 0, loc),
+			want:     true,
+		},
+		{
+			name:     "weekly, less than a week after the last one",
+			schedule: weekly,
+			now:      time.Date(2025, 3, 10, 9, 0, 0, 0, loc),
+			last:     time.Date(2025, 3, 5, 9, 0, 0, 0, loc),
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isReminderDue(tt.now, tt.last, tt.schedule))
+		})
+	}
+}
+
+func TestParseWeekdays(t *testing.T) {
+	days, err := parseWeekdays("sun,Mon, tue,sun")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sun", "mon", "tue"}, days)
+
+	days, err = parseWeekdays("weekdays")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mon", "tue", "wed", "thu", "fri"}, days)
+
+	_, err = parseWeekdays("mon,funday")
+	assert.Error(t, err)
+}
+
+func TestReminderUsers(t *testing.T) {
+	userID, otherID := model.NewId(), model.NewId()
+
+	t.Run("turning the reminder on and off updates the users", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSet", reminderEnabledKey(userID), []byte("true")).Return(nil)
+		api.On("KVSet", reminderEnabledKey(userID), []byte("false")).Return(nil)
+		api.On("KVGet", StoreReminderUsersKey).Return([]byte(`["`+otherID+`"]`), nil).Once()
+		api.On("KVCompareAndSet", StoreReminderUsersKey, []byte(`["`+otherID+`"]`), []byte(`["`+otherID+`","`+userID+`"]`)).Return(true, nil).Once()
+		api.On("KVGet", StoreReminderUsersKey).Return([]byte(`["`+otherID+`","`+userID+`"]`), nil).Once()
+		api.On("KVCompareAndSet", StoreReminderUsersKey, []byte(`["`+otherID+`","`+userID+`"]`), []byte(`["`+otherID+`"]`)).Return(true, nil).Once()
+		defer api.AssertExpectations(t)
+		p := &Plugin{}
+		p.SetAPI(api)
+
+		require.NoError(t, p.saveReminderPreference(userID, true))
+		require.NoError(t, p.saveReminderPreference(userID, false))
+	})
+
+	t.Run("users with todos are collected once", func(t *testing.T) {
+		thirdID := model.NewId()
+		store := newMemStore()
+		store.lists[listKey(userID, MyListKey)] = []*IssueRef{{IssueID: model.NewId()}}
+		store.lists[listKey(otherID, MyListKey)] = []*IssueRef{{IssueID: model.NewId()}}
+
+		api := &plugintest.API{}
+		api.On("KVGet", StoreReminderUsersMigrationKey).Return(nil, nil).Once()
+		api.On("KVGet", reminderEnabledKey(userID)).Return(nil, nil)
+		api.On("KVGet", reminderEnabledKey(otherID)).Return([]byte("false"), nil)
+		api.On("LogInfo", mock.Anything)
+		api.On("LogInfo", mock.Anything, mock.Anything, mock.Anything)
+		// Someone changed their setting before the migration
+		api.On("KVGet", StoreReminderUsersKey).Return([]byte(`["`+thirdID+`"]`), nil)
+		api.On("KVCompareAndSet", StoreReminderUsersKey, []byte(`["`+thirdID+`"]`), []byte(`["`+thirdID+`","`+userID+`"]`)).Return(true, nil).Once()
+		api.On("KVSet", StoreReminderUsersMigrationKey, []byte("true")).Return(nil).Once()
+		api.On("KVGet", StoreReminderUsersMigrationKey).Return([]byte("true"), nil).Once()
+		defer api.AssertExpectations(t)
+		p := &Plugin{listManager: &listManager{store: store, api: api}}
+		p.SetAPI(api)
+
+		require.NoError(t, p.collectReminderUsers())
+		require.NoError(t, p.collectReminderUsers())
+	})
+
+	t.Run("new users are added once", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", reminderEnabledKey(userID)).Return(nil, nil).Once()
+		api.On("LogInfo", mock.Anything)
+		api.On("KVGet", StoreReminderUsersKey).Return([]byte(`["`+otherID+`"]`), nil).Once()
+		api.On("KVCompareAndSet", StoreReminderUsersKey, []byte(`["`+otherID+`"]`), []byte(`["`+otherID+`","`+userID+`"]`)).Return(true, nil).Once()
+		defer api.AssertExpectations(t)
+		p := &Plugin{}
+		p.SetAPI(api)
+
+		p.ensureReminderUser(userID)
+		p.ensureReminderUser(userID)
+	})
+}
+
+func TestGetReminderPreference(t *testing.T) {
+	userID, offID := model.NewId(), model.NewId()
+
+	api := &plugintest.API{}
+	api.On("KVGet", reminderEnabledKey(userID)).Return(nil, nil)
+	api.On("KVGet", reminderEnabledKey(offID)).Return([]byte("false"), nil)
+	api.On("LogInfo", mock.Anything)
+	p := &Plugin{}
+	p.SetAPI(api)
+
+	// Users that never changed the setting keep getting reminders
+	assert.True(t, p.getReminderPreference(userID))
+	assert.False(t, p.getReminderPreference(offID))
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"io"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -33,12 +34,23 @@ func (t *TelemetryAPIRequest) IsValid() error {
 	return nil
 }
 
+// AddAPIRequest is the payload to add a todo. Due is a due date as accepted by /todo add --due,
+// resolved in the timezone of the user, and takes precedence over DueAt. Recurrence is a repetition
+// rule as accepted by /todo add --repeat. Labels are added to those written on the message. SendTo
+// holds the usernames, user groups or, starting with ~, channels of TeamID to send the todo to,
+// separated by commas or spaces.
 type AddAPIRequest struct {
-	Message       string `json:"message"`
-	PostPermalink string `json:"postPermalink"`
-	Description   string `json:"description"`
-	SendTo        string `json:"send_to"`
-	PostID        string `json:"post_id"`
+	Message       string   `json:"message"`
+	PostPermalink string   `json:"postPermalink"`
+	Description   string   `json:"description"`
+	SendTo        string   `json:"send_to"`
+	TeamID        string   `json:"team_id"`
+	PostID        string   `json:"post_id"`
+	DueAt         int64    `json:"due_at"`
+	Due           string   `json:"due"`
+	Priority      string   `json:"priority"`
+	Recurrence    string   `json:"recurrence"`
+	Labels        []string `json:"labels"`
 }
 
 func GetAddIssuePayloadFromJSON(data io.Reader) (*AddAPIRequest, error) {
@@ -59,13 +71,25 @@ func (a *AddAPIRequest) IsValid() error {
 		return errors.New("message is required")
 	}
 
+	if a.DueAt < 0 {
+		return errors.New("due date is not valid")
+	}
+
+	if !isValidPriority(a.Priority) {
+		return errors.New("priority is not valid")
+	}
+
 	return nil
 }
 
 type EditAPIRequest struct {
-	ID          string `json:"id"`
-	Message     string `json:"message"`
-	Description string `json:"description"`
+	ID          string    `json:"id"`
+	Message     string    `json:"message"`
+	Description string    `json:"description"`
+	DueAt       *int64    `json:"due_at"`
+	Priority    *string   `json:"priority"`
+	Recurrence  *string   `json:"recurrence"`
+	Labels      *[]string `json:"labels"`
 }
 
 func GetEditIssuePayloadFromJSON(data io.Reader) (*EditAPIRequest, error) {
@@ -86,6 +110,14 @@ func (e *EditAPIRequest) IsValid() error {
 		return errors.New("id is required")
 	}
 
+	if e.DueAt != nil && *e.DueAt < 0 {
+		return errors.New("due date is not valid")
+	}
+
+	if e.Priority != nil && !isValidPriority(*e.Priority) {
+		return errors.New("priority is not valid")
+	}
+
 	return nil
 }
 
@@ -169,6 +201,31 @@ func (c *CompleteAPIRequest) IsValid() error {
 	return nil
 }
 
+type ReopenAPIRequest struct {
+	ID string `json:"id"`
+}
+
+func GetReopenIssuePayloadFromJSON(data io.Reader) (*ReopenAPIRequest, error) {
+	body := &ReopenAPIRequest{}
+	if err := json.NewDecoder(data).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func (r *ReopenAPIRequest) IsValid() error {
+	if r == nil {
+		return errors.New("invalid request body")
+	}
+
+	if r.ID == "" {
+		return errors.New("id is required")
+	}
+
+	return nil
+}
+
 type RemoveAPIRequest struct {
 	ID string `json:"id"`
 }
@@ -218,3 +275,403 @@ func (b *BumpAPIRequest) IsValid() error {
 
 	return nil
 }
+
+// SnoozeAPIRequest snoozes a todo until a time. When is a time as accepted by /todo snooze, resolved
+// in the timezone of the user, and takes precedence over Until.
+type SnoozeAPIRequest struct {
+	ID    string `json:"id"`
+	Until int64  `json:"until"`
+	When  string `json:"when"`
+}
+
+func GetSnoozeIssuePayloadFromJSON(data io.Reader) (*SnoozeAPIRequest, error) {
+	body := &SnoozeAPIRequest{}
+	if err := json.NewDecoder(data).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func (s *SnoozeAPIRequest) IsValid() error {
+	if s == nil {
+		return errors.New("invalid request body")
+	}
+
+	if s.ID == "" {
+		return errors.New("id is required")
+	}
+
+	if s.When == "" && s.Until <= 0 {
+		return errors.New("until or when is required")
+	}
+
+	return nil
+}
+
+type UnsnoozeAPIRequest struct {
+	ID string `json:"id"`
+}
+
+func GetUnsnoozeIssuePayloadFromJSON(data io.Reader) (*UnsnoozeAPIRequest, error) {
+	body := &UnsnoozeAPIRequest{}
+	if err := json.NewDecoder(data).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func (u *UnsnoozeAPIRequest) IsValid() error {
+	if u == nil {
+		return errors.New("invalid request body")
+	}
+
+	if u.ID == "" {
+		return errors.New("id is required")
+	}
+
+	return nil
+}
+
+type CreateListAPIRequest struct {
+	Name string `json:"name"`
+}
+
+func GetCreateListPayloadFromJSON(data io.Reader) (*CreateListAPIRequest, error) {
+	body := &CreateListAPIRequest{}
+	if err := json.NewDecoder(data).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func (c *CreateListAPIRequest) IsValid() error {
+	if c == nil {
+		return errors.New("invalid request body")
+	}
+
+	if c.Name == "" {
+		return errors.New("name is required")
+	}
+
+	return nil
+}
+
+type RenameListAPIRequest struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func GetRenameListPayloadFromJSON(data io.Reader) (*RenameListAPIRequest, error) {
+	body := &RenameListAPIRequest{}
+	if err := json.NewDecoder(data).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func (r *RenameListAPIRequest) IsValid() error {
+	if r == nil {
+		return errors.New("invalid request body")
+	}
+
+	if r.ID == "" {
+		return errors.New("id is required")
+	}
+
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+
+	return nil
+}
+
+type DeleteListAPIRequest struct {
+	ID string `json:"id"`
+}
+
+func GetDeleteListPayloadFromJSON(data io.Reader) (*DeleteListAPIRequest, error) {
+	body := &DeleteListAPIRequest{}
+	if err := json.NewDecoder(data).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func (d *DeleteListAPIRequest) IsValid() error {
+	if d == nil {
+		return errors.New("invalid request body")
+	}
+
+	if d.ID == "" {
+		return errors.New("id is required")
+	}
+
+	return nil
+}
+
+// MoveListAPIRequest moves a custom list to a zero based position among the lists of the user
+type MoveListAPIRequest struct {
+	ID       string `json:"id"`
+	Position int    `json:"position"`
+}
+
+func GetMoveListPayloadFromJSON(data io.Reader) (*MoveListAPIRequest, error) {
+	body := &MoveListAPIRequest{}
+	if err := json.NewDecoder(data).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func (m *MoveListAPIRequest) IsValid() error {
+	if m == nil {
+		return errors.New("invalid request body")
+	}
+
+	if m.ID == "" {
+		return errors.New("id is required")
+	}
+
+	if m.Position < 0 {
+		return errors.New("position is not valid")
+	}
+
+	return nil
+}
+
+// MoveIssueAPIRequest moves a todo to another list. List is the ID of a custom list, or empty or
+// "my" for the my list.
+type MoveIssueAPIRequest struct {
+	ID   string `json:"id"`
+	List string `json:"list"`
+}
+
+func GetMoveIssuePayloadFromJSON(data io.Reader) (*MoveIssueAPIRequest, error) {
+	body := &MoveIssueAPIRequest{}
+	if err := json.NewDecoder(data).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func (m *MoveIssueAPIRequest) IsValid() error {
+	if m == nil {
+		return errors.New("invalid request body")
+	}
+
+	if m.ID == "" {
+		return errors.New("id is required")
+	}
+
+	if m.List != "" && m.List != MyFlag && !isCustomListKey(m.List) {
+		return errors.New("list is not valid")
+	}
+
+	return nil
+}
+
+// ReorderAPIRequest sorts a list with the todos on Order first, in that order, followed by the
+// ones not given. List is my, in, out or the ID of a custom list.
+type ReorderAPIRequest struct {
+	List  string   `json:"list"`
+	Order []string `json:"order"`
+}
+
+func GetReorderPayloadFromJSON(data io.Reader) (*ReorderAPIRequest, error) {
+	body := &ReorderAPIRequest{}
+	if err := json.NewDecoder(data).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func (r *ReorderAPIRequest) IsValid() error {
+	if r == nil {
+		return errors.New("invalid request body")
+	}
+
+	if _, ok := listIDFromFlag(r.List); !ok && !isCustomListKey(r.List) {
+		return errors.New("list is not valid")
+	}
+
+	if len(r.Order) == 0 {
+		return errors.New("order is required")
+	}
+
+	return nil
+}
+
+// ChannelAddAPIRequest adds a todo to the list of a channel
+type ChannelAddAPIRequest struct {
+	Message       string   `json:"message"`
+	PostPermalink string   `json:"postPermalink"`
+	Description   string   `json:"description"`
+	PostID        string   `json:"post_id"`
+	DueAt         int64    `json:"due_at"`
+	Priority      string   `json:"priority"`
+	Labels        []string `json:"labels"`
+}
+
+func GetChannelAddPayloadFromJSON(data io.Reader) (*ChannelAddAPIRequest, error) {
+	body := &ChannelAddAPIRequest{}
+	if err := json.NewDecoder(data).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func (c *ChannelAddAPIRequest) IsValid() error {
+	if c == nil {
+		return errors.New("invalid request body")
+	}
+
+	if c.Message == "" {
+		return errors.New("message is required")
+	}
+
+	if c.DueAt < 0 {
+		return errors.New("due date is not valid")
+	}
+
+	if !isValidPriority(c.Priority) {
+		return errors.New("priority is not valid")
+	}
+
+	return nil
+}
+
+// ChannelIssueAPIRequest claims, completes or removes a todo of the list of a channel
+type ChannelIssueAPIRequest struct {
+	ID string `json:"id"`
+}
+
+func GetChannelIssuePayloadFromJSON(data io.Reader) (*ChannelIssueAPIRequest, error) {
+	body := &ChannelIssueAPIRequest{}
+	if err := json.NewDecoder(data).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func (c *ChannelIssueAPIRequest) IsValid() error {
+	if c == nil {
+		return errors.New("invalid request body")
+	}
+
+	if c.ID == "" {
+		return errors.New("id is required")
+	}
+
+	return nil
+}
+
+// ChecklistAPIRequest changes the checklist of a todo. Text is required to add an item, and ItemID
+// for the rest of actions. Position is the zero based position to move the item to.
+type ChecklistAPIRequest struct {
+	ID       string `json:"id"`
+	Action   string `json:"action"`
+	ItemID   string `json:"item_id"`
+	Text     string `json:"text"`
+	Position int    `json:"position"`
+}
+
+func GetChecklistPayloadFromJSON(data io.Reader) (*ChecklistAPIRequest, error) {
+	body := &ChecklistAPIRequest{}
+	if err := json.NewDecoder(data).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func (c *ChecklistAPIRequest) IsValid() error {
+	if c == nil {
+		return errors.New("invalid request body")
+	}
+
+	if c.ID == "" {
+		return errors.New("id is required")
+	}
+
+	switch c.Action {
+	case ChecklistAdd:
+		if c.Text == "" {
+			return errors.New("text is required")
+		}
+	case ChecklistToggle, ChecklistRemove, ChecklistMove:
+		if c.ItemID == "" {
+			return errors.New("item_id is required")
+		}
+	default:
+		return errors.Errorf("action must be %s, %s, %s or %s", ChecklistAdd, ChecklistToggle, ChecklistRemove, ChecklistMove)
+	}
+
+	if c.Position < 0 {
+		return errors.New("position is not valid")
+	}
+
+	return nil
+}
+
+// update returns the checklist update requested
+func (c *ChecklistAPIRequest) update() *ChecklistUpdate {
+	if c.Action == ChecklistAdd {
+		return newChecklistAdd(c.Text)
+	}
+	return &ChecklistUpdate{Action: c.Action, ItemID: c.ItemID, Position: c.Position}
+}
+
+// SettingsAPIRequest updates the user settings. Missing fields are left unchanged.
+type SettingsAPIRequest struct {
+	Summary                   *bool             `json:"summary"`
+	ReminderSchedule          *ReminderSchedule `json:"reminder_schedule"`
+	AllowIncomingTaskRequests *bool             `json:"allow_incoming_task_requests"`
+	Timezone                  *string           `json:"timezone"`
+}
+
+func GetSettingsPayloadFromJSON(data io.Reader) (*SettingsAPIRequest, error) {
+	body := &SettingsAPIRequest{}
+	if err := json.NewDecoder(data).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func (s *SettingsAPIRequest) IsValid() error {
+	if s == nil {
+		return errors.New("invalid request body")
+	}
+
+	if s.ReminderSchedule != nil {
+		if err := s.ReminderSchedule.IsValid(); err != nil {
+			return err
+		}
+	}
+
+	if s.Timezone != nil && *s.Timezone != "" {
+		if _, err := time.LoadLocation(*s.Timezone); err != nil || *s.Timezone == "Local" {
+			return errors.Errorf("unknown timezone %q", *s.Timezone)
+		}
+	}
+
+	return nil
+}
+
+// SettingsAPIResponse holds the user settings
+type SettingsAPIResponse struct {
+	Summary                   bool              `json:"summary"`
+	ReminderSchedule          *ReminderSchedule `json:"reminder_schedule"`
+	AllowIncomingTaskRequests bool              `json:"allow_incoming_task_requests"`
+	Timezone                  string            `json:"timezone"`
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+
+	"github.com/mattermost/mattermost-plugin-todo/server/dateparse"
+)
+
+const (
+	// SnoozeJobInterval is how often the job checks which snoozed issues must wake up
+	SnoozeJobInterval = 5 * time.Minute
+	// SnoozeDefaultHour is the local hour snoozed issues wake up at when only a date is given
+	SnoozeDefaultHour = 9
+
+	snoozeJobKey = "snoozed_issues"
+)
+
+// parseSnoozeTime parses when a snoozed issue must wake up, written as a due date is, on the given
+// location. It must be in the future.
+func parseSnoozeTime(value string, loc *time.Location) (int64, error) {
+	now := time.Now().In(loc)
+	t, err := dateparse.ParseWithDefaultClock(value, now, dateparse.Clock{Hour: SnoozeDefaultHour})
+	if err != nil {
+		return 0, fmt.Errorf("invalid snooze time `%s`, use a time like tomorrow, monday 9am or in 2 hours", value)
+	}
+
+	if !t.After(now) {
+		return 0, fmt.Errorf("the snooze time `%s` has already passed", value)
+	}
+
+	return t.UnixMilli(), nil
+}
+
+// formatSnoozeTime returns the time a snoozed issue wakes up at on loc, to be shown to users
+func formatSnoozeTime(until int64, loc *time.Location) string {
+	return time.UnixMilli(until).In(loc).Format("Monday, January 2 at 15:04")
+}
+
+// wakeSnoozedIssues moves the snoozed issues whose time has come back to the top of their lists,
+// and lets their owners know. It is run periodically by the snooze job, on a single server of the cluster.
+func (p *Plugin) wakeSnoozedIssues() {
+	userIDs, err := p.listManager.GetUserIDs()
+	if err != nil {
+		p.API.LogError("Unable to get users to wake snoozed issues", "err", err.Error())
+		return
+	}
+
+	now := model.GetMillis()
+	for _, userID := range userIDs {
+		if err := p.wakeUserSnoozedIssues(userID, now); err != nil {
+			p.API.LogWarn("Unable to wake snoozed issues", "user_id", userID, "err", err.Error())
+		}
+	}
+}
+
+func (p *Plugin) wakeUserSnoozedIssues(userID string, now int64) error {
+	listIDs := []string{MyListKey, InListKey}
+	customLists, err := p.listManager.GetCustomLists(userID)
+	if err != nil {
+		return err
+	}
+	for _, list := range customLists {
+		listIDs = append(listIDs, list.ID)
+	}
+
+	for _, listID := range listIDs {
+		issues, err := p.listManager.GetIssueList(userID, listID)
+		if err != nil {
+			return err
+		}
+
+		// Issues are woken from the bottom, so those waking at once keep their order at the top of the list
+		for i := len(issues) - 1; i >= 0; i-- {
+			if issues[i].SnoozedUntil == 0 || issues[i].IsSnoozed(now) {
+				continue
+			}
+
+			issue, listID, err := p.listManager.WakeIssue(userID, issues[i].ID)
+			if err != nil {
+				return err
+			}
+
+			p.notifyWake(userID, issue, listID)
+		}
+	}
+
+	return nil
+}
+
+// notifyWake lets userID know the snooze of issue on listID is over
+func (p *Plugin) notifyWake(userID string, issue *Issue, listID string) {
+	p.sendRefreshEvent(userID, []string{listID})
+
+	actions := ownIssueActions(issue.ID)
+	if listID == InListKey {
+		actions = issueActions(issue.ID)
+	}
+	p.PostBotIssueDM(userID, "A snoozed Todo is back on your list", issueSummary(issue), issue.PostPermalink, issue.ID, actions)
+}
@@ -0,0 +1,930 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	issuesTable     = "todo_issues"
+	referencesTable = "todo_references"
+	migrationsTable = "todo_migrations"
+)
+
+// issueColumns are the columns of the issues table, in the order used by scanIssue and issueValues
+var issueColumns = []string{
+	"id",
+	"message",
+	"description",
+	"post_permalink",
+	"post_id",
+	"create_at",
+	"due_at",
+	"priority",
+	"completed_at",
+	"completed_by",
+	"short_id",
+	"snoozed_until",
+	"recurrence",
+	"checklist",
+	"labels",
+	"recipients",
+	"claimed_by",
+	"next_issue_id",
+	"next_user_id",
+}
+
+// sqlMigration holds the statements of a schema change for each supported database driver. The
+// statements must be safe to run again, since MySQL commits each one right away and the version is
+// recorded after all of them.
+type sqlMigration struct {
+	Postgres []string
+	MySQL    []string
+	// Table and Column are set by the migrations adding a column, which are skipped if it already exists
+	Table  string
+	Column string
+}
+
+// sqlMigrations are applied in order and each one is recorded on the migrations table once done.
+// Never modify an already released migration, add a new one instead.
+var sqlMigrations = []sqlMigration{
+	{
+		Postgres: []string{
+			`CREATE TABLE IF NOT EXISTS todo_issues (
+				id VARCHAR(26) PRIMARY KEY,
+				message TEXT NOT NULL,
+				description TEXT NOT NULL,
+				post_permalink TEXT NOT NULL,
+				post_id VARCHAR(26) NOT NULL,
+				create_at BIGINT NOT NULL,
+				due_at BIGINT NOT NULL DEFAULT 0,
+				priority VARCHAR(16) NOT NULL DEFAULT '',
+				completed_at BIGINT NOT NULL DEFAULT 0,
+				completed_by VARCHAR(26) NOT NULL DEFAULT ''
+			)`,
+			`CREATE TABLE IF NOT EXISTS todo_references (
+				user_id VARCHAR(26) NOT NULL,
+				list_id VARCHAR(64) NOT NULL,
+				issue_id VARCHAR(26) NOT NULL,
+				foreign_issue_id VARCHAR(26) NOT NULL DEFAULT '',
+				foreign_user_id VARCHAR(26) NOT NULL DEFAULT '',
+				previous_list VARCHAR(64) NOT NULL DEFAULT '',
+				sort_order BIGINT NOT NULL,
+				PRIMARY KEY (user_id, list_id, issue_id)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_todo_references_order ON todo_references (user_id, list_id, sort_order)`,
+			`CREATE INDEX IF NOT EXISTS idx_todo_references_issue ON todo_references (user_id, issue_id)`,
+		},
+		MySQL: []string{
+			`CREATE TABLE IF NOT EXISTS todo_issues (
+				id VARCHAR(26) PRIMARY KEY,
+				message TEXT NOT NULL,
+				description TEXT NOT NULL,
+				post_permalink TEXT NOT NULL,
+				post_id VARCHAR(26) NOT NULL,
+				create_at BIGINT NOT NULL,
+				due_at BIGINT NOT NULL DEFAULT 0,
+				priority VARCHAR(16) NOT NULL DEFAULT '',
+				completed_at BIGINT NOT NULL DEFAULT 0,
+				completed_by VARCHAR(26) NOT NULL DEFAULT ''
+			) DEFAULT CHARACTER SET utf8mb4`,
+			`CREATE TABLE IF NOT EXISTS todo_references (
+				user_id VARCHAR(26) NOT NULL,
+				list_id VARCHAR(64) NOT NULL,
+				issue_id VARCHAR(26) NOT NULL,
+				foreign_issue_id VARCHAR(26) NOT NULL DEFAULT '',
+				foreign_user_id VARCHAR(26) NOT NULL DEFAULT '',
+				previous_list VARCHAR(64) NOT NULL DEFAULT '',
+				sort_order BIGINT NOT NULL,
+				PRIMARY KEY (user_id, list_id, issue_id),
+				INDEX idx_todo_references_order (user_id, list_id, sort_order),
+				INDEX idx_todo_references_issue (user_id, issue_id)
+			) DEFAULT CHARACTER SET utf8mb4`,
+		},
+	},
+	{
+		Postgres: []string{
+			`ALTER TABLE todo_issues ADD COLUMN short_id BIGINT NOT NULL DEFAULT 0`,
+		},
+		MySQL: []string{
+			`ALTER TABLE todo_issues ADD COLUMN short_id BIGINT NOT NULL DEFAULT 0`,
+		},
+		Table:  issuesTable,
+		Column: "short_id",
+	},
+	{
+		Postgres: []string{
+			`ALTER TABLE todo_issues ADD COLUMN snoozed_until BIGINT NOT NULL DEFAULT 0`,
+		},
+		MySQL: []string{
+			`ALTER TABLE todo_issues ADD COLUMN snoozed_until BIGINT NOT NULL DEFAULT 0`,
+		},
+		Table:  issuesTable,
+		Column: "snoozed_until",
+	},
+	{
+		Postgres: []string{
+			`ALTER TABLE todo_issues ADD COLUMN recurrence VARCHAR(255) NOT NULL DEFAULT ''`,
+		},
+		MySQL: []string{
+			`ALTER TABLE todo_issues ADD COLUMN recurrence VARCHAR(255) NOT NULL DEFAULT ''`,
+		},
+		Table:  issuesTable,
+		Column: "recurrence",
+	},
+	{
+		Postgres: []string{
+			`ALTER TABLE todo_issues ADD COLUMN checklist TEXT`,
+		},
+		MySQL: []string{
+			`ALTER TABLE todo_issues ADD COLUMN checklist TEXT`,
+		},
+		Table:  issuesTable,
+		Column: "checklist",
+	},
+	{
+		Postgres: []string{
+			`ALTER TABLE todo_issues ADD COLUMN labels TEXT`,
+		},
+		MySQL: []string{
+			`ALTER TABLE todo_issues ADD COLUMN labels TEXT`,
+		},
+		Table:  issuesTable,
+		Column: "labels",
+	},
+	{
+		Postgres: []string{
+			`ALTER TABLE todo_issues ADD COLUMN recipients TEXT`,
+		},
+		MySQL: []string{
+			`ALTER TABLE todo_issues ADD COLUMN recipients TEXT`,
+		},
+		Table:  issuesTable,
+		Column: "recipients",
+	},
+	{
+		Postgres: []string{
+			`ALTER TABLE todo_issues ADD COLUMN claimed_by VARCHAR(26) NOT NULL DEFAULT ''`,
+		},
+		MySQL: []string{
+			`ALTER TABLE todo_issues ADD COLUMN claimed_by VARCHAR(26) NOT NULL DEFAULT ''`,
+		},
+		Table:  issuesTable,
+		Column: "claimed_by",
+	},
+	{
+		Postgres: []string{
+			`ALTER TABLE todo_issues ADD COLUMN next_issue_id VARCHAR(26) NOT NULL DEFAULT ''`,
+		},
+		MySQL: []string{
+			`ALTER TABLE todo_issues ADD COLUMN next_issue_id VARCHAR(26) NOT NULL DEFAULT ''`,
+		},
+		Table:  issuesTable,
+		Column: "next_issue_id",
+	},
+	{
+		Postgres: []string{
+			`ALTER TABLE todo_issues ADD COLUMN next_user_id VARCHAR(26) NOT NULL DEFAULT ''`,
+		},
+		MySQL: []string{
+			`ALTER TABLE todo_issues ADD COLUMN next_user_id VARCHAR(26) NOT NULL DEFAULT ''`,
+		},
+		Table:  issuesTable,
+		Column: "next_user_id",
+	},
+}
+
+type sqlStore struct {
+	db         *sql.DB
+	driverName string
+	// kvStore keeps the data that is not stored on tables: the undo tombstones, the journal, the short
+	// ID counters and the custom lists
+	kvStore ListStore
+}
+
+// newSQLListStore creates a new sqlStore using db. Call migrate before using it.
+func newSQLListStore(db *sql.DB, driverName string, kvStore ListStore) *sqlStore {
+	return &sqlStore{
+		db:         db,
+		driverName: driverName,
+		kvStore:    kvStore,
+	}
+}
+
+// migrate applies the pending schema migrations. It must not run concurrently on several servers.
+func (s *sqlStore) migrate() error {
+	_, err := s.db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (version INTEGER NOT NULL PRIMARY KEY)", migrationsTable))
+	if err != nil {
+		return errors.Wrap(err, "failed to create migrations table")
+	}
+
+	var currentVersion int
+	err = s.db.QueryRow(fmt.Sprintf("SELECT COALESCE(MAX(version), 0) FROM %s", migrationsTable)).Scan(&currentVersion)
+	if err != nil {
+		return errors.Wrap(err, "failed to get current schema version")
+	}
+
+	for i := currentVersion; i < len(sqlMigrations); i++ {
+		statements := sqlMigrations[i].Postgres
+		if s.driverName == model.DatabaseDriverMysql {
+			statements = sqlMigrations[i].MySQL
+		}
+
+		version := i + 1
+		applied := false
+		if sqlMigrations[i].Column != "" {
+			if applied, err = s.columnExists(sqlMigrations[i].Table, sqlMigrations[i].Column); err != nil {
+				return errors.Wrapf(err, "failed to check migration %d", version)
+			}
+		}
+
+		if !applied {
+			for _, statement := range statements {
+				if _, err = s.db.Exec(statement); err != nil {
+					return errors.Wrapf(err, "failed to apply migration %d", version)
+				}
+			}
+		}
+
+		if _, err = s.db.Exec(s.rebind(fmt.Sprintf("INSERT INTO %s (version) VALUES (?)", migrationsTable)), version); err != nil {
+			return errors.Wrapf(err, "failed to record migration %d", version)
+		}
+	}
+
+	return nil
+}
+
+// columnExists returns whether table has the given column
+func (s *sqlStore) columnExists(table, column string) (bool, error) {
+	schema := "current_schema()"
+	if s.driverName == model.DatabaseDriverMysql {
+		schema = "DATABASE()"
+	}
+
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM information_schema.columns
+		WHERE table_schema = %s AND table_name = ? AND column_name = ?`, schema)
+
+	var n int
+	if err := s.db.QueryRow(s.rebind(query), table, column).Scan(&n); err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+func (s *sqlStore) SaveIssue(issue *Issue) error {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(issueColumns)), ", ")
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) %s", issuesTable, strings.Join(issueColumns, ", "), placeholders, s.upsertClause("id", issueColumns[1:]))
+
+	values, err := issueValues(issue)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(s.rebind(query), values...)
+	return err
+}
+
+func (s *sqlStore) GetIssue(issueID string) (*Issue, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = ?", strings.Join(issueColumns, ", "), issuesTable)
+
+	issue, err := scanIssue(s.db.QueryRow(s.rebind(query), issueID))
+	if err == sql.ErrNoRows {
+		return nil, ErrIssueNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return issue, nil
+}
+
+func (s *sqlStore) RemoveIssue(_, issueID string) error {
+	_, err := s.db.Exec(s.rebind(fmt.Sprintf("DELETE FROM %s WHERE id = ?", issuesTable)), issueID)
+	return err
+}
+
+func (s *sqlStore) GetAndRemoveIssue(userID, issueID string) (*Issue, error) {
+	issue, err := s.GetIssue(issueID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.RemoveIssue(userID, issueID)
+	if err != nil {
+		return nil, err
+	}
+
+	return issue, nil
+}
+
+func (s *sqlStore) AddReference(userID, issueID, listID, foreignUserID, foreignIssueID string) error {
+	return s.InsertReference(userID, listID, &IssueRef{
+		IssueID:        issueID,
+		ForeignIssueID: foreignIssueID,
+		ForeignUserID:  foreignUserID,
+	}, -1)
+}
+
+func (s *sqlStore) InsertReference(userID, listID string, ir *IssueRef, position int) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		list, orders, err := s.lockList(tx, userID, listID)
+		if err != nil {
+			return err
+		}
+
+		for _, listIR := range list {
+			if listIR.IssueID == ir.IssueID {
+				return ErrReferenceExists
+			}
+		}
+
+		switch {
+		case len(list) == 0:
+			return s.insertReference(tx, userID, listID, ir, 0)
+		case position < 0 || position >= len(list):
+			return s.insertReference(tx, userID, listID, ir, orders[len(orders)-1]+1)
+		case position == 0:
+			return s.insertReference(tx, userID, listID, ir, orders[0]-1)
+		}
+
+		// Make room for the new reference by moving down the ones after it
+		for i := position; i < len(list); i++ {
+			if err := s.updateOrder(tx, userID, listID, list[i].IssueID, orders[i]+1); err != nil {
+				return err
+			}
+		}
+
+		return s.insertReference(tx, userID, listID, ir, orders[position])
+	})
+}
+
+func (s *sqlStore) RemoveReference(userID, issueID, listID string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE user_id = ? AND list_id = ? AND issue_id = ?", referencesTable)
+	result, err := s.db.Exec(s.rebind(query), userID, listID, issueID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrIssueNotFound
+	}
+
+	return nil
+}
+
+func (s *sqlStore) PopReference(userID, listID string) (*IssueRef, error) {
+	var ir *IssueRef
+	err := s.withTx(func(tx *sql.Tx) error {
+		list, _, err := s.lockList(tx, userID, listID)
+		if err != nil {
+			return err
+		}
+
+		if len(list) == 0 {
+			return ErrIssueNotFound
+		}
+
+		ir = list[0]
+		query := fmt.Sprintf("DELETE FROM %s WHERE user_id = ? AND list_id = ? AND issue_id = ?", referencesTable)
+		_, err = tx.Exec(s.rebind(query), userID, listID, ir.IssueID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ir, nil
+}
+
+func (s *sqlStore) BumpReference(userID, issueID, listID string) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		list, orders, err := s.lockList(tx, userID, listID)
+		if err != nil {
+			return err
+		}
+
+		for i, ir := range list {
+			if ir.IssueID != issueID {
+				continue
+			}
+
+			if i == 0 {
+				return nil
+			}
+
+			return s.updateOrder(tx, userID, listID, issueID, orders[0]-1)
+		}
+
+		return ErrIssueNotFound
+	})
+}
+
+func (s *sqlStore) MoveReference(userID, issueID, listID string, newPosition int) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		list, orders, err := s.lockList(tx, userID, listID)
+		if err != nil {
+			return err
+		}
+
+		newList, n, err := moveReference(list, issueID, newPosition)
+		if err != nil {
+			return err
+		}
+
+		from, to := n, newPosition
+		if to < 0 || to >= len(list) {
+			to = len(list) - 1
+		}
+		if from > to {
+			from, to = to, from
+		}
+
+		// Hand the sort orders of the affected range over to the references now there. Repeated
+		// orders are sorted by issue ID, so renumber the whole list when there are any.
+		for i := 1; i < len(orders); i++ {
+			if orders[i] == orders[i-1] {
+				from, to = 0, len(list)-1
+				for j := range orders {
+					orders[j] = int64(j)
+				}
+				break
+			}
+		}
+
+		for i := from; i <= to; i++ {
+			if err := s.updateOrder(tx, userID, listID, newList[i].IssueID, orders[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *sqlStore) ReorderReferences(userID, listID string, issueIDs []string) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		list, _, err := s.lockList(tx, userID, listID)
+		if err != nil {
+			return err
+		}
+
+		newList, err := reorderReferences(list, issueIDs)
+		if err != nil {
+			return err
+		}
+
+		for i, ir := range newList {
+			if err := s.updateOrder(tx, userID, listID, ir.IssueID, int64(i)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *sqlStore) GetIssueReference(userID, issueID, listID string) (*IssueRef, int, error) {
+	query := fmt.Sprintf(`SELECT issue_id, foreign_issue_id, foreign_user_id, previous_list, sort_order
+		FROM %s WHERE user_id = ? AND list_id = ? AND issue_id = ?`, referencesTable)
+
+	ir := &IssueRef{}
+	var order int64
+	err := s.db.QueryRow(s.rebind(query), userID, listID, issueID).Scan(&ir.IssueID, &ir.ForeignIssueID, &ir.ForeignUserID, &ir.PreviousList, &order)
+	if err == sql.ErrNoRows {
+		return nil, 0, ErrIssueNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	n, err := s.getPosition(userID, listID, issueID, order)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return ir, n, nil
+}
+
+func (s *sqlStore) GetIssueListAndReference(userID, issueID string) (string, *IssueRef, int) {
+	query := fmt.Sprintf(`SELECT list_id, issue_id, foreign_issue_id, foreign_user_id, previous_list, sort_order
+		FROM %s WHERE user_id = ? AND issue_id = ? AND list_id <> ?`, referencesTable)
+
+	rows, err := s.db.Query(s.rebind(query), userID, issueID, DoneListKey)
+	if err != nil {
+		return "", nil, 0
+	}
+	defer rows.Close()
+
+	found := map[string]*IssueRef{}
+	orders := map[string]int64{}
+	for rows.Next() {
+		var listID string
+		var order int64
+		ir := &IssueRef{}
+		if err = rows.Scan(&listID, &ir.IssueID, &ir.ForeignIssueID, &ir.ForeignUserID, &ir.PreviousList, &order); err != nil {
+			return "", nil, 0
+		}
+		found[listID] = ir
+		orders[listID] = order
+	}
+	if rows.Err() != nil {
+		return "", nil, 0
+	}
+
+	// Keep the same precedence as the KV store in case the issue is on several lists, with the custom
+	// lists last
+	listIDs := []string{MyListKey, OutListKey, InListKey}
+	customListIDs := []string{}
+	for listID := range found {
+		if isCustomListKey(listID) {
+			customListIDs = append(customListIDs, listID)
+		}
+	}
+	sort.Strings(customListIDs)
+
+	for _, listID := range append(listIDs, customListIDs...) {
+		ir, ok := found[listID]
+		if !ok {
+			continue
+		}
+
+		n, err := s.getPosition(userID, listID, issueID, orders[listID])
+		if err != nil {
+			return "", nil, 0
+		}
+
+		return listID, ir, n
+	}
+
+	return "", nil, 0
+}
+
+func (s *sqlStore) GetList(userID, listID string) ([]*IssueRef, error) {
+	query := fmt.Sprintf(`SELECT issue_id, foreign_issue_id, foreign_user_id, previous_list, sort_order
+		FROM %s WHERE user_id = ? AND list_id = ? ORDER BY sort_order, issue_id`, referencesTable)
+
+	rows, err := s.db.Query(s.rebind(query), userID, listID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list, _, err := scanReferences(rows)
+	return list, err
+}
+
+func (s *sqlStore) GetUserIDs() ([]string, error) {
+	return s.queryIDs(fmt.Sprintf("SELECT DISTINCT user_id FROM %s WHERE list_id NOT IN (?, ?)", referencesTable), ChannelListKey, ChannelDoneListKey)
+}
+
+func (s *sqlStore) GetChannelIDs() ([]string, error) {
+	return s.queryIDs(fmt.Sprintf("SELECT DISTINCT user_id FROM %s WHERE list_id IN (?, ?)", referencesTable), ChannelListKey, ChannelDoneListKey)
+}
+
+func (s *sqlStore) GetIssueIDs() ([]string, error) {
+	return s.queryIDs(fmt.Sprintf("SELECT id FROM %s", issuesTable))
+}
+
+func (s *sqlStore) queryIDs(query string, args ...interface{}) ([]string, error) {
+	rows, err := s.db.Query(s.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (s *sqlStore) NextShortID(userID string) (int64, error) {
+	return s.kvStore.NextShortID(userID)
+}
+
+// SaveShortID does nothing, since short IDs are looked up on the issues table
+func (s *sqlStore) SaveShortID(_ string, _ int64, _ string) error {
+	return nil
+}
+
+func (s *sqlStore) GetIssueIDByShortID(userID string, shortID int64) (string, error) {
+	query := fmt.Sprintf(`SELECT i.id FROM %s i JOIN %s r ON r.issue_id = i.id
+		WHERE r.user_id = ? AND i.short_id = ?`, issuesTable, referencesTable)
+
+	var issueID string
+	err := s.db.QueryRow(s.rebind(query), userID, shortID).Scan(&issueID)
+	if err == sql.ErrNoRows {
+		return "", ErrIssueNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return issueID, nil
+}
+
+func (s *sqlStore) SaveTombstone(userID string, tombstone *Tombstone) error {
+	return s.kvStore.SaveTombstone(userID, tombstone)
+}
+
+func (s *sqlStore) GetAndRemoveTombstone(userID string) (*Tombstone, error) {
+	return s.kvStore.GetAndRemoveTombstone(userID)
+}
+
+func (s *sqlStore) GetCustomLists(userID string) ([]*CustomList, error) {
+	return s.kvStore.GetCustomLists(userID)
+}
+
+func (s *sqlStore) UpdateCustomLists(userID string, update func([]*CustomList) ([]*CustomList, error)) error {
+	return s.kvStore.UpdateCustomLists(userID, update)
+}
+
+func (s *sqlStore) SaveJournalEntry(entry *JournalEntry) error {
+	return s.kvStore.SaveJournalEntry(entry)
+}
+
+func (s *sqlStore) RemoveJournalEntry(entryID string) error {
+	return s.kvStore.RemoveJournalEntry(entryID)
+}
+
+func (s *sqlStore) GetJournalEntries() ([]*JournalEntry, error) {
+	return s.kvStore.GetJournalEntries()
+}
+
+// importIssue stores issue unless it already exists
+func (s *sqlStore) importIssue(issue *Issue) error {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(issueColumns)), ", ")
+	query := fmt.Sprintf("%s INTO %s (%s) VALUES (%s) %s", s.insertIgnore(), issuesTable, strings.Join(issueColumns, ", "), placeholders, s.onConflictDoNothing())
+
+	values, err := issueValues(issue)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(s.rebind(query), values...)
+	return err
+}
+
+// importList stores list as the references of listID for userID, skipping the ones that already exist
+func (s *sqlStore) importList(userID, listID string, list []*IssueRef) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		for i, ir := range list {
+			query := fmt.Sprintf(`%s INTO %s (user_id, list_id, issue_id, foreign_issue_id, foreign_user_id, previous_list, sort_order)
+				VALUES (?, ?, ?, ?, ?, ?, ?) %s`, s.insertIgnore(), referencesTable, s.onConflictDoNothing())
+
+			_, err := tx.Exec(s.rebind(query), userID, listID, ir.IssueID, ir.ForeignIssueID, ir.ForeignUserID, ir.PreviousList, i)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// lockList returns the references of listID for userID, along with their sort order, locking them until tx finishes
+func (s *sqlStore) lockList(tx *sql.Tx, userID, listID string) ([]*IssueRef, []int64, error) {
+	query := fmt.Sprintf(`SELECT issue_id, foreign_issue_id, foreign_user_id, previous_list, sort_order
+		FROM %s WHERE user_id = ? AND list_id = ? ORDER BY sort_order, issue_id FOR UPDATE`, referencesTable)
+
+	rows, err := tx.Query(s.rebind(query), userID, listID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	return scanReferences(rows)
+}
+
+func (s *sqlStore) insertReference(tx *sql.Tx, userID, listID string, ir *IssueRef, order int64) error {
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, list_id, issue_id, foreign_issue_id, foreign_user_id, previous_list, sort_order)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`, referencesTable)
+
+	_, err := tx.Exec(s.rebind(query), userID, listID, ir.IssueID, ir.ForeignIssueID, ir.ForeignUserID, ir.PreviousList, order)
+	return err
+}
+
+func (s *sqlStore) updateOrder(tx *sql.Tx, userID, listID, issueID string, order int64) error {
+	query := fmt.Sprintf("UPDATE %s SET sort_order = ? WHERE user_id = ? AND list_id = ? AND issue_id = ?", referencesTable)
+	_, err := tx.Exec(s.rebind(query), order, userID, listID, issueID)
+	return err
+}
+
+// getPosition returns the position on the list of the reference with the given issueID and sort order
+func (s *sqlStore) getPosition(userID, listID, issueID string, order int64) (int, error) {
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s
+		WHERE user_id = ? AND list_id = ? AND (sort_order < ? OR (sort_order = ? AND issue_id < ?))`, referencesTable)
+
+	var n int
+	err := s.db.QueryRow(s.rebind(query), userID, listID, order, order, issueID).Scan(&n)
+	return n, err
+}
+
+func (s *sqlStore) withTx(f func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+
+	if err = f(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return errors.Wrapf(err, "failed to rollback transaction: %s", rollbackErr.Error())
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// rebind replaces the ? placeholders in query by the ones used by the database driver
+func (s *sqlStore) rebind(query string) string {
+	if s.driverName != model.DatabaseDriverPostgres {
+		return query
+	}
+
+	var builder strings.Builder
+	n := 0
+	for _, r := range query {
+		if r != '?' {
+			builder.WriteRune(r)
+			continue
+		}
+		n++
+		builder.WriteString(fmt.Sprintf("$%d", n))
+	}
+
+	return builder.String()
+}
+
+// upsertClause returns the clause to update columns when a row with the same key already exists
+func (s *sqlStore) upsertClause(key string, columns []string) string {
+	updates := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if s.driverName == model.DatabaseDriverMysql {
+			updates = append(updates, fmt.Sprintf("%s = VALUES(%s)", column, column))
+		} else {
+			updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", column, column))
+		}
+	}
+
+	if s.driverName == model.DatabaseDriverMysql {
+		return "ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+	}
+
+	return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s", key, strings.Join(updates, ", "))
+}
+
+func (s *sqlStore) insertIgnore() string {
+	if s.driverName == model.DatabaseDriverMysql {
+		return "INSERT IGNORE"
+	}
+	return "INSERT"
+}
+
+func (s *sqlStore) onConflictDoNothing() string {
+	if s.driverName == model.DatabaseDriverMysql {
+		return ""
+	}
+	return "ON CONFLICT DO NOTHING"
+}
+
+func issueValues(issue *Issue) ([]interface{}, error) {
+	// The checklist is stored as JSON, or NULL when empty
+	var checklist sql.NullString
+	if len(issue.Checklist) > 0 {
+		checklistJSON, err := json.Marshal(issue.Checklist)
+		if err != nil {
+			return nil, err
+		}
+		checklist = sql.NullString{String: string(checklistJSON), Valid: true}
+	}
+
+	// So are the labels
+	var labels sql.NullString
+	if len(issue.Labels) > 0 {
+		labelsJSON, err := json.Marshal(issue.Labels)
+		if err != nil {
+			return nil, err
+		}
+		labels = sql.NullString{String: string(labelsJSON), Valid: true}
+	}
+
+	// And the recipients
+	var recipients sql.NullString
+	if len(issue.Recipients) > 0 {
+		recipientsJSON, err := json.Marshal(issue.Recipients)
+		if err != nil {
+			return nil, err
+		}
+		recipients = sql.NullString{String: string(recipientsJSON), Valid: true}
+	}
+
+	return []interface{}{
+		issue.ID,
+		issue.Message,
+		issue.Description,
+		issue.PostPermalink,
+		issue.PostID,
+		issue.CreateAt,
+		issue.DueAt,
+		issue.Priority,
+		issue.CompletedAt,
+		issue.CompletedBy,
+		issue.ShortID,
+		issue.SnoozedUntil,
+		issue.Recurrence,
+		checklist,
+		labels,
+		recipients,
+		issue.ClaimedBy,
+		issue.NextIssueID,
+		issue.NextUserID,
+	}, nil
+}
+
+func scanIssue(row *sql.Row) (*Issue, error) {
+	issue := &Issue{}
+	var checklist, labels, recipients sql.NullString
+	err := row.Scan(
+		&issue.ID,
+		&issue.Message,
+		&issue.Description,
+		&issue.PostPermalink,
+		&issue.PostID,
+		&issue.CreateAt,
+		&issue.DueAt,
+		&issue.Priority,
+		&issue.CompletedAt,
+		&issue.CompletedBy,
+		&issue.ShortID,
+		&issue.SnoozedUntil,
+		&issue.Recurrence,
+		&checklist,
+		&labels,
+		&recipients,
+		&issue.ClaimedBy,
+		&issue.NextIssueID,
+		&issue.NextUserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if checklist.Valid && checklist.String != "" {
+		if err = json.Unmarshal([]byte(checklist.String), &issue.Checklist); err != nil {
+			return nil, err
+		}
+	}
+
+	if labels.Valid && labels.String != "" {
+		if err = json.Unmarshal([]byte(labels.String), &issue.Labels); err != nil {
+			return nil, err
+		}
+	}
+
+	if recipients.Valid && recipients.String != "" {
+		if err = json.Unmarshal([]byte(recipients.String), &issue.Recipients); err != nil {
+			return nil, err
+		}
+	}
+
+	return issue, nil
+}
+
+func scanReferences(rows *sql.Rows) ([]*IssueRef, []int64, error) {
+	list := []*IssueRef{}
+	orders := []int64{}
+	for rows.Next() {
+		ir := &IssueRef{}
+		var order int64
+		if err := rows.Scan(&ir.IssueID, &ir.ForeignIssueID, &ir.ForeignUserID, &ir.PreviousList, &order); err != nil {
+			return nil, nil, err
+		}
+		list = append(list, ir)
+		orders = append(orders, order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return list, orders, nil
+}
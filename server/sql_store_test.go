@@ -0,0 +1,232 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// sqlTestDSNs are the environment variables with the connection strings of the databases to test the
+// sqlStore against, for each driver. The tests are skipped for the ones that are not set.
+var sqlTestDSNs = map[string]string{
+	model.DatabaseDriverPostgres: "TODO_TEST_POSTGRES_DSN",
+	model.DatabaseDriverMysql:    "TODO_TEST_MYSQL_DSN",
+}
+
+// runSQLStoreTest runs test against an empty and migrated sqlStore for each configured database
+func runSQLStoreTest(t *testing.T, test func(t *testing.T, store *sqlStore)) {
+	for driverName, env := range sqlTestDSNs {
+		t.Run(driverName, func(t *testing.T) {
+			dsn := os.Getenv(env)
+			if dsn == "" {
+				t.Skipf("%s is not set", env)
+			}
+
+			db, err := sql.Open(driverName, dsn)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = db.Close() })
+
+			dropTables := func() {
+				for _, table := range []string{issuesTable, referencesTable, migrationsTable} {
+					_, err := db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", table))
+					require.NoError(t, err)
+				}
+			}
+			dropTables()
+			t.Cleanup(dropTables)
+
+			store := newSQLListStore(db, driverName, newMemStore())
+			require.NoError(t, store.migrate())
+
+			test(t, store)
+		})
+	}
+}
+
+func TestSQLStoreMigrate(t *testing.T) {
+	runSQLStoreTest(t, func(t *testing.T, store *sqlStore) {
+		// Applying the migrations again is a no-op
+		require.NoError(t, store.migrate())
+
+		var version int
+		require.NoError(t, store.db.QueryRow(fmt.Sprintf("SELECT MAX(version) FROM %s", migrationsTable)).Scan(&version))
+		assert.Equal(t, len(sqlMigrations), version)
+
+		// A migration interrupted after adding its column is recorded when run again
+		_, err := store.db.Exec(store.rebind(fmt.Sprintf("DELETE FROM %s WHERE version = ?", migrationsTable)), len(sqlMigrations))
+		require.NoError(t, err)
+		require.NoError(t, store.migrate())
+		require.NoError(t, store.db.QueryRow(fmt.Sprintf("SELECT MAX(version) FROM %s", migrationsTable)).Scan(&version))
+		assert.Equal(t, len(sqlMigrations), version)
+	})
+}
+
+func TestSQLStoreIssues(t *testing.T) {
+	runSQLStoreTest(t, func(t *testing.T, store *sqlStore) {
+		issue := newIssue("message", "", "description", "")
+		issue.ShortID = 3
+		issue.DueAt = model.GetMillis()
+		issue.Labels = []string{"bug"}
+		issue.Checklist = []ChecklistItem{{ID: model.NewId(), Text: "step"}}
+		require.NoError(t, store.SaveIssue(issue))
+
+		got, err := store.GetIssue(issue.ID)
+		require.NoError(t, err)
+		assert.Equal(t, issue, got)
+
+		// Saving again updates the issue
+		issue.Message = "updated"
+		issue.Labels = nil
+		require.NoError(t, store.SaveIssue(issue))
+		got, err = store.GetIssue(issue.ID)
+		require.NoError(t, err)
+		assert.Equal(t, issue, got)
+
+		got, err = store.GetAndRemoveIssue("", issue.ID)
+		require.NoError(t, err)
+		assert.Equal(t, issue, got)
+
+		_, err = store.GetIssue(issue.ID)
+		assert.Equal(t, ErrIssueNotFound, err)
+	})
+}
+
+func TestSQLStoreReferences(t *testing.T) {
+	userID := model.NewId()
+	ids := []string{model.NewId(), model.NewId(), model.NewId(), model.NewId()}
+
+	listIDs := func(t *testing.T, store *sqlStore) []string {
+		list, err := store.GetList(userID, MyListKey)
+		require.NoError(t, err)
+
+		got := []string{}
+		for _, ir := range list {
+			got = append(got, ir.IssueID)
+		}
+		return got
+	}
+
+	runSQLStoreTest(t, func(t *testing.T, store *sqlStore) {
+		require.NoError(t, store.AddReference(userID, ids[0], MyListKey, "", ""))
+		require.NoError(t, store.AddReference(userID, ids[1], MyListKey, "", ""))
+		require.NoError(t, store.InsertReference(userID, MyListKey, &IssueRef{IssueID: ids[2]}, 0))
+		require.NoError(t, store.InsertReference(userID, MyListKey, &IssueRef{IssueID: ids[3]}, 2))
+		assert.Equal(t, []string{ids[2], ids[0], ids[3], ids[1]}, listIDs(t, store))
+
+		assert.Equal(t, ErrReferenceExists, store.AddReference(userID, ids[0], MyListKey, "", ""))
+
+		ir, n, err := store.GetIssueReference(userID, ids[3], MyListKey)
+		require.NoError(t, err)
+		assert.Equal(t, ids[3], ir.IssueID)
+		assert.Equal(t, 2, n)
+
+		listID, ir, n := store.GetIssueListAndReference(userID, ids[0])
+		assert.Equal(t, MyListKey, listID)
+		require.NotNil(t, ir)
+		assert.Equal(t, 1, n)
+
+		require.NoError(t, store.BumpReference(userID, ids[1], MyListKey))
+		assert.Equal(t, []string{ids[1], ids[2], ids[0], ids[3]}, listIDs(t, store))
+
+		require.NoError(t, store.MoveReference(userID, ids[1], MyListKey, 2))
+		assert.Equal(t, []string{ids[2], ids[0], ids[1], ids[3]}, listIDs(t, store))
+
+		require.NoError(t, store.ReorderReferences(userID, MyListKey, []string{ids[3], ids[1], ids[0], ids[2]}))
+		assert.Equal(t, []string{ids[3], ids[1], ids[0], ids[2]}, listIDs(t, store))
+
+		// An invalid order leaves the list untouched
+		assert.Equal(t, ErrInvalidOrder, store.ReorderReferences(userID, MyListKey, []string{ids[3], ids[3], ids[0], ids[2]}))
+		assert.Equal(t, []string{ids[3], ids[1], ids[0], ids[2]}, listIDs(t, store))
+
+		ir, err = store.PopReference(userID, MyListKey)
+		require.NoError(t, err)
+		assert.Equal(t, ids[3], ir.IssueID)
+
+		require.NoError(t, store.RemoveReference(userID, ids[0], MyListKey))
+		assert.Equal(t, ErrIssueNotFound, store.RemoveReference(userID, ids[0], MyListKey))
+		assert.Equal(t, []string{ids[1], ids[2]}, listIDs(t, store))
+
+		userIDs, err := store.GetUserIDs()
+		require.NoError(t, err)
+		assert.Equal(t, []string{userID}, userIDs)
+	})
+}
+
+func TestSQLStoreGetIssueIDByShortID(t *testing.T) {
+	userID := model.NewId()
+
+	runSQLStoreTest(t, func(t *testing.T, store *sqlStore) {
+		issue := newIssue("message", "", "", "")
+		issue.ShortID = 7
+		require.NoError(t, store.SaveIssue(issue))
+		require.NoError(t, store.AddReference(userID, issue.ID, MyListKey, "", ""))
+
+		issueID, err := store.GetIssueIDByShortID(userID, 7)
+		require.NoError(t, err)
+		assert.Equal(t, issue.ID, issueID)
+
+		_, err = store.GetIssueIDByShortID(model.NewId(), 7)
+		assert.Equal(t, ErrIssueNotFound, err)
+	})
+}
+
+func TestMigrateKVToSQL(t *testing.T) {
+	userID := model.NewId()
+
+	runSQLStoreTest(t, func(t *testing.T, store *sqlStore) {
+		kvStore := newMemStore()
+		copiedIssue := newIssue("copied before the interruption", "", "", "")
+		newKVIssue := newIssue("not copied yet", "", "", "")
+		kvStore.issues[copiedIssue.ID] = copiedIssue
+		kvStore.issues[newKVIssue.ID] = newKVIssue
+		kvStore.lists[listKey(userID, MyListKey)] = []*IssueRef{{IssueID: copiedIssue.ID}, {IssueID: newKVIssue.ID}}
+
+		// A previous run was interrupted after copying the first issue and its reference
+		require.NoError(t, store.SaveIssue(copiedIssue))
+		require.NoError(t, store.AddReference(userID, copiedIssue.ID, MyListKey, "", ""))
+
+		api := &plugintest.API{}
+		api.On("LogInfo", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+		api.On("LogInfo", mock.Anything).Maybe()
+		api.On("KVGet", StoreSQLMigrationKey).Return(nil, nil).Once()
+		api.On("KVList", 0, kvListPerPage).Return([]string{
+			issueKey(copiedIssue.ID),
+			issueKey(newKVIssue.ID),
+			listKey(userID, MyListKey),
+		}, nil).Once()
+		api.On("KVSet", StoreSQLMigrationKey, []byte("true")).Return(nil).Once()
+		api.On("KVGet", StoreSQLMigrationKey).Return([]byte("true"), nil).Once()
+		defer api.AssertExpectations(t)
+
+		p := &Plugin{}
+		p.SetAPI(api)
+		require.NoError(t, p.migrateKVToSQL(kvStore, store))
+
+		list, err := store.GetList(userID, MyListKey)
+		require.NoError(t, err)
+		assert.Equal(t, kvStore.lists[listKey(userID, MyListKey)], list)
+
+		issue, err := store.GetIssue(newKVIssue.ID)
+		require.NoError(t, err)
+		assert.Equal(t, newKVIssue, issue)
+
+		// Once done, the todos created on the database are never replaced
+		dbIssue := newIssue("created on the database", "", "", "")
+		require.NoError(t, store.SaveIssue(dbIssue))
+		require.NoError(t, store.AddReference(userID, dbIssue.ID, MyListKey, "", ""))
+		require.NoError(t, p.migrateKVToSQL(kvStore, store))
+
+		list, err = store.GetList(userID, MyListKey)
+		require.NoError(t, err)
+		assert.Len(t, list, 3)
+	})
+}
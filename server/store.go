@@ -21,9 +21,39 @@ const (
 	StoreReminderKey = "reminder"
 	// StoreReminderEnabledKey is the key used to store the user preference of auto daily reminder
 	StoreReminderEnabledKey = "reminder_enabled"
+	// StoreReminderUsersKey is the key used to store the users that enabled the auto daily reminder
+	StoreReminderUsersKey = "reminder_users"
+	// StoreReminderScheduleKey is the key used to store the user preference of when to receive reminders
+	StoreReminderScheduleKey = "reminder_schedule"
+	// StoreTimezoneKey is the key used to store the IANA timezone the user reminders are scheduled on
+	StoreTimezoneKey = "timezone"
+	// StoreReactionIssueKey is the key used to store the ID of the issue a user added by reacting to a post
+	StoreReactionIssueKey = "reaction_issue"
 
 	// StoreAllowIncomingTaskRequestsKey is the key used to store user preference for wallowing any incoming todo requests
 	StoreAllowIncomingTaskRequestsKey = "allow_incoming_task"
+	// StoreTombstoneKey is the key used to store the information to undo the last operation of a user
+	StoreTombstoneKey = "undo"
+	// StoreShortIDKey is the key used to store the last short ID allocated for the issues of a user
+	StoreShortIDKey = "short_id"
+	// StoreShortIDIssueKey is the key used to store the ID of the issue a short ID of a user refers to
+	StoreShortIDIssueKey = "short_id_issue"
+	// StoreCustomListsKey is the key used to store the custom lists of a user, in order
+	StoreCustomListsKey = "lists"
+	// StoreJournalKey is the key used to store each of the operations that are being applied
+	StoreJournalKey = "journal"
+
+	// UndoExpirySeconds is the time an operation can be undone
+	UndoExpirySeconds = 5 * 60
+
+	// TombstoneComplete is the tombstone action of completed issues
+	TombstoneComplete = "complete"
+	// TombstoneRemove is the tombstone action of removed issues
+	TombstoneRemove = "remove"
+	// TombstoneDecline is the tombstone action of removed issues that were received from other user
+	TombstoneDecline = "decline"
+	// TombstonePop is the tombstone action of popped issues
+	TombstonePop = "pop"
 )
 
 // IssueRef denotes every element in any of the lists. Contains the issue that refers to,
@@ -33,6 +63,30 @@ type IssueRef struct {
 	IssueID        string `json:"issue_id"`
 	ForeignIssueID string `json:"foreign_issue_id"`
 	ForeignUserID  string `json:"foreign_user_id"`
+	// PreviousList is the list the issue was on before being moved to the done list
+	PreviousList string `json:"previous_list,omitempty"`
+}
+
+// Tombstone keeps the state of an issue, and its foreign counterpart if any, previous to a
+// destructive operation, so the operation can be undone.
+type Tombstone struct {
+	Action   string    `json:"action"`
+	CreateAt int64     `json:"create_at"`
+	Issue    *Issue    `json:"issue"`
+	ListID   string    `json:"list_id"`
+	Position int       `json:"position"`
+	Ref      *IssueRef `json:"ref"`
+
+	ForeignIssue    *Issue    `json:"foreign_issue,omitempty"`
+	ForeignListID   string    `json:"foreign_list_id,omitempty"`
+	ForeignPosition int       `json:"foreign_position,omitempty"`
+	ForeignRef      *IssueRef `json:"foreign_ref,omitempty"`
+
+	Recipients []*RecipientTombstone `json:"recipients,omitempty"`
+
+	// NextIssueID is the occurrence of NextUserID created on completing a repeating todo, removed on undo
+	NextIssueID string `json:"next_issue_id,omitempty"`
+	NextUserID  string `json:"next_user_id,omitempty"`
 }
 
 func listKey(userID string, listID string) string {
@@ -51,10 +105,42 @@ func reminderEnabledKey(userID string) string {
 	return fmt.Sprintf("%s_%s", StoreReminderEnabledKey, userID)
 }
 
+func reminderScheduleKey(userID string) string {
+	return fmt.Sprintf("%s_%s", StoreReminderScheduleKey, userID)
+}
+
+func timezoneKey(userID string) string {
+	return fmt.Sprintf("%s_%s", StoreTimezoneKey, userID)
+}
+
+func reactionIssueKey(userID, postID string) string {
+	return fmt.Sprintf("%s_%s_%s", StoreReactionIssueKey, userID, postID)
+}
+
 func allowIncomingTaskRequestsKey(userID string) string {
 	return fmt.Sprintf("%s_%s", StoreAllowIncomingTaskRequestsKey, userID)
 }
 
+func shortIDKey(userID string) string {
+	return fmt.Sprintf("%s_%s", StoreShortIDKey, userID)
+}
+
+func shortIDIssueKey(userID string, shortID int64) string {
+	return fmt.Sprintf("%s_%s_%d", StoreShortIDIssueKey, userID, shortID)
+}
+
+func tombstoneKey(userID string) string {
+	return fmt.Sprintf("%s_%s", StoreTombstoneKey, userID)
+}
+
+func customListsKey(userID string) string {
+	return fmt.Sprintf("%s_%s", StoreCustomListsKey, userID)
+}
+
+func journalKey(entryID string) string {
+	return fmt.Sprintf("%s_%s", StoreJournalKey, entryID)
+}
+
 type listStore struct {
 	api plugin.API
 }
@@ -87,7 +173,7 @@ func (l *listStore) GetIssue(issueID string) (*Issue, error) {
 	}
 
 	if originalJSONIssue == nil {
-		return nil, errors.New("cannot find issue")
+		return nil, ErrIssueNotFound
 	}
 
 	var issue *Issue
@@ -99,7 +185,11 @@ func (l *listStore) GetIssue(issueID string) (*Issue, error) {
 	return issue, nil
 }
 
-func (l *listStore) RemoveIssue(issueID string) error {
+func (l *listStore) RemoveIssue(userID, issueID string) error {
+	if err := l.removeShortID(userID, issueID); err != nil {
+		return err
+	}
+
 	appErr := l.api.KVDelete(issueKey(issueID))
 	if appErr != nil {
 		return errors.New(appErr.Error())
@@ -108,13 +198,39 @@ func (l *listStore) RemoveIssue(issueID string) error {
 	return nil
 }
 
-func (l *listStore) GetAndRemoveIssue(issueID string) (*Issue, error) {
+// removeShortID removes the short ID of userID referring to issueID, if any
+func (l *listStore) removeShortID(userID, issueID string) error {
+	if userID == "" {
+		return nil
+	}
+
+	issue, err := l.GetIssue(issueID)
+	if errors.Is(err, ErrIssueNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if issue.ShortID == 0 {
+		return nil
+	}
+
+	_, appErr := l.api.KVCompareAndDelete(shortIDIssueKey(userID, issue.ShortID), []byte(issueID))
+	if appErr != nil {
+		return errors.New(appErr.Error())
+	}
+
+	return nil
+}
+
+func (l *listStore) GetAndRemoveIssue(userID, issueID string) (*Issue, error) {
 	issue, err := l.GetIssue(issueID)
 	if err != nil {
 		return nil, err
 	}
 
-	err = l.RemoveIssue(issueID)
+	err = l.RemoveIssue(userID, issueID)
 	if err != nil {
 		return nil, err
 	}
@@ -146,7 +262,7 @@ func (l *listStore) GetIssueReference(userID, issueID, listID string) (*IssueRef
 			return ir, i, nil
 		}
 	}
-	return nil, 0, errors.New("cannot find issue")
+	return nil, 0, ErrIssueNotFound
 }
 
 func (l *listStore) GetIssueListAndReference(userID, issueID string) (string, *IssueRef, int) {
@@ -165,6 +281,17 @@ func (l *listStore) GetIssueListAndReference(userID, issueID string) (string, *I
 		return InListKey, ir, n
 	}
 
+	lists, err := l.GetCustomLists(userID)
+	if err != nil {
+		return "", nil, 0
+	}
+	for _, list := range lists {
+		ir, n, _ = l.GetIssueReference(userID, issueID, list.ID)
+		if ir != nil {
+			return list.ID, ir, n
+		}
+	}
+
 	return "", nil, 0
 }
 
@@ -177,7 +304,7 @@ func (l *listStore) AddReference(userID, issueID, listID, foreignUserID, foreign
 
 		for _, ir := range list {
 			if ir.IssueID == issueID {
-				return errors.New("issue id already exists in list")
+				return ErrReferenceExists
 			}
 		}
 
@@ -202,6 +329,42 @@ func (l *listStore) AddReference(userID, issueID, listID, foreignUserID, foreign
 	return errors.New("unable to store installation")
 }
 
+func (l *listStore) InsertReference(userID, listID string, newIR *IssueRef, position int) error {
+	for i := 0; i < StoreRetries; i++ {
+		list, originalJSONList, err := l.getList(userID, listID)
+		if err != nil {
+			return err
+		}
+
+		for _, ir := range list {
+			if ir.IssueID == newIR.IssueID {
+				return ErrReferenceExists
+			}
+		}
+
+		if position < 0 || position > len(list) {
+			position = len(list)
+		}
+
+		newList := append([]*IssueRef{}, list[:position]...)
+		newList = append(newList, newIR)
+		newList = append(newList, list[position:]...)
+
+		ok, err := l.saveList(userID, listID, newList, originalJSONList)
+		if err != nil {
+			return err
+		}
+
+		// If err is nil but ok is false, then something else updated the installs between the get and set above
+		// so we need to try again, otherwise we can return
+		if ok {
+			return nil
+		}
+	}
+
+	return errors.New("unable to store list")
+}
+
 func (l *listStore) RemoveReference(userID, issueID, listID string) error {
 	for i := 0; i < StoreRetries; i++ {
 		list, originalJSONList, err := l.getList(userID, listID)
@@ -218,7 +381,7 @@ func (l *listStore) RemoveReference(userID, issueID, listID string) error {
 		}
 
 		if !found {
-			return errors.New("cannot find issue")
+			return ErrIssueNotFound
 		}
 
 		ok, err := l.saveList(userID, listID, list, originalJSONList)
@@ -244,7 +407,7 @@ func (l *listStore) PopReference(userID, listID string) (*IssueRef, error) {
 		}
 
 		if len(list) == 0 {
-			return nil, errors.New("cannot find issue")
+			return nil, ErrIssueNotFound
 		}
 
 		ir := list[0]
@@ -282,7 +445,7 @@ func (l *listStore) BumpReference(userID, issueID, listID string) error {
 		}
 
 		if i == len(list) {
-			return errors.New("cannot find issue")
+			return ErrIssueNotFound
 		}
 
 		newList := append([]*IssueRef{ir}, list[:i]...)
@@ -303,6 +466,110 @@ func (l *listStore) BumpReference(userID, issueID, listID string) error {
 	return errors.New("unable to store list")
 }
 
+func (l *listStore) MoveReference(userID, issueID, listID string, newPosition int) error {
+	for i := 0; i < StoreRetries; i++ {
+		list, originalJSONList, err := l.getList(userID, listID)
+		if err != nil {
+			return err
+		}
+
+		newList, _, err := moveReference(list, issueID, newPosition)
+		if err != nil {
+			return err
+		}
+
+		ok, err := l.saveList(userID, listID, newList, originalJSONList)
+		if err != nil {
+			return err
+		}
+
+		// If err is nil but ok is false, then something else updated the installs between the get and set above
+		// so we need to try again, otherwise we can return
+		if ok {
+			return nil
+		}
+	}
+
+	return errors.New("unable to store list")
+}
+
+func (l *listStore) ReorderReferences(userID, listID string, issueIDs []string) error {
+	for i := 0; i < StoreRetries; i++ {
+		list, originalJSONList, err := l.getList(userID, listID)
+		if err != nil {
+			return err
+		}
+
+		newList, err := reorderReferences(list, issueIDs)
+		if err != nil {
+			return err
+		}
+
+		ok, err := l.saveList(userID, listID, newList, originalJSONList)
+		if err != nil {
+			return err
+		}
+
+		// If err is nil but ok is false, then something else updated the installs between the get and set above
+		// so we need to try again, otherwise we can return
+		if ok {
+			return nil
+		}
+	}
+
+	return errors.New("unable to store list")
+}
+
+// reorderReferences returns a copy of list with the references for issueIDs first, in that order,
+// followed by the rest in their order
+func reorderReferences(list []*IssueRef, issueIDs []string) ([]*IssueRef, error) {
+	byIssueID := map[string]*IssueRef{}
+	for _, ir := range list {
+		byIssueID[ir.IssueID] = ir
+	}
+
+	newList := []*IssueRef{}
+	for _, issueID := range issueIDs {
+		ir, ok := byIssueID[issueID]
+		if !ok {
+			return nil, ErrInvalidOrder
+		}
+		newList = append(newList, ir)
+		delete(byIssueID, issueID)
+	}
+
+	for _, ir := range list {
+		if _, ok := byIssueID[ir.IssueID]; ok {
+			newList = append(newList, ir)
+		}
+	}
+
+	return newList, nil
+}
+
+// moveReference returns a copy of list with the reference for issueID moved to newPosition, along
+// with its previous position. Out of range positions move it to the end of the list.
+func moveReference(list []*IssueRef, issueID string, newPosition int) ([]*IssueRef, int, error) {
+	n := -1
+	for i, ir := range list {
+		if ir.IssueID == issueID {
+			n = i
+			break
+		}
+	}
+	if n < 0 {
+		return nil, 0, ErrIssueNotFound
+	}
+
+	newList := append(append([]*IssueRef{}, list[:n]...), list[n+1:]...)
+	if newPosition < 0 || newPosition > len(newList) {
+		newPosition = len(newList)
+	}
+	newList = append(newList[:newPosition], append([]*IssueRef{list[n]}, newList[newPosition:]...)...)
+
+	return newList, n, nil
+}
+
 func (l *listStore) GetList(userID, listID string) ([]*IssueRef, error) {
 	irs, _, err := l.getList(userID, listID)
 	return irs, err
@@ -365,6 +632,260 @@ func (l *listStore) legacyIssueRef(userID, listID string) ([]*IssueRef, []byte,
 	return newList, originalJSONList, nil
 }
 
+func (l *listStore) GetUserIDs() ([]string, error) {
+	return l.getOwnerIDs(false)
+}
+
+func (l *listStore) GetChannelIDs() ([]string, error) {
+	return l.getOwnerIDs(true)
+}
+
+// getOwnerIDs returns the IDs of the channels that have lists stored if channels is set, or of the
+// users otherwise
+func (l *listStore) getOwnerIDs(channels bool) ([]string, error) {
+	ownerIDs := []string{}
+	seen := map[string]bool{}
+	err := l.forEachKey(func(key string) {
+		if ownerID, listID, ok := parseListKey(key); ok && isChannelListKey(listID) == channels && !seen[ownerID] {
+			seen[ownerID] = true
+			ownerIDs = append(ownerIDs, ownerID)
+		}
+	})
+	return ownerIDs, err
+}
+
+func (l *listStore) GetIssueIDs() ([]string, error) {
+	issueIDs := []string{}
+	err := l.forEachKey(func(key string) {
+		if issueID, ok := parseIssueKey(key); ok {
+			issueIDs = append(issueIDs, issueID)
+		}
+	})
+	return issueIDs, err
+}
+
+func (l *listStore) NextShortID(userID string) (int64, error) {
+	for i := 0; i < StoreRetries; i++ {
+		originalJSONLast, appErr := l.api.KVGet(shortIDKey(userID))
+		if appErr != nil {
+			return 0, errors.New(appErr.Error())
+		}
+
+		var last int64
+		if originalJSONLast != nil {
+			if err := json.Unmarshal(originalJSONLast, &last); err != nil {
+				return 0, err
+			}
+		}
+
+		newJSONLast, err := json.Marshal(last + 1)
+		if err != nil {
+			return 0, err
+		}
+
+		ok, appErr := l.api.KVCompareAndSet(shortIDKey(userID), originalJSONLast, newJSONLast)
+		if appErr != nil {
+			return 0, errors.New(appErr.Error())
+		}
+
+		if ok {
+			return last + 1, nil
+		}
+	}
+
+	return 0, errors.New("unable to store short id")
+}
+
+func (l *listStore) SaveShortID(userID string, shortID int64, issueID string) error {
+	appErr := l.api.KVSet(shortIDIssueKey(userID, shortID), []byte(issueID))
+	if appErr != nil {
+		return errors.New(appErr.Error())
+	}
+
+	return nil
+}
+
+func (l *listStore) GetIssueIDByShortID(userID string, shortID int64) (string, error) {
+	issueID, appErr := l.api.KVGet(shortIDIssueKey(userID, shortID))
+	if appErr != nil {
+		return "", errors.New(appErr.Error())
+	}
+
+	if issueID == nil {
+		return "", ErrIssueNotFound
+	}
+
+	return string(issueID), nil
+}
+
+func (l *listStore) GetCustomLists(userID string) ([]*CustomList, error) {
+	lists, _, err := l.getCustomLists(userID)
+	return lists, err
+}
+
+func (l *listStore) getCustomLists(userID string) ([]*CustomList, []byte, error) {
+	originalJSONLists, appErr := l.api.KVGet(customListsKey(userID))
+	if appErr != nil {
+		return nil, nil, errors.New(appErr.Error())
+	}
+
+	lists := []*CustomList{}
+	if originalJSONLists == nil {
+		return lists, nil, nil
+	}
+
+	if err := json.Unmarshal(originalJSONLists, &lists); err != nil {
+		return nil, nil, err
+	}
+
+	return lists, originalJSONLists, nil
+}
+
+func (l *listStore) UpdateCustomLists(userID string, update func([]*CustomList) ([]*CustomList, error)) error {
+	for i := 0; i < StoreRetries; i++ {
+		lists, originalJSONLists, err := l.getCustomLists(userID)
+		if err != nil {
+			return err
+		}
+
+		newLists, err := update(lists)
+		if err != nil {
+			return err
+		}
+
+		newJSONLists, err := json.Marshal(newLists)
+		if err != nil {
+			return err
+		}
+
+		ok, appErr := l.api.KVCompareAndSet(customListsKey(userID), originalJSONLists, newJSONLists)
+		if appErr != nil {
+			return errors.New(appErr.Error())
+		}
+
+		if ok {
+			return nil
+		}
+	}
+
+	return errors.New("unable to store custom lists")
+}
+
+func (l *listStore) forEachKey(f func(key string)) error {
+	for page := 0; ; page++ {
+		keys, appErr := l.api.KVList(page, kvListPerPage)
+		if appErr != nil {
+			return errors.New(appErr.Error())
+		}
+
+		for _, key := range keys {
+			f(key)
+		}
+
+		if len(keys) < kvListPerPage {
+			return nil
+		}
+	}
+}
+
+func (l *listStore) SaveTombstone(userID string, tombstone *Tombstone) error {
+	jsonTombstone, jsonErr := json.Marshal(tombstone)
+	if jsonErr != nil {
+		return jsonErr
+	}
+
+	appErr := l.api.KVSetWithExpiry(tombstoneKey(userID), jsonTombstone, UndoExpirySeconds)
+	if appErr != nil {
+		return errors.New(appErr.Error())
+	}
+
+	return nil
+}
+
+func (l *listStore) GetAndRemoveTombstone(userID string) (*Tombstone, error) {
+	jsonTombstone, appErr := l.api.KVGet(tombstoneKey(userID))
+	if appErr != nil {
+		return nil, errors.New(appErr.Error())
+	}
+
+	if jsonTombstone == nil {
+		return nil, nil
+	}
+
+	ok, appErr := l.api.KVCompareAndDelete(tombstoneKey(userID), jsonTombstone)
+	if appErr != nil {
+		return nil, errors.New(appErr.Error())
+	}
+
+	// Someone else undid or replaced the operation in the meantime
+	if !ok {
+		return nil, nil
+	}
+
+	var tombstone *Tombstone
+	if err := json.Unmarshal(jsonTombstone, &tombstone); err != nil {
+		return nil, err
+	}
+
+	return tombstone, nil
+}
+
+func (l *listStore) SaveJournalEntry(entry *JournalEntry) error {
+	jsonEntry, jsonErr := json.Marshal(entry)
+	if jsonErr != nil {
+		return jsonErr
+	}
+
+	appErr := l.api.KVSet(journalKey(entry.ID), jsonEntry)
+	if appErr != nil {
+		return errors.New(appErr.Error())
+	}
+
+	return nil
+}
+
+func (l *listStore) RemoveJournalEntry(entryID string) error {
+	appErr := l.api.KVDelete(journalKey(entryID))
+	if appErr != nil {
+		return errors.New(appErr.Error())
+	}
+
+	return nil
+}
+
+func (l *listStore) GetJournalEntries() ([]*JournalEntry, error) {
+	entryIDs := []string{}
+	err := l.forEachKey(func(key string) {
+		if entryID, ok := parseJournalKey(key); ok {
+			entryIDs = append(entryIDs, entryID)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []*JournalEntry{}
+	for _, entryID := range entryIDs {
+		jsonEntry, appErr := l.api.KVGet(journalKey(entryID))
+		if appErr != nil {
+			return nil, errors.New(appErr.Error())
+		}
+
+		// The operation finished after listing the keys
+		if jsonEntry == nil {
+			continue
+		}
+
+		var entry *JournalEntry
+		if err := json.Unmarshal(jsonEntry, &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
 func (p *Plugin) saveLastReminderTimeForUser(userID string) error {
 	strTime := strconv.FormatInt(model.GetMillis(), 10)
 	appErr := p.API.KVSet(reminderKey(userID), []byte(strTime))
@@ -398,7 +919,19 @@ func (p *Plugin) saveReminderPreference(userID string, preference bool) error {
 	if appErr != nil {
 		return appErr
 	}
-	return nil
+
+	return p.updateReminderUsers(func(userIDs []string) []string {
+		newUserIDs := []string{}
+		for _, id := range userIDs {
+			if id != userID {
+				newUserIDs = append(newUserIDs, id)
+			}
+		}
+		if preference {
+			newUserIDs = append(newUserIDs, userID)
+		}
+		return newUserIDs
+	})
 }
 
 // getReminderPreference - gets user preference on reminder - default value will be true if in case any error
@@ -423,6 +956,158 @@ func (p *Plugin) getReminderPreference(userID string) bool {
 	return preference
 }
 
+// ensureReminderUser adds userID to the users that get reminders if they have not turned them off.
+// Users with Todos are added by migrateReminderUsers, this adds the ones that start using the plugin
+// later. Each user is checked once per server.
+func (p *Plugin) ensureReminderUser(userID string) {
+	if _, checked := p.reminderUsersChecked.Load(userID); checked {
+		return
+	}
+
+	if p.getReminderPreference(userID) {
+		err := p.updateReminderUsers(func(userIDs []string) []string {
+			return mergeIDs(userIDs, []string{userID})
+		})
+		if err != nil {
+			p.API.LogWarn("Unable to add user to the reminders", "user_id", userID, "err", err.Error())
+			return
+		}
+	}
+
+	p.reminderUsersChecked.Store(userID, true)
+}
+
+// getReminderUsers returns the users that get reminders, along with the stored value to update them
+func (p *Plugin) getReminderUsers() ([]string, []byte, error) {
+	originalJSONUserIDs, appErr := p.API.KVGet(StoreReminderUsersKey)
+	if appErr != nil {
+		return nil, nil, errors.New(appErr.Error())
+	}
+
+	userIDs := []string{}
+	if originalJSONUserIDs == nil {
+		return userIDs, nil, nil
+	}
+
+	if err := json.Unmarshal(originalJSONUserIDs, &userIDs); err != nil {
+		return nil, nil, err
+	}
+
+	return userIDs, originalJSONUserIDs, nil
+}
+
+func (p *Plugin) updateReminderUsers(update func([]string) []string) error {
+	for i := 0; i < StoreRetries; i++ {
+		userIDs, originalJSONUserIDs, err := p.getReminderUsers()
+		if err != nil {
+			return err
+		}
+
+		newUserIDs := update(userIDs)
+		if len(newUserIDs) == len(userIDs) && originalJSONUserIDs != nil {
+			// Nothing changed
+			return nil
+		}
+
+		newJSONUserIDs, err := json.Marshal(newUserIDs)
+		if err != nil {
+			return err
+		}
+
+		ok, appErr := p.API.KVCompareAndSet(StoreReminderUsersKey, originalJSONUserIDs, newJSONUserIDs)
+		if appErr != nil {
+			return errors.New(appErr.Error())
+		}
+
+		if ok {
+			return nil
+		}
+	}
+
+	return errors.New("unable to store reminder users")
+}
+
+func (p *Plugin) saveReminderSchedule(userID string, schedule *ReminderSchedule) error {
+	jsonSchedule, err := json.Marshal(schedule)
+	if err != nil {
+		return err
+	}
+
+	if appErr := p.API.KVSet(reminderScheduleKey(userID), jsonSchedule); appErr != nil {
+		return errors.New(appErr.Error())
+	}
+	return nil
+}
+
+// getReminderSchedule gets the reminder schedule of userID, or the default one if not set
+func (p *Plugin) getReminderSchedule(userID string) (*ReminderSchedule, error) {
+	jsonSchedule, appErr := p.API.KVGet(reminderScheduleKey(userID))
+	if appErr != nil {
+		return nil, errors.New(appErr.Error())
+	}
+
+	if jsonSchedule == nil {
+		return newDefaultReminderSchedule(), nil
+	}
+
+	schedule := newDefaultReminderSchedule()
+	if err := json.Unmarshal(jsonSchedule, schedule); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// saveTimezonePreference stores the IANA timezone of userID. An empty timezone removes it.
+func (p *Plugin) saveTimezonePreference(userID, timezone string) error {
+	if timezone == "" {
+		if appErr := p.API.KVDelete(timezoneKey(userID)); appErr != nil {
+			return errors.New(appErr.Error())
+		}
+		return nil
+	}
+
+	if appErr := p.API.KVSet(timezoneKey(userID), []byte(timezone)); appErr != nil {
+		return errors.New(appErr.Error())
+	}
+	return nil
+}
+
+// getTimezonePreference gets the IANA timezone stored for userID, or an empty string if none
+func (p *Plugin) getTimezonePreference(userID string) (string, error) {
+	timezoneBytes, appErr := p.API.KVGet(timezoneKey(userID))
+	if appErr != nil {
+		return "", errors.New(appErr.Error())
+	}
+
+	return string(timezoneBytes), nil
+}
+
+// saveReactionIssue stores issueID as the issue userID added by reacting to postID. An empty issueID removes it.
+func (p *Plugin) saveReactionIssue(userID, postID, issueID string) error {
+	if issueID == "" {
+		if appErr := p.API.KVDelete(reactionIssueKey(userID, postID)); appErr != nil {
+			return errors.New(appErr.Error())
+		}
+		return nil
+	}
+
+	if appErr := p.API.KVSet(reactionIssueKey(userID, postID), []byte(issueID)); appErr != nil {
+		return errors.New(appErr.Error())
+	}
+	return nil
+}
+
+// getReactionIssue gets the ID of the issue userID added by reacting to postID, or an empty string if none
+func (p *Plugin) getReactionIssue(userID, postID string) (string, error) {
+	issueID, appErr := p.API.KVGet(reactionIssueKey(userID, postID))
+	if appErr != nil {
+		return "", errors.New(appErr.Error())
+	}
+
+	return string(issueID), nil
+}
+
 func (p *Plugin) saveAllowIncomingTaskRequestsPreference(userID string, preference bool) error {
 	preferenceString := strconv.FormatBool(preference)
 	appErr := p.API.KVSet(allowIncomingTaskRequestsKey(userID), []byte(preferenceString))
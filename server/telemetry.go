@@ -3,8 +3,10 @@ package main
 type telemetrySource string
 
 const (
-	sourceCommand telemetrySource = "command"
-	sourceWebapp  telemetrySource = "webapp"
+	sourceCommand  telemetrySource = "command"
+	sourceWebapp   telemetrySource = "webapp"
+	sourceReaction telemetrySource = "reaction"
+	sourceCapture  telemetrySource = "capture"
 )
 
 func (p *Plugin) trackCommand(userID, command string) {
@@ -31,6 +33,10 @@ func (p *Plugin) trackCompleteIssue(userID string) {
 	_ = p.tracker.TrackUserEvent("complete_issue", userID, map[string]interface{}{})
 }
 
+func (p *Plugin) trackReopenIssue(userID string) {
+	_ = p.tracker.TrackUserEvent("reopen_issue", userID, map[string]interface{}{})
+}
+
 func (p *Plugin) trackRemoveIssue(userID string) {
 	_ = p.tracker.TrackUserEvent("remove_issue", userID, map[string]interface{}{})
 }
@@ -51,6 +57,39 @@ func (p *Plugin) trackBumpIssue(userID string) {
 	_ = p.tracker.TrackUserEvent("bump_issue", userID, map[string]interface{}{})
 }
 
+func (p *Plugin) trackSnoozeIssue(userID string) {
+	_ = p.tracker.TrackUserEvent("snooze_issue", userID, map[string]interface{}{})
+}
+
+func (p *Plugin) trackCustomList(userID, action string) {
+	_ = p.tracker.TrackUserEvent("custom_list", userID, map[string]interface{}{
+		"action": action,
+	})
+}
+
+func (p *Plugin) trackChannelList(userID string, source telemetrySource, action string) {
+	_ = p.tracker.TrackUserEvent("channel_list", userID, map[string]interface{}{
+		"source": source,
+		"action": action,
+	})
+}
+
+func (p *Plugin) trackMoveIssue(userID string) {
+	_ = p.tracker.TrackUserEvent("move_issue", userID, map[string]interface{}{})
+}
+
+func (p *Plugin) trackReorder(userID string, count int) {
+	_ = p.tracker.TrackUserEvent("reorder", userID, map[string]interface{}{
+		"count": count,
+	})
+}
+
+func (p *Plugin) trackUndo(userID, action string) {
+	_ = p.tracker.TrackUserEvent("undo", userID, map[string]interface{}{
+		"action": action,
+	})
+}
+
 func (p *Plugin) trackFrontend(userID, event string, properties map[string]interface{}) {
 	if properties == nil {
 		properties = map[string]interface{}{}
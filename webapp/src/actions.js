@@ -139,10 +139,10 @@ export const changeAssignee = (id, assignee) => async (dispatch, getState) => {
     }));
 };
 
-export const fetchAllIssueLists = (reminder = false) => async (dispatch, getState) => {
+export const fetchAllIssueLists = () => async (dispatch, getState) => {
     let data;
     try {
-        const resp = await fetch(getPluginServerRoute(getState()) + '/lists?reminder=' + reminder, Client4.getOptions({
+        const resp = await fetch(getPluginServerRoute(getState()) + '/lists', Client4.getOptions({
             method: 'get',
         }));
         data = await resp.json();
@@ -61,7 +61,7 @@ export default class Plugin {
         registry.registerWebSocketEventHandler(`custom_${pluginId}_refresh`, refresh);
         registry.registerReconnectHandler(refresh);
 
-        store.dispatch(fetchAllIssueLists(true));
+        store.dispatch(fetchAllIssueLists());
 
         // register websocket event to track config changes
         const configUpdate = ({data}) => {
@@ -75,7 +75,7 @@ export default class Plugin {
         activityFunc = () => {
             const now = new Date().getTime();
             if (now - lastActivityTime > activityTimeout) {
-                store.dispatch(fetchAllIssueLists(true));
+                store.dispatch(fetchAllIssueLists());
             }
             lastActivityTime = now;
         };